@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/server"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*     Audit     */
+/*****************/
+
+func TestProposalAudit(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("proposal_results")
+	clearTable("votes")
+	communityId := otu.AddCommunities(1)[0]
+	proposalId := otu.AddActiveProposals(communityId, 1)[0]
+
+	for account, choice := range map[string]string{"user1": "a", "user2": "b"} {
+		response := otu.CreateVoteAPI(proposalId, otu.GenerateValidVotePayload(account, proposalId, choice))
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+	}
+
+	getAudit := func(t *testing.T) models.ProposalAudit {
+		response := otu.GetProposalAuditAPI(proposalId)
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		var audit models.ProposalAudit
+		json.Unmarshal(response.Body.Bytes(), &audit)
+		return audit
+	}
+
+	t.Run("Should export votes without a report until results are stored", func(t *testing.T) {
+		audit := getAudit(t)
+		assert.Equal(t, 2, len(audit.Votes))
+		assert.Nil(t, audit.Results)
+		assert.Nil(t, audit.Report)
+	})
+
+	otu.UpdateProposalEndTime(proposalId, time.Now().UTC().Add(-time.Hour))
+	server.NewScheduler(otu.A).ProcessTransitions()
+
+	t.Run("Should verify the audit once results are stored", func(t *testing.T) {
+		audit := getAudit(t)
+		assert.NotNil(t, audit.Results)
+		assert.NotNil(t, audit.Report)
+		assert.Equal(t, 2, audit.Report.Votes_checked)
+		assert.Empty(t, audit.Report.Invalid_signatures)
+		assert.Empty(t, audit.Report.Invalid_ballots)
+		assert.True(t, *audit.Report.Results_match)
+		assert.True(t, audit.Report.Verified)
+
+		stored, err := models.GetAuditReport(otu.A.DB, proposalId)
+		assert.Nil(t, err)
+		assert.Equal(t, audit.Report.Verified, stored.Verified)
+		assert.Equal(t, audit.Report.Votes_checked, stored.Votes_checked)
+	})
+
+	t.Run("VerifyAudit should report a tampered ballot", func(t *testing.T) {
+		audit := getAudit(t)
+		vote := audit.Votes[0]
+		if vote.Choice == "a" {
+			vote.Choice = "b"
+		} else {
+			vote.Choice = "a"
+		}
+
+		report, err := server.VerifyAudit(otu.A.FlowAdapter, &audit)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{vote.Addr}, report.Invalid_ballots)
+		assert.Empty(t, report.Invalid_signatures)
+		assert.False(t, report.Verified)
+	})
+
+	t.Run("VerifyAudit should report a vote with an invalid signature", func(t *testing.T) {
+		audit := getAudit(t)
+		audit.Votes[1].Message = audit.Votes[0].Message
+
+		report, err := server.VerifyAudit(otu.A.FlowAdapter, &audit)
+		assert.Nil(t, err)
+		assert.Contains(t, report.Invalid_signatures, audit.Votes[1].Addr)
+		assert.False(t, report.Verified)
+	})
+
+	t.Run("VerifyAudit should report results that do not match the votes", func(t *testing.T) {
+		audit := getAudit(t)
+		audit.Results.Results_float["a"] += 1
+
+		report, err := server.VerifyAudit(otu.A.FlowAdapter, &audit)
+		assert.Nil(t, err)
+		assert.Empty(t, report.Invalid_signatures)
+		assert.False(t, *report.Results_match)
+		assert.False(t, report.Verified)
+	})
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*  EVM Chains   */
+/*****************/
+
+// Serves eth_call for an ERC-20 and ERC-721 contract with the given
+// balance, decimals and token IDs.
+func newEVMTestServer(balance *big.Int, decimals int64, tokenIds []int64) *httptest.Server {
+	balanceOf := hexutil.Encode(crypto.Keccak256([]byte("balanceOf(address)"))[:4])
+	tokenOfOwnerByIndex := hexutil.Encode(crypto.Keccak256([]byte("tokenOfOwnerByIndex(address,uint256)"))[:4])
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var result interface{}
+		switch req.Method {
+		case "eth_blockNumber":
+			result = "0x64"
+		case "eth_call":
+			var call struct {
+				Data string `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &call)
+
+			var value *big.Int
+			switch {
+			case strings.HasPrefix(call.Data, balanceOf):
+				value = balance
+			case strings.HasPrefix(call.Data, tokenOfOwnerByIndex):
+				index := new(big.Int).SetBytes(common.FromHex(call.Data)[36:68])
+				value = big.NewInt(tokenIds[index.Int64()])
+			default:
+				value = big.NewInt(decimals)
+			}
+			result = hexutil.Encode(common.LeftPadBytes(value.Bytes(), 32))
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+}
+
+func TestEVMAdapter(t *testing.T) {
+	addr := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	voter := "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	chain := shared.EVMChainKey
+	chainId := "31337"
+	contract := shared.Contract{Addr: &addr, Chain: &chain, Chain_id: &chainId}
+
+	t.Run("Should read ERC-20 balances in tokens", func(t *testing.T) {
+		balance, _ := new(big.Int).SetString("2500000000000000000", 10)
+		server := newEVMTestServer(balance, 18, nil)
+		defer server.Close()
+
+		chains := shared.NewChains(nil, chainId+"="+server.URL)
+		adapter, err := chains.ForContract(&contract)
+		assert.Nil(t, err)
+
+		tokens, err := adapter.GetBalanceAtBlock(voter, &contract, 100)
+		assert.Nil(t, err)
+		assert.Equal(t, 2.5, tokens)
+
+		height, err := adapter.GetCurrentBlockHeight()
+		assert.Nil(t, err)
+		assert.Equal(t, 100, height)
+	})
+
+	t.Run("Should read ERC-721 token IDs", func(t *testing.T) {
+		server := newEVMTestServer(big.NewInt(2), 0, []int64{7, 42})
+		defer server.Close()
+
+		adapter := shared.NewEVMAdapter(server.URL)
+		nftIds, err := adapter.GetNFTIdsAtBlock(voter, &contract, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{"7", "42"}, nftIds)
+	})
+
+	t.Run("Should reject chains that are not configured", func(t *testing.T) {
+		other := "1"
+		chains := shared.NewChains(nil, "")
+		_, err := chains.ForContract(&shared.Contract{Addr: &addr, Chain: &chain, Chain_id: &other})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestEVMSignature(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	message := hex.EncodeToString([]byte("1:a:1660000000000"))
+
+	sig, _ := crypto.Sign(accounts.TextHash([]byte("1:a:1660000000000")), key)
+	sig[crypto.RecoveryIDOffset] += 27
+	sigs := []shared.CompositeSignature{{Addr: addr, Signature: hexutil.Encode(sig)}}
+
+	adapter := shared.NewEVMAdapter("")
+
+	t.Run("Should accept a personal_sign signature", func(t *testing.T) {
+		assert.Nil(t, adapter.ValidateSignature(addr, message, &sigs))
+	})
+
+	t.Run("Should reject a signature from another address", func(t *testing.T) {
+		other, _ := crypto.GenerateKey()
+		otherAddr := crypto.PubkeyToAddress(other.PublicKey).Hex()
+		assert.NotNil(t, adapter.ValidateSignature(otherAddr, message, &sigs))
+	})
+
+	t.Run("Should reject a signature of another message", func(t *testing.T) {
+		tampered := hex.EncodeToString([]byte("1:b:1660000000000"))
+		assert.NotNil(t, adapter.ValidateSignature(addr, tampered, &sigs))
+	})
+}
+
+func TestEVMVoting(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("proposal_results")
+	clearTable("votes")
+	clearTable("balances")
+
+	balance, _ := new(big.Int).SetString("2500000000000000000", 10)
+	server := newEVMTestServer(balance, 18, nil)
+	defer server.Close()
+
+	chainId := "31337"
+	chains := otu.A.Chains
+	otu.A.Chains = shared.NewChains(otu.A.FlowAdapter, chainId+"="+server.URL)
+	defer func() { otu.A.Chains = chains }()
+
+	tokenAddr := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	tokenName := "TestToken"
+	chain := shared.EVMChainKey
+	strategy := "token-weighted-default"
+	communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+	otu.UpdateCommunityStrategies(communityId, []models.Strategy{{
+		Name: &strategy,
+		Contract: shared.Contract{
+			Name:     &tokenName,
+			Addr:     &tokenAddr,
+			Chain:    &chain,
+			Chain_id: &chainId,
+		},
+	}})
+
+	proposalStruct := otu.GenerateProposalStruct("user1", communityId)
+	proposalStruct.Start_time = time.Now().UTC().Add(-time.Hour)
+	response := otu.CreateProposalAPI(otu.GenerateProposalPayload("user1", proposalStruct))
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var p models.Proposal
+	json.Unmarshal(response.Body.Bytes(), &p)
+	assert.Equal(t, uint64(100), *p.Block_height)
+
+	key, _ := crypto.GenerateKey()
+	voter := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	signVote := func(addr, choice string) *models.Vote {
+		timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+		message := fmt.Sprintf("%d:%s:%d", p.ID, hex.EncodeToString([]byte(choice)), timestamp)
+		sig, _ := crypto.Sign(accounts.TextHash([]byte(message)), key)
+		sig[crypto.RecoveryIDOffset] += 27
+		return &models.Vote{
+			Proposal_id:          p.ID,
+			Addr:                 addr,
+			Choice:               choice,
+			Message:              hex.EncodeToString([]byte(message)),
+			Composite_signatures: &[]shared.CompositeSignature{{Addr: addr, Signature: hexutil.Encode(sig)}},
+		}
+	}
+
+	t.Run("Should reject an EVM address with non-hex characters", func(t *testing.T) {
+		response := otu.CreateVoteAPI(p.ID, signVote("0x"+strings.Repeat("z", 40), "a"))
+		assert.NotEqual(t, http.StatusCreated, response.Code)
+	})
+
+	t.Run("Should count a vote signed with an EVM account", func(t *testing.T) {
+		response := otu.CreateVoteAPI(p.ID, signVote(voter, "a"))
+		checkResponseCode(t, http.StatusCreated, response.Code)
+
+		var vote models.VoteWithBalance
+		json.Unmarshal(response.Body.Bytes(), &vote)
+		assert.Equal(t, strings.ToLower(voter), vote.Addr)
+
+		response = otu.GetVoteForProposalByAddressAPI(p.ID, voter)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		response = otu.GetProposalResultsAPI(p.ID)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var results models.ProposalResults
+		json.Unmarshal(response.Body.Bytes(), &results)
+		assert.Equal(t, 2.5, results.Results_float["a"])
+		assert.Equal(t, 0.0, results.Results_float["b"])
+	})
+	t.Run("Should list the delegations to an EVM account", func(t *testing.T) {
+		clearTable("delegations")
+		d := models.Delegation{
+			Community_id:         communityId,
+			Delegator:            "0x0000000000000001",
+			Delegate:             strings.ToLower(voter),
+			Message:              "delegate",
+			Composite_signatures: &[]shared.CompositeSignature{},
+		}
+		assert.Nil(t, d.CreateDelegation(otu.A.DB))
+
+		response := otu.GetDelegationsToAddressAPI(voter)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var delegations []models.Delegation
+		json.Unmarshal(response.Body.Bytes(), &delegations)
+		assert.Equal(t, 1, len(delegations))
+	})
+}
@@ -0,0 +1,103 @@
+// Command verify independently checks the tally of a proposal.
+//
+// It loads a proposal audit exported by a CAST API, or from a file,
+// re-checks every vote signature against the Flow network, re-runs the
+// proposal's strategy over the exported votes and reports whether the
+// tally matches the stored results. Run it from the backend directory
+// so flow.json and the cadence scripts can be found:
+//
+//	go run ./main/cmd/verify -proposal 42 -api https://api.example.com -network mainnet
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/server"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	api := flag.String("api", "http://localhost:5001", "base url of the CAST API to export the audit from")
+	proposalId := flag.Int("proposal", 0, "id of the proposal to verify")
+	file := flag.String("file", "", "read the audit from a file instead of the API")
+	network := flag.String("network", "", "flow network from flow.json, defaults to FLOW_ENV or emulator")
+	flag.Parse()
+
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+
+	if *file == "" && *proposalId == 0 {
+		fmt.Fprintln(os.Stderr, "either -proposal or -file is required")
+		os.Exit(2)
+	}
+
+	var audit models.ProposalAudit
+	if err := loadAudit(*api, *proposalId, *file, &audit); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading audit: %v\n", err)
+		os.Exit(1)
+	}
+
+	env := *network
+	if env == "" {
+		env = os.Getenv("FLOW_ENV")
+	}
+	if env == "" {
+		env = "emulator"
+	}
+	fa := shared.NewFlowClient(env, map[string]shared.CustomScript{})
+
+	report, err := server.VerifyAudit(fa, &audit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error verifying audit: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	if !report.Verified {
+		os.Exit(1)
+	}
+}
+
+func loadAudit(api string, proposalId int, file string, audit *models.ProposalAudit) error {
+	var body []byte
+	var err error
+
+	if file != "" {
+		body, err = ioutil.ReadFile(file)
+	} else {
+		body, err = fetchAudit(api, proposalId)
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, audit)
+}
+
+func fetchAudit(api string, proposalId int) ([]byte, error) {
+	url := fmt.Sprintf("%s/proposals/%d/audit", strings.TrimRight(api, "/"), proposalId)
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with status %d: %s", url, res.StatusCode, body)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAudit(t *testing.T) {
+	strategy := "token-weighted-default"
+	exported := models.ProposalAudit{
+		Proposal: models.Proposal{ID: 42, Strategy: &strategy},
+		Votes: []*models.VoteWithBalance{
+			{Vote: models.Vote{Proposal_id: 42, Addr: "0x0000000000000001", Choice: "a"}},
+		},
+	}
+	body, _ := json.Marshal(exported)
+
+	t.Run("Should load an audit from a file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "audit.json")
+		if err := ioutil.WriteFile(file, body, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var audit models.ProposalAudit
+		err := loadAudit("", 0, file, &audit)
+		assert.Nil(t, err)
+		assert.Equal(t, 42, audit.Proposal.ID)
+		assert.Equal(t, 1, len(audit.Votes))
+		assert.Equal(t, "0x0000000000000001", audit.Votes[0].Addr)
+	})
+
+	t.Run("Should load an audit from the API", func(t *testing.T) {
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/proposals/42/audit", r.URL.Path)
+			w.Write(body)
+		}))
+		defer api.Close()
+
+		var audit models.ProposalAudit
+		err := loadAudit(api.URL+"/", 42, "", &audit)
+		assert.Nil(t, err)
+		assert.Equal(t, 42, audit.Proposal.ID)
+	})
+
+	t.Run("Should return an error when the API does not export the audit", func(t *testing.T) {
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Votes are sealed until the proposal ends.", http.StatusForbidden)
+		}))
+		defer api.Close()
+
+		var audit models.ProposalAudit
+		err := loadAudit(api.URL, 42, "", &audit)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Should return an error when the file is missing", func(t *testing.T) {
+		var audit models.ProposalAudit
+		err := loadAudit("", 0, filepath.Join(os.TempDir(), "missing-audit.json"), &audit)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+/*************************/
+/*   Community Scripts   */
+/*************************/
+
+func TestCommunityScripts(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("community_scripts")
+
+	t.Run("Community admin should be able to register a script", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateCommunityScriptPayload("user1", communityId)
+		response := otu.CreateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusCreated, response.Code)
+
+		var script models.CommunityScript
+		json.Unmarshal(response.Body.Bytes(), &script)
+		assert.Equal(t, payload.Key, script.Key)
+		assert.Equal(t, payload.Signing_addr, script.Creator_addr)
+
+		response = otu.GetCommunityScriptsAPI(communityId)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var scripts []models.CommunityScript
+		json.Unmarshal(response.Body.Bytes(), &scripts)
+		assert.Equal(t, 1, len(scripts))
+
+		response = otu.CreateCommunityScriptAPI(otu.GenerateCommunityScriptPayload("user1", communityId))
+		checkResponseCode(t, http.StatusConflict, response.Code)
+
+		payload = otu.GenerateCommunityScriptPayload("user1", communityId)
+		payload.Name = "Renamed"
+		response = otu.UpdateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("A script in use should not be changed or deleted", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateCommunityScriptPayload("user1", communityId)
+		response := otu.CreateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusCreated, response.Code)
+
+		name := "custom-script"
+		otu.UpdateCommunityStrategies(communityId, []models.Strategy{{
+			Name: &name,
+			Contract: shared.Contract{
+				Name:        payload.Contract.Name,
+				Addr:        payload.Contract.Addr,
+				Public_path: payload.Contract.Public_path,
+				Script:      &payload.Key,
+			},
+		}})
+
+		payload = otu.GenerateCommunityScriptPayload("user1", communityId)
+		payload.Src += "// changed\n"
+		response = otu.UpdateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusConflict, response.Code)
+
+		response = otu.DeleteCommunityScriptAPI(otu.GenerateCommunityScriptPayload("user1", communityId))
+		checkResponseCode(t, http.StatusConflict, response.Code)
+
+		// renaming doesn't change the votes it weighs
+		payload = otu.GenerateCommunityScriptPayload("user1", communityId)
+		payload.Name = "Renamed"
+		response = otu.UpdateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		otu.UpdateCommunityStrategies(communityId, []models.Strategy{})
+		response = otu.DeleteCommunityScriptAPI(otu.GenerateCommunityScriptPayload("user1", communityId))
+		checkResponseCode(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Non admins should not be able to register a script", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateCommunityScriptPayload("user2", communityId)
+		response := otu.CreateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Should reject scripts that fail their dry run", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateCommunityScriptPayload("user1", communityId)
+		payload.Src = "pub fun main(address: Address): String { return \"\" }"
+		response := otu.CreateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Should reject scripts over the size limit", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateCommunityScriptPayload("user1", communityId)
+		payload.Src += "// " + strings.Repeat("x", models.MaxCommunityScriptSize)
+		response := otu.CreateCommunityScriptAPI(payload)
+		checkResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+}
@@ -11,7 +11,9 @@ func UseCors(c shared.Config) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if c.Features["useCorsMiddleware"] {
 				w.Header().Add("Access-Control-Allow-Origin", "*")
-				w.Header().Add("Access-Control-Allow-Headers", "*")
+				// the wildcard does not cover Authorization, which carries
+				// session tokens
+				w.Header().Add("Access-Control-Allow-Headers", "*, Authorization")
 
 				// handle preflight
 				if r.Method == "OPTIONS" {
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+)
+
+// Everything needed to independently verify the tally of a proposal:
+// every vote with its signed message, composite signatures and snapshot
+// balances, along with the stored results.
+type ProposalAudit struct {
+	Proposal     Proposal           `json:"proposal"`
+	Block_height *uint64            `json:"blockHeight"`
+	Votes        []*VoteWithBalance `json:"votes"`
+	Results      *ProposalResults   `json:"results,omitempty"`
+	Ballot_key   *ProposalKey       `json:"ballotKey,omitempty"`
+	Report       *AuditReport       `json:"report,omitempty"`
+}
+
+type AuditReport struct {
+	Votes_checked      int             `json:"votesChecked"`
+	Invalid_signatures []string        `json:"invalidSignatures"`
+	Invalid_ballots    []string        `json:"invalidBallots"`
+	Tally              ProposalResults `json:"tally"`
+	// nil when the proposal has no stored results yet
+	Results_match *bool `json:"resultsMatch"`
+	Verified      bool  `json:"verified"`
+}
+
+// The report of a proposal whose results are stored does not change, so
+// it is stored once verified rather than re-checking every signature on
+// chain for each request.
+func GetAuditReport(db *s.Database, proposalId int) (*AuditReport, error) {
+	var report AuditReport
+	err := db.Conn.QueryRow(db.Context,
+		`SELECT report FROM proposal_audit_reports WHERE proposal_id = $1`,
+		proposalId).Scan(&report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *AuditReport) CreateAuditReport(db *s.Database, proposalId int) error {
+	_, err := db.Conn.Exec(db.Context,
+		`
+		INSERT INTO proposal_audit_reports(proposal_id, report)
+		VALUES($1, $2)
+		ON CONFLICT DO NOTHING
+		`, proposalId, r)
+	return err
+}
+
+// Returns true if the tally has the same results as the stored results,
+// allowing for float rounding.
+func (r *ProposalResults) Matches(other *ProposalResults) bool {
+	const tolerance = 0.000001
+
+	if len(r.Results) != len(other.Results) || len(r.Results_float) != len(other.Results_float) {
+		return false
+	}
+	for choice, total := range r.Results {
+		if other.Results[choice] != total {
+			return false
+		}
+	}
+	for choice, total := range r.Results_float {
+		diff := other.Results_float[choice] - total
+		if diff > tolerance || diff < -tolerance {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Checks a decoded <proposalId>:<choice>:<timestamp> message was signed
+// for this proposal and the vote's ballot.
+func (v *Vote) MatchesSignedMessage(message string, p Proposal) error {
+	vars := strings.Split(message, ":")
+	if len(vars) != 3 || vars[0] != strconv.Itoa(p.ID) {
+		return errors.New("vote message was not signed for this proposal")
+	}
+
+	if p.IsWeighted() {
+		return v.validateMessageAllocations(message)
+	}
+	return v.validateMessageChoices(message)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	s "github.com/DapperCollectives/CAST/backend/main/shared"
@@ -21,27 +22,74 @@ type Balance struct {
 	BlockHeight             uint64    `json:"blockHeight"`
 	Proposal_id             int       `json:"proposal_id"`
 	NFTCount                int       `json:"nftCount"`
+	FungibleTokenID         string    `json:"fungibleTokenId,omitempty"`
 	CreatedAt               time.Time `json:"createdAt"`
 }
 
 func (b *Balance) GetBalanceByAddressAndBlockHeight(db *s.Database) error {
 	sql := `
 	SELECT * from balances as b
-	WHERE b.addr = $1 and b.block_height = $2
+	WHERE b.addr = $1 and b.block_height = $2 and b.fungible_token_id = $3
 	`
-	return pgxscan.Get(db.Context, db.Conn, b, sql, b.Addr, b.BlockHeight)
+	return pgxscan.Get(db.Context, db.Conn, b, sql, b.Addr, b.BlockHeight, b.FungibleTokenID)
+}
+
+// Returns the token whose balances weigh votes with the strategy on the
+// proposal. Strategies that don't weigh token balances store their
+// balances without a token.
+func BalanceTokenId(db *s.Database, proposalId int, strategy string) (string, error) {
+	if !RequiresSnapshot(strategy) {
+		return "", nil
+	}
+
+	var c Community
+	if err := c.GetCommunityByProposalId(db, proposalId); err != nil {
+		return "", err
+	}
+	if c.Strategies == nil {
+		return "", fmt.Errorf("community has no strategy %s", strategy)
+	}
+	st, err := MatchStrategyByProposal(*c.Strategies, strategy)
+	if err != nil {
+		return "", err
+	}
+	if st.Contract.Name == nil && !st.Contract.IsEVM() {
+		return "", fmt.Errorf("strategy %s has no contract name", strategy)
+	}
+
+	return s.FungibleTokenId(&st.Contract), nil
+}
+
+// Returns the token whose balances weigh votes with the proposal's
+// strategy.
+func proposalBalanceTokenId(db *s.Database, proposalId int) (string, error) {
+	var strategy *string
+	err := db.Conn.QueryRow(db.Context,
+		`SELECT strategy FROM proposals WHERE id = $1`, proposalId,
+	).Scan(&strategy)
+	if err != nil {
+		return "", err
+	}
+	if strategy == nil {
+		return "", nil
+	}
+	return BalanceTokenId(db, proposalId, *strategy)
 }
 
 func (b *Balance) CreateBalance(db *s.Database) error {
 	sql := `
 	INSERT INTO balances (addr, primary_account_balance, secondary_address,
-	    secondary_account_balance, staking_balance, script_result, stakes, block_height, id)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	    secondary_account_balance, staking_balance, script_result, stakes, block_height, id,
+	    fungible_token_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT DO NOTHING
 	`
 
+	// balances of a token at a block height are only stored once, and
+	// may already be cached by the snapshot engine
 	_, err := db.Conn.Exec(db.Context, sql,
 		b.Addr, b.PrimaryAccountBalance, b.SecondaryAddress, b.SecondaryAccountBalance,
-		b.StakingBalance, b.ScriptResult, b.Stakes, b.BlockHeight, uuid.New(),
+		b.StakingBalance, b.ScriptResult, b.Stakes, b.BlockHeight, uuid.New(), b.FungibleTokenID,
 	)
 
 	if err != nil {
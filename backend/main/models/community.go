@@ -40,6 +40,9 @@ type Community struct {
 	Contract_type *string `json:"contractType,omitempty"`
 	Public_path   *string `json:"publicPath,omitempty"`
 
+	// ID of the community in the VotingCommunity contract
+	Onchain_id *uint64 `json:"onchainId,omitempty"`
+
 	Timestamp            string                  `json:"timestamp"             validate:"required"`
 	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures"`
 	Creator_addr         string                  `json:"creatorAddr"           validate:"required"`
@@ -81,6 +84,7 @@ type UpdateCommunityRequestPayload struct {
 	Contract_type *string  `json:"contractType,omitempty"`
 	Public_path   *string  `json:"publicPath,omitempty"`
 	Threshold     *float64 `json:"threshold,omitempty"`
+	Onchain_id    *uint64  `json:"onchainId,omitempty"`
 
 	s.TimestampSignaturePayload
 }
@@ -269,8 +273,9 @@ func (c *Community) UpdateCommunity(db *s.Database, p *UpdateCommunityRequestPay
 	contract_addr = COALESCE($17, contract_addr),
 	contract_type = COALESCE($18, contract_type),
 	public_path = COALESCE($19, public_path),
-	only_authors_to_submit = COALESCE($20, only_authors_to_submit)
-	WHERE id = $21
+	only_authors_to_submit = COALESCE($20, only_authors_to_submit),
+	onchain_id = COALESCE($21, onchain_id)
+	WHERE id = $22
 	`,
 		p.Name,
 		p.Body,
@@ -292,6 +297,7 @@ func (c *Community) UpdateCommunity(db *s.Database, p *UpdateCommunityRequestPay
 		p.Contract_type,
 		p.Public_path,
 		p.Only_authors_to_submit,
+		p.Onchain_id,
 		c.ID,
 	)
 
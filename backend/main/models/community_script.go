@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+// The largest Cadence script, in bytes, a community can register.
+const MaxCommunityScriptSize = 16 * 1024
+
+// A Cadence script a community registers for the custom-script strategy.
+// It takes the voter's address and returns the IDs of the NFTs that
+// count towards their vote weight. Community strategies reference the
+// script by key in their contract's script field.
+type CommunityScript struct {
+	ID           int        `json:"id"`
+	Community_id int        `json:"communityId"`
+	Key          string     `json:"key" validate:"required"`
+	Name         string     `json:"name" validate:"required"`
+	Description  *string    `json:"description,omitempty"`
+	Src          string     `json:"src" validate:"required"`
+	Creator_addr string     `json:"creatorAddr"`
+	Created_at   *time.Time `json:"createdAt,omitempty"`
+	Updated_at   *time.Time `json:"updatedAt,omitempty"`
+}
+
+type CommunityScriptPayload struct {
+	CommunityScript
+	// The contract the script's placeholders are replaced with when it
+	// is dry run.
+	Contract s.Contract `json:"contract"`
+
+	s.TimestampSignaturePayload
+}
+
+var communityScriptKey = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+func GetCommunityScripts(db *s.Database, communityId int) ([]*CommunityScript, error) {
+	scripts := []*CommunityScript{}
+	err := pgxscan.Select(db.Context, db.Conn, &scripts,
+		`SELECT * FROM community_scripts WHERE community_id = $1 ORDER BY key ASC`,
+		communityId)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return scripts, nil
+}
+
+func (cs *CommunityScript) GetCommunityScriptByKey(db *s.Database) error {
+	return pgxscan.Get(db.Context, db.Conn, cs,
+		`SELECT * FROM community_scripts WHERE community_id = $1 AND key = $2`,
+		cs.Community_id, cs.Key)
+}
+
+func (cs *CommunityScript) CreateCommunityScript(db *s.Database) error {
+	return db.Conn.QueryRow(db.Context,
+		`
+		INSERT INTO community_scripts(community_id, key, name, description, src, creator_addr)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+		`, cs.Community_id, cs.Key, cs.Name, cs.Description, cs.Src, cs.Creator_addr,
+	).Scan(&cs.ID, &cs.Created_at, &cs.Updated_at)
+}
+
+func (cs *CommunityScript) UpdateCommunityScript(db *s.Database) error {
+	return db.Conn.QueryRow(db.Context,
+		`
+		UPDATE community_scripts
+		SET name = $1, description = $2, src = $3, creator_addr = $4,
+			updated_at = (now() at time zone 'utc')
+		WHERE id = $5
+		RETURNING updated_at
+		`, cs.Name, cs.Description, cs.Src, cs.Creator_addr, cs.ID,
+	).Scan(&cs.Updated_at)
+}
+
+func (cs *CommunityScript) DeleteCommunityScript(db *s.Database) error {
+	_, err := db.Conn.Exec(db.Context,
+		`DELETE FROM community_scripts WHERE id = $1`,
+		cs.ID)
+	return err
+}
+
+// Returns whether any of the community's strategies weigh votes with the
+// script.
+func (cs *CommunityScript) IsUsedBy(c *Community) bool {
+	if c.Strategies == nil {
+		return false
+	}
+	for _, st := range *c.Strategies {
+		if st.Name != nil && *st.Name == "custom-script" &&
+			st.Contract.Script != nil && *st.Contract.Script == cs.Key {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts the community's published proposals that have not ended and
+// weigh votes with the custom-script strategy.
+func CountLiveCustomScriptProposals(db *s.Database, communityId int) (int, error) {
+	var count int
+	err := db.Conn.QueryRow(db.Context,
+		`
+		SELECT COUNT(*) FROM proposals
+		WHERE community_id = $1
+		AND status = 'published'
+		AND end_time > (now() at time zone 'utc')
+		AND (strategy = 'custom-script' OR strategies @> '[{"name": "custom-script"}]')
+		`, communityId,
+	).Scan(&count)
+	return count, err
+}
+
+func (cs *CommunityScript) Validate() error {
+	if !communityScriptKey.MatchString(cs.Key) {
+		return errors.New("script key must be lowercase letters, numbers and dashes")
+	}
+	if len(cs.Src) == 0 {
+		return errors.New("script src is required")
+	}
+	if len(cs.Src) > MaxCommunityScriptSize {
+		return fmt.Errorf("script src must be at most %d bytes", MaxCommunityScriptSize)
+	}
+
+	return nil
+}
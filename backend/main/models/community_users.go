@@ -36,10 +36,9 @@ type UserCommunity struct {
 
 type CommunityUserPayload struct {
 	CommunityUser
-	Signing_addr         string                  `json:"signingAddr"`
-	Timestamp            string                  `json:"timestamp"`
-	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures"`
-	Voucher              *s.Voucher              `json:"voucher"`
+	Voucher *s.Voucher `json:"voucher"`
+
+	s.TimestampSignaturePayload
 }
 
 type UserAchievements = []struct {
@@ -0,0 +1,295 @@
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+type Delegation struct {
+	ID                   int                     `json:"id"`
+	Community_id         int                     `json:"communityId"`
+	Delegator            string                  `json:"delegator"           validate:"required"`
+	Delegate             string                  `json:"delegate"            validate:"required"`
+	Strategy             *string                 `json:"strategy,omitempty"`
+	Message              string                  `json:"message"             validate:"required"`
+	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures" validate:"required"`
+	Created_at           *time.Time              `json:"createdAt,omitempty"`
+	Revoked_at           *time.Time              `json:"revokedAt,omitempty"`
+}
+
+type RevokeDelegationPayload struct {
+	ID                   int                     `json:"id"`
+	Community_id         int                     `json:"communityId"`
+	Delegator            string                  `json:"delegator"           validate:"required"`
+	Message              string                  `json:"message"             validate:"required"`
+	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures" validate:"required"`
+}
+
+// A snapshot balance delegated to a voter by an address that did not
+// vote on the proposal itself.
+type DelegatedBalance struct {
+	Addr                    string  `json:"addr"`
+	Delegate                string  `json:"-"`
+	PrimaryAccountBalance   *uint64 `json:"primaryAccountBalance"`
+	SecondaryAccountBalance *uint64 `json:"secondaryAccountBalance"`
+	StakingBalance          *uint64 `json:"stakingBalance"`
+}
+
+func GetDelegationsFromAddress(db *s.Database, addr string) ([]*Delegation, error) {
+	return getActiveDelegations(db, "delegator", addr)
+}
+
+func GetDelegationsToAddress(db *s.Database, addr string) ([]*Delegation, error) {
+	return getActiveDelegations(db, "delegate", addr)
+}
+
+func getActiveDelegations(db *s.Database, column, addr string) ([]*Delegation, error) {
+	delegations := []*Delegation{}
+	sql := fmt.Sprintf(`
+		SELECT * FROM delegations
+		WHERE %s = $1 AND revoked_at IS NULL
+		ORDER BY community_id ASC, created_at DESC
+	`, column)
+
+	err := pgxscan.Select(db.Context, db.Conn, &delegations, sql, addr)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return delegations, nil
+}
+
+func (d *Delegation) GetDelegationById(db *s.Database) error {
+	return pgxscan.Get(db.Context, db.Conn, d,
+		`SELECT * FROM delegations WHERE id = $1`,
+		d.ID)
+}
+
+// Creates the delegation, revoking any active delegation from the
+// delegator it replaces.
+func (d *Delegation) CreateDelegation(db *s.Database) error {
+	tx, err := db.Conn.Begin(db.Context)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(db.Context)
+
+	_, err = tx.Exec(db.Context,
+		`
+		UPDATE delegations
+		SET revoked_at = (now() at time zone 'utc')
+		WHERE community_id = $1 AND delegator = $2
+		AND COALESCE(strategy, '') = COALESCE($3, '') AND revoked_at IS NULL
+		`, d.Community_id, d.Delegator, d.Strategy)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(db.Context,
+		`
+		INSERT INTO delegations(community_id, delegator, delegate, strategy, message, composite_signatures)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+		`, d.Community_id, d.Delegator, d.Delegate, d.Strategy, d.Message, d.Composite_signatures).
+		Scan(&d.ID, &d.Created_at)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(db.Context)
+}
+
+func (d *Delegation) RevokeDelegation(db *s.Database) error {
+	return db.Conn.QueryRow(db.Context,
+		`
+		UPDATE delegations
+		SET revoked_at = (now() at time zone 'utc')
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING revoked_at
+		`, d.ID).Scan(&d.Revoked_at)
+}
+
+// Validates a hex encoded <communityId>:<delegate>:<strategy>:<timestamp>
+// message against the delegation. The strategy is left empty to
+// delegate for every strategy of the community.
+func (d *Delegation) ValidateMessage() error {
+	decoded, err := hex.DecodeString(d.Message)
+	if err != nil {
+		return errors.New("delegation message must be hex encoded")
+	}
+
+	vars := strings.Split(string(decoded), ":")
+	if len(vars) != 4 {
+		return errors.New("invalid delegation message")
+	}
+
+	var strategy string
+	if d.Strategy != nil {
+		strategy = *d.Strategy
+	}
+
+	if vars[0] != strconv.Itoa(d.Community_id) || vars[1] != d.Delegate || vars[2] != strategy {
+		return errors.New("delegation message does not match request")
+	}
+
+	return validateMessageTimestamp(vars[3])
+}
+
+// Validates a hex encoded <communityId>:revoke:<delegationId>:<timestamp>
+// message against the request.
+func (r *RevokeDelegationPayload) ValidateMessage() error {
+	decoded, err := hex.DecodeString(r.Message)
+	if err != nil {
+		return errors.New("revoke message must be hex encoded")
+	}
+
+	vars := strings.Split(string(decoded), ":")
+	if len(vars) != 4 || vars[1] != "revoke" {
+		return errors.New("invalid revoke message")
+	}
+
+	if vars[0] != strconv.Itoa(r.Community_id) || vars[2] != strconv.Itoa(r.ID) {
+		return errors.New("revoke message does not match request")
+	}
+
+	return validateMessageTimestamp(vars[3])
+}
+
+func validateMessageTimestamp(stamp string) error {
+	timestamp, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	uxTime := time.Unix(timestamp/1000, (timestamp%1000)*1000*1000)
+	diff := time.Now().UTC().Sub(uxTime).Seconds()
+	if diff > timestampExpiry {
+		return errors.New("timestamp on request has expired")
+	}
+	return nil
+}
+
+// Attaches the balances delegated to each voter in the community of the
+// proposal. A delegation counts if it was active when the proposal ended,
+// applies to the proposal's strategy, and the delegator has not voted on
+// the proposal directly. A strategy specific delegation takes precedence
+// over one for every strategy.
+func attachDelegations(db *s.Database, proposalId int, strategy string, votes []*VoteWithBalance) error {
+	if len(votes) == 0 || !IsDelegableStrategy(strategy) {
+		return nil
+	}
+
+	byDelegate := make(map[string]*VoteWithBalance, len(votes))
+	voters := make([]string, 0, len(votes))
+	for _, v := range votes {
+		byDelegate[v.Addr] = v
+		voters = append(voters, v.Addr)
+	}
+
+	tokenId, err := BalanceTokenId(db, proposalId, strategy)
+	if err != nil {
+		return err
+	}
+
+	var delegated []*DelegatedBalance
+	err = pgxscan.Select(db.Context, db.Conn, &delegated,
+		`
+		SELECT DISTINCT ON (d.delegator)
+			d.delegator as addr,
+			d.delegate,
+			b.primary_account_balance,
+			b.secondary_account_balance,
+			b.staking_balance
+		FROM delegations d
+		JOIN proposals p ON p.id = $1
+		LEFT JOIN balances b ON b.addr = d.delegator
+			AND b.block_height = p.block_height
+			AND b.fungible_token_id = $3
+		WHERE d.community_id = p.community_id
+		AND d.delegate = ANY($2)
+		AND (d.strategy IS NULL OR d.strategy = p.strategy)
+		AND d.created_at <= p.end_time
+		AND (d.revoked_at IS NULL OR d.revoked_at > p.end_time)
+		AND NOT EXISTS (
+			SELECT 1 FROM votes v
+			WHERE v.proposal_id = p.id AND v.addr = d.delegator
+		)
+		ORDER BY d.delegator, d.strategy NULLS LAST, d.created_at DESC
+		`, proposalId, voters, tokenId)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return err
+	}
+
+	for _, d := range delegated {
+		v := byDelegate[d.Delegate]
+		v.Delegations = append(v.Delegations, d)
+	}
+
+	return nil
+}
+
+// Returns the balances delegated to an address on the proposal, as
+// they would be attached to the address's vote.
+func GetDelegatedBalances(db *s.Database, proposalId int, strategy, delegate string) ([]*DelegatedBalance, error) {
+	vb := &VoteWithBalance{Vote: Vote{Addr: delegate, Proposal_id: proposalId}}
+	if err := attachDelegations(db, proposalId, strategy, []*VoteWithBalance{vb}); err != nil {
+		return nil, err
+	}
+	return vb.Delegations, nil
+}
+
+// Returns the live proposals of the community the address has voted on.
+func GetLiveProposalsVotedOnBy(db *s.Database, communityId int, addr string) ([]*Proposal, error) {
+	var proposals []*Proposal
+	sql := fmt.Sprintf(`
+		SELECT *, %s FROM proposals
+		WHERE community_id = $1
+		AND status = 'published'
+		AND start_time < (now() at time zone 'utc')
+		AND end_time > (now() at time zone 'utc')
+		AND id IN (SELECT proposal_id FROM votes WHERE addr = $2)
+	`, computedStatusSQL)
+
+	err := pgxscan.Select(db.Context, db.Conn, &proposals, sql, communityId, addr)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+// The voter's primary balance plus the primary balances delegated to them.
+func (vb *VoteWithBalance) PrimaryBalanceWithDelegations() uint64 {
+	var total uint64
+	if vb.PrimaryAccountBalance != nil {
+		total = *vb.PrimaryAccountBalance
+	}
+	for _, d := range vb.Delegations {
+		if d.PrimaryAccountBalance != nil {
+			total += *d.PrimaryAccountBalance
+		}
+	}
+	return total
+}
+
+// The voter's staking balance plus the staking balances delegated to them.
+func (vb *VoteWithBalance) StakingBalanceWithDelegations() uint64 {
+	var total uint64
+	if vb.StakingBalance != nil {
+		total = *vb.StakingBalance
+	}
+	for _, d := range vb.Delegations {
+		if d.StakingBalance != nil {
+			total += *d.StakingBalance
+		}
+	}
+	return total
+}
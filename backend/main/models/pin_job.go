@@ -0,0 +1,195 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+// A vote or proposal that could not be pinned to IPFS when it was
+// created. The record is stored with a pending pin status and the job
+// is retried until its payload is pinned and the record's cid is set.
+type PinJob struct {
+	ID              int             `json:"id"`
+	Community_id    int             `json:"communityId"`
+	Record_type     string          `json:"recordType"`
+	Record_id       int             `json:"recordId"`
+	Payload         json.RawMessage `json:"payload"`
+	Status          string          `json:"status"`
+	Attempts        int             `json:"attempts"`
+	Error           *string         `json:"error,omitempty"`
+	Next_attempt_at *time.Time      `json:"nextAttemptAt,omitempty"`
+	Pinned_at       *time.Time      `json:"pinnedAt,omitempty"`
+	Created_at      *time.Time      `json:"createdAt,omitempty"`
+}
+
+const (
+	PinRecordVote            = "vote"
+	PinRecordProposal        = "proposal"
+	PinRecordProposalResults = "proposal_results"
+	PinRecordProposalKey     = "proposal_key"
+)
+
+const (
+	PinPending = "pending"
+	PinPinned  = "pinned"
+	PinFailed  = "failed"
+)
+
+// The table and key column of each record type. Proposal results and
+// keys are keyed by their proposal.
+var pinRecordTables = map[string][2]string{
+	PinRecordVote:            {"votes", "id"},
+	PinRecordProposal:        {"proposals", "id"},
+	PinRecordProposalResults: {"proposal_results", "proposal_id"},
+	PinRecordProposalKey:     {"proposal_keys", "proposal_id"},
+}
+
+// Queues the job, replacing any pending job for the same record, as
+// only the record's latest payload should be pinned.
+func (j *PinJob) CreatePinJob(db *s.Database) error {
+	tx, err := db.Conn.Begin(db.Context)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(db.Context)
+
+	_, err = tx.Exec(db.Context,
+		`DELETE FROM pin_jobs WHERE record_type = $1 AND record_id = $2 AND status = 'pending'`,
+		j.Record_type, j.Record_id)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(db.Context,
+		`
+		INSERT INTO pin_jobs(community_id, record_type, record_id, payload, status, next_attempt_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+		`, j.Community_id, j.Record_type, j.Record_id, j.Payload, j.Status, j.Next_attempt_at,
+	).Scan(&j.ID, &j.Created_at)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(db.Context)
+}
+
+// Drops the pending jobs for a record that has since been pinned.
+func CancelPendingPinJobs(db *s.Database, recordType string, recordId int) error {
+	_, err := db.Conn.Exec(db.Context,
+		`DELETE FROM pin_jobs WHERE record_type = $1 AND record_id = $2 AND status = 'pending'`,
+		recordType, recordId)
+	return err
+}
+
+// Claims pending jobs that are due for an attempt, leasing them so
+// concurrent workers do not pin the same payload.
+func ClaimDuePinJobs(db *s.Database, limit int, lease time.Duration) ([]*PinJob, error) {
+	jobs := []*PinJob{}
+	leaseUntil := time.Now().UTC().Add(lease)
+	err := pgxscan.Select(db.Context, db.Conn, &jobs,
+		`
+		UPDATE pin_jobs
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM pin_jobs
+			WHERE status = 'pending' AND next_attempt_at <= (now() at time zone 'utc')
+			ORDER BY next_attempt_at ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+		`, leaseUntil, limit)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Records a failed attempt.
+func (j *PinJob) UpdatePinJob(db *s.Database) error {
+	_, err := db.Conn.Exec(db.Context,
+		`
+		UPDATE pin_jobs
+		SET status = $1, attempts = $2, error = $3, next_attempt_at = $4
+		WHERE id = $5
+		`, j.Status, j.Attempts, j.Error, j.Next_attempt_at, j.ID)
+	return err
+}
+
+// Marks the job pinned and sets the record's cid. Jobs replaced while
+// they were being attempted leave the record untouched.
+func (j *PinJob) CompletePinJob(db *s.Database, cid string) error {
+	table, ok := pinRecordTables[j.Record_type]
+	if !ok {
+		return fmt.Errorf("unknown pin record type: %s", j.Record_type)
+	}
+
+	tx, err := db.Conn.Begin(db.Context)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(db.Context)
+
+	err = tx.QueryRow(db.Context,
+		`
+		UPDATE pin_jobs
+		SET status = 'pinned', attempts = $1, error = NULL, next_attempt_at = NULL,
+			pinned_at = (now() at time zone 'utc')
+		WHERE id = $2 AND status = 'pending'
+		RETURNING pinned_at
+		`, j.Attempts, j.ID).Scan(&j.Pinned_at)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return nil
+		}
+		return err
+	}
+	j.Status = PinPinned
+	j.Error = nil
+	j.Next_attempt_at = nil
+
+	_, err = tx.Exec(db.Context,
+		fmt.Sprintf(`UPDATE %s SET cid = $1, pin_status = 'pinned' WHERE %s = $2`, table[0], table[1]),
+		cid, j.Record_id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(db.Context)
+}
+
+// Returns the community's jobs still pending and the jobs that ran out
+// of attempts. A status narrows the list to jobs with that status.
+func GetStuckPinJobs(db *s.Database, communityId int, status string, params s.PageParams) ([]*PinJob, int, error) {
+	statuses := []string{PinPending, PinFailed}
+	if status != "" {
+		statuses = []string{status}
+	}
+
+	jobs := []*PinJob{}
+	sql := fmt.Sprintf(`
+		SELECT * FROM pin_jobs
+		WHERE community_id = $3 AND status = ANY($4)
+		ORDER BY created_at %s
+		LIMIT $1 OFFSET $2
+	`, params.Order)
+
+	err := pgxscan.Select(db.Context, db.Conn, &jobs, sql, params.Count, params.Start, communityId, statuses)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, 0, err
+	}
+
+	var totalRecords int
+	countSql := `SELECT COUNT(*) FROM pin_jobs WHERE community_id = $1 AND status = ANY($2)`
+	_ = db.Conn.QueryRow(db.Context, countSql, communityId, statuses).Scan(&totalRecords)
+
+	return jobs, totalRecords, nil
+}
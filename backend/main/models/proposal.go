@@ -5,6 +5,7 @@ package models
 ///////////////
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -18,29 +19,54 @@ import (
 )
 
 type Proposal struct {
-	ID                   int                     `json:"id,omitempty"`
-	Name                 string                  `json:"name" validate:"required"`
-	Community_id         int                     `json:"communityId"`
-	Choices              []s.Choice              `json:"choices" validate:"required"`
-	Strategy             *string                 `json:"strategy,omitempty"`
-	Max_weight           *float64                 `json:"maxWeight,omitempty"`
-	Min_balance          *float64                 `json:"minBalance,omitempty"`
-	Creator_addr         string                  `json:"creatorAddr" validate:"required"`
-	Start_time           time.Time               `json:"startTime" validate:"required"`
-	Result               *string                 `json:"result,omitempty"`
-	End_time             time.Time               `json:"endTime" validate:"required"`
-	Created_at           *time.Time              `json:"createdAt,omitempty"`
-	Cid                  *string                 `json:"cid,omitempty"`
-	Status               *string                 `json:"status,omitempty"`
-	Body                 *string                 `json:"body,omitempty" validate:"required"`
-	Block_height         *uint64                 `json:"block_height"`
-	Total_votes          int                     `json:"total_votes"`
-	Timestamp            string                  `json:"timestamp" validate:"required"`
-	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures"`
-	Computed_status      *string                 `json:"computedStatus,omitempty"`
-	Snapshot_status      *string                 `json:"snapshotStatus,omitempty"`
-	Voucher              *shared.Voucher         `json:"voucher,omitempty"`
-	Achievements_done	 bool					 `json:"achievementsDone"`
+	ID                      int                     `json:"id,omitempty"`
+	Name                    string                  `json:"name" validate:"required"`
+	Community_id            int                     `json:"communityId"`
+	Choices                 []s.Choice              `json:"choices" validate:"required"`
+	Strategy                *string                 `json:"strategy,omitempty"`
+	Max_weight              *float64                `json:"maxWeight,omitempty"`
+	Min_balance             *float64                `json:"minBalance,omitempty"`
+	Creator_addr            string                  `json:"creatorAddr" validate:"required"`
+	Start_time              time.Time               `json:"startTime" validate:"required"`
+	Result                  *string                 `json:"result,omitempty"`
+	End_time                time.Time               `json:"endTime" validate:"required"`
+	Created_at              *time.Time              `json:"createdAt,omitempty"`
+	Cid                     *string                 `json:"cid,omitempty"`
+	Status                  *string                 `json:"status,omitempty"`
+	Body                    *string                 `json:"body,omitempty" validate:"required"`
+	Block_height            *uint64                 `json:"block_height"`
+	Total_votes             int                     `json:"total_votes"`
+	Timestamp               string                  `json:"timestamp" validate:"required"`
+	Composite_signatures    *[]s.CompositeSignature `json:"compositeSignatures"`
+	Computed_status         *string                 `json:"computedStatus,omitempty"`
+	Snapshot_status         *string                 `json:"snapshotStatus,omitempty"`
+	Voucher                 *shared.Voucher         `json:"voucher,omitempty"`
+	Achievements_done       bool                    `json:"achievementsDone"`
+	Voting_type             *string                 `json:"votingType,omitempty"`
+	Quorum                  *float64                `json:"quorum,omitempty"`
+	Quorum_type             *string                 `json:"quorumType,omitempty"`
+	Pass_threshold          *float64                `json:"passThreshold,omitempty"`
+	Pass_choice             *string                 `json:"passChoice,omitempty"`
+	Total_supply            *float64                `json:"totalSupply,omitempty"`
+	Processed_status        *string                 `json:"-"`
+	Transition_attempts     int                     `json:"-"`
+	Transition_retry_at     *time.Time              `json:"-"`
+	Results_tx_id           *string                 `json:"resultsTxId,omitempty"`
+	Results_tx_status       *string                 `json:"resultsTxStatus,omitempty"`
+	Results_tx_attempts     int                     `json:"-"`
+	Results_tx_error        *string                 `json:"-"`
+	Results_tx_retry_at     *time.Time              `json:"-"`
+	Results_tx_submitted_at *time.Time              `json:"-"`
+	Is_private              bool                    `json:"isPrivate"`
+	Publish_at              *time.Time              `json:"publishAt,omitempty"`
+	Publish_attempts        int                     `json:"-"`
+	Publish_retry_at        *time.Time              `json:"-"`
+	Publish_error           *string                 `json:"publishError,omitempty"`
+	Strategies              *[]ProposalStrategy     `json:"strategies,omitempty"`
+	Strategy_combination    *string                 `json:"strategyCombination,omitempty"`
+	Pin_status              string                  `json:"pinStatus,omitempty"`
+	Snapshot_time           *time.Time              `json:"snapshotTime,omitempty"`
+	Snapshot_block_height   *uint64                 `json:"snapshotBlockHeight,omitempty"`
 }
 
 type UpdateProposalRequestPayload struct {
@@ -50,6 +76,20 @@ type UpdateProposalRequestPayload struct {
 	s.TimestampSignaturePayload
 }
 
+const (
+	SingleChoice = "single-choice"
+	RankedChoice = "ranked-choice"
+	Approval     = "approval"
+	Weighted     = "weighted"
+)
+
+var votingTypes = []string{SingleChoice, RankedChoice, Approval, Weighted}
+
+const (
+	QuorumAbsolute   = "absolute"
+	QuorumPercentage = "percentage"
+)
+
 var computedStatusSQL = `
 	CASE
 		WHEN status = 'published' AND start_time > (now() at time zone 'utc') THEN 'pending'
@@ -57,9 +97,43 @@ var computedStatusSQL = `
 		WHEN status = 'published' AND end_time < (now() at time zone 'utc') THEN 'closed'
 		WHEN status = 'cancelled' THEN 'cancelled'
 		WHEN status = 'closed' THEN 'closed'
+		WHEN status = 'draft' THEN 'draft'
 	END as computed_status
 	`
 
+// A proposal with its final results, if it has closed.
+type ProposalWithResults struct {
+	Proposal
+	Tally *ProposalResults `json:"results,omitempty"`
+}
+
+// Returns up to limit proposals of the community with an id after
+// afterId, ordered by id, along with their stored results.
+func GetProposalsWithResultsAfter(
+	db *s.Database,
+	communityId int,
+	afterId int,
+	limit int,
+) ([]*ProposalWithResults, error) {
+	var proposals []*ProposalWithResults
+	sql := fmt.Sprintf(`
+		SELECT p.*, r.tally || jsonb_build_object('cid', r.cid) AS tally, %s,
+			(SELECT count(*) FROM votes v WHERE v.proposal_id = p.id) AS total_votes
+		FROM proposals p
+		LEFT JOIN proposal_results r ON r.proposal_id = p.id
+		WHERE p.community_id = $1 AND p.id > $2 AND p.status <> 'draft'
+		ORDER BY p.id ASC
+		LIMIT $3
+	`, computedStatusSQL)
+
+	err := pgxscan.Select(db.Context, db.Conn, &proposals, sql, communityId, afterId, limit)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
 func GetProposalsForCommunity(
 	db *s.Database,
 	communityId int,
@@ -74,7 +148,7 @@ func GetProposalsForCommunity(
 	statusFilter := ""
 
 	// Generate SQL based on computed status
-	// status: { pending | active | closed | cancelled }
+	// status: { pending | active | closed | cancelled | draft }
 	switch status {
 	case "pending":
 		statusFilter = ` AND status = 'published' AND start_time > (now() at time zone 'utc')`
@@ -88,6 +162,10 @@ func GetProposalsForCommunity(
 		statusFilter = ` AND (status = 'cancelled' OR (status = 'published' AND end_time < (now() at time zone 'utc')))`
 	case "inprogress":
 		statusFilter = ` AND status = 'published' AND end_time > (now() at time zone 'utc')`
+	case "draft":
+		statusFilter = ` AND status = 'draft'`
+	default:
+		statusFilter = ` AND status <> 'draft'`
 	}
 
 	orderBySql := fmt.Sprintf(` ORDER BY created_at %s`, params.Order)
@@ -123,6 +201,10 @@ func (p *Proposal) GetProposalById(db *s.Database) error {
 }
 
 func (p *Proposal) CreateProposal(db *s.Database) error {
+	if p.Pin_status == "" {
+		p.Pin_status = PinPinned
+	}
+
 	err := db.Conn.QueryRow(db.Context,
 		`
 	INSERT INTO proposals(community_id, 
@@ -139,9 +221,22 @@ func (p *Proposal) CreateProposal(db *s.Database) error {
 	block_height, 
 	cid, 
 	composite_signatures,
-	voucher
+	voucher,
+	voting_type,
+	quorum,
+	quorum_type,
+	pass_threshold,
+	total_supply,
+	is_private,
+	publish_at,
+	strategies,
+	strategy_combination,
+	pin_status,
+	snapshot_time,
+	snapshot_block_height,
+	pass_choice
 	)
-	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28)
 	RETURNING id, created_at
 	`,
 		p.Community_id,
@@ -159,6 +254,19 @@ func (p *Proposal) CreateProposal(db *s.Database) error {
 		p.Cid,
 		p.Composite_signatures,
 		p.Voucher,
+		p.Voting_type,
+		p.Quorum,
+		p.Quorum_type,
+		p.Pass_threshold,
+		p.Total_supply,
+		p.Is_private,
+		p.Publish_at,
+		p.Strategies,
+		p.Strategy_combination,
+		p.Pin_status,
+		p.Snapshot_time,
+		p.Snapshot_block_height,
+		p.Pass_choice,
 	).Scan(&p.ID, &p.Created_at)
 
 	return err
@@ -202,13 +310,161 @@ func (p *Proposal) UpdateSnapshotStatus(db *s.Database) error {
 	return err
 }
 
+func (p *Proposal) HasChoice(choice string) bool {
+	for _, c := range p.Choices {
+		if c.Choice_text == choice {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Proposal) IsRankedChoice() bool {
+	return p.Voting_type != nil && *p.Voting_type == RankedChoice
+}
+
+func (p *Proposal) IsApproval() bool {
+	return p.Voting_type != nil && *p.Voting_type == Approval
+}
+
+// Weighted ballots split the voter's weight across choices
+// by percentage.
+func (p *Proposal) IsWeighted() bool {
+	return p.Voting_type != nil && *p.Voting_type == Weighted
+}
+
+// Ranked choice and approval ballots carry a list of choices
+// rather than a single choice.
+func (p *Proposal) AcceptsMultipleChoices() bool {
+	return p.IsRankedChoice() || p.IsApproval()
+}
+
+// Percentage quorums are measured against the token supply
+// at the proposal snapshot.
+func (p *Proposal) RequiresTotalSupply() bool {
+	return p.Quorum != nil && p.Quorum_type != nil && *p.Quorum_type == QuorumPercentage
+}
+
+// Returns the vote weight needed to reach quorum, or zero
+// if the proposal has no quorum.
+func (p *Proposal) QuorumWeight() float64 {
+	if p.Quorum == nil {
+		return 0
+	}
+	if p.RequiresTotalSupply() {
+		if p.Total_supply == nil {
+			return 0
+		}
+		return *p.Total_supply * *p.Quorum / 100
+	}
+	return *p.Quorum
+}
+
 func (p *Proposal) IsLive() bool {
 	now := time.Now().UTC()
-	return now.After(p.Start_time) && now.Before(p.End_time)
+	return !p.IsDraft() && now.After(p.Start_time) && now.Before(p.End_time)
+}
+
+// Ballots on a private proposal stay sealed until the proposal ends.
+func (p *Proposal) BallotsSealed() bool {
+	return p.Is_private && time.Now().UTC().Before(p.End_time)
 }
 
 // Validations
 
+// Sets the voting type to single-choice when not provided, and
+// returns an error if the voting type is not supported.
+func (p *Proposal) ValidateVotingType() error {
+	if p.Voting_type == nil {
+		singleChoice := SingleChoice
+		p.Voting_type = &singleChoice
+		return nil
+	}
+
+	for _, t := range votingTypes {
+		if *p.Voting_type == t {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid voting type: %s", *p.Voting_type)
+}
+
+// Sets the quorum type to absolute when a quorum is provided without one,
+// and returns an error if the quorum or pass threshold is out of range.
+func (p *Proposal) ValidateQuorum() error {
+	if p.Quorum != nil {
+		if p.Quorum_type == nil {
+			quorumType := QuorumAbsolute
+			p.Quorum_type = &quorumType
+		}
+		if *p.Quorum_type != QuorumAbsolute && *p.Quorum_type != QuorumPercentage {
+			return fmt.Errorf("invalid quorum type: %s", *p.Quorum_type)
+		}
+		if *p.Quorum < 0 {
+			return errors.New("quorum must not be negative")
+		}
+		if p.RequiresTotalSupply() && *p.Quorum > 100 {
+			return errors.New("quorum percentage must be between 0 and 100")
+		}
+		if p.RequiresTotalSupply() {
+			for _, name := range p.StrategyNames() {
+				if !IsTokenWeightedStrategy(name) {
+					return fmt.Errorf("percentage quorum is not supported for strategy %s", name)
+				}
+			}
+		}
+	}
+
+	if p.Pass_threshold != nil && (*p.Pass_threshold < 0 || *p.Pass_threshold > 100) {
+		return errors.New("pass threshold must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// Returns the choice that must win for the proposal to pass. Unless the
+// proposal gives one, it is the first choice, as in "For" or "Yes" of
+// a for/against proposal.
+func (p *Proposal) PassChoice() string {
+	if p.Pass_choice != nil {
+		return *p.Pass_choice
+	}
+	if len(p.Choices) == 0 {
+		return ""
+	}
+	return p.Choices[0].Choice_text
+}
+
+// Returns an error if the pass choice is not one of the proposal's choices.
+func (p *Proposal) ValidatePassChoice() error {
+	if p.Pass_choice == nil {
+		return nil
+	}
+	for _, c := range p.Choices {
+		if c.Choice_text == *p.Pass_choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("pass choice is not a choice of the proposal: %s", *p.Pass_choice)
+}
+
+// Snapshots are taken at the latest block, unless the proposal gives
+// a block height or a time to resolve to a block.
+func (p *Proposal) HasSnapshotBlock() bool {
+	return p.Snapshot_time != nil || p.Snapshot_block_height != nil
+}
+
+func (p *Proposal) ValidateSnapshotBlock(c *s.Contract) error {
+	if p.Snapshot_time != nil && p.Snapshot_block_height != nil {
+		return errors.New("snapshot time and snapshot block height are exclusive")
+	}
+	if p.Snapshot_time != nil && c.IsEVM() {
+		return errors.New("snapshot times are only supported for Flow contracts")
+	}
+	return nil
+}
+
 // Returns an error if the account's balance is insufficient to cast
 // a vote on the proposal.
 func (p *Proposal) ValidateBalance(weight float64) error {
@@ -0,0 +1,299 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+const (
+	ProposalDraft     = "draft"
+	ProposalPublished = "published"
+)
+
+// The fields of a draft that can be edited before it is published.
+// Omitted fields are left unchanged.
+type DraftProposalPayload struct {
+	Name                  *string             `json:"name,omitempty"`
+	Body                  *string             `json:"body,omitempty"`
+	Choices               *[]s.Choice         `json:"choices,omitempty"`
+	Start_time            *time.Time          `json:"startTime,omitempty"`
+	End_time              *time.Time          `json:"endTime,omitempty"`
+	Strategy              *string             `json:"strategy,omitempty"`
+	Strategies            *[]ProposalStrategy `json:"strategies,omitempty"`
+	Combination           *string             `json:"strategyCombination,omitempty"`
+	Voting_type           *string             `json:"votingType,omitempty"`
+	Is_private            *bool               `json:"isPrivate,omitempty"`
+	Publish_at            *time.Time          `json:"publishAt,omitempty"`
+	Max_weight            *float64            `json:"maxWeight,omitempty"`
+	Min_balance           *float64            `json:"minBalance,omitempty"`
+	Quorum                *float64            `json:"quorum,omitempty"`
+	Quorum_type           *string             `json:"quorumType,omitempty"`
+	Pass_threshold        *float64            `json:"passThreshold,omitempty"`
+	Pass_choice           *string             `json:"passChoice,omitempty"`
+	Snapshot_time         *time.Time          `json:"snapshotTime,omitempty"`
+	Snapshot_block_height *uint64             `json:"snapshotBlockHeight,omitempty"`
+
+	s.TimestampSignaturePayload
+}
+
+func (p *Proposal) IsDraft() bool {
+	return p.Status != nil && *p.Status == ProposalDraft
+}
+
+// Sets the status of a new proposal to published when not provided, and
+// returns an error if it is neither a draft nor published.
+func (p *Proposal) ValidateStatus() error {
+	if p.Status == nil {
+		published := ProposalPublished
+		p.Status = &published
+		return nil
+	}
+	if *p.Status != ProposalDraft && *p.Status != ProposalPublished {
+		return errors.New("A new proposal must be a draft or published.")
+	}
+	return nil
+}
+
+// Applies the edited fields of the payload to the draft.
+func (p *Proposal) ApplyDraft(d DraftProposalPayload) {
+	if d.Name != nil {
+		p.Name = *d.Name
+	}
+	if d.Body != nil {
+		p.Body = d.Body
+	}
+	if d.Choices != nil {
+		p.Choices = *d.Choices
+	}
+	if d.Start_time != nil {
+		p.Start_time = *d.Start_time
+	}
+	if d.End_time != nil {
+		p.End_time = *d.End_time
+	}
+	if d.Strategy != nil {
+		p.Strategy = d.Strategy
+	}
+	if d.Strategies != nil {
+		p.Strategies = d.Strategies
+	}
+	if d.Combination != nil {
+		p.Strategy_combination = d.Combination
+	}
+	if d.Voting_type != nil {
+		p.Voting_type = d.Voting_type
+	}
+	if d.Is_private != nil {
+		p.Is_private = *d.Is_private
+	}
+	if d.Publish_at != nil {
+		p.Publish_at = d.Publish_at
+	}
+	if d.Max_weight != nil {
+		p.Max_weight = d.Max_weight
+	}
+	if d.Min_balance != nil {
+		p.Min_balance = d.Min_balance
+	}
+	if d.Quorum != nil {
+		p.Quorum = d.Quorum
+	}
+	if d.Quorum_type != nil {
+		p.Quorum_type = d.Quorum_type
+	}
+	if d.Pass_threshold != nil {
+		p.Pass_threshold = d.Pass_threshold
+	}
+	if d.Pass_choice != nil {
+		p.Pass_choice = d.Pass_choice
+	}
+	// a snapshot is taken at either a time or a block height, so
+	// setting one clears the other
+	if d.Snapshot_time != nil {
+		p.Snapshot_time = d.Snapshot_time
+		p.Snapshot_block_height = nil
+	}
+	if d.Snapshot_block_height != nil {
+		p.Snapshot_block_height = d.Snapshot_block_height
+		p.Snapshot_time = nil
+	}
+}
+
+func (p *Proposal) UpdateDraft(db *s.Database) error {
+	tag, err := db.Conn.Exec(db.Context,
+		`
+	UPDATE proposals
+	SET name = $1,
+		body = $2,
+		choices = $3,
+		start_time = $4,
+		end_time = $5,
+		strategy = $6,
+		voting_type = $7,
+		is_private = $8,
+		publish_at = $9,
+		max_weight = $10,
+		min_balance = $11,
+		quorum = $12,
+		quorum_type = $13,
+		pass_threshold = $14,
+		strategies = $15,
+		strategy_combination = $16,
+		snapshot_time = $17,
+		snapshot_block_height = $18,
+		pass_choice = $19,
+		publish_attempts = 0,
+		publish_retry_at = NULL,
+		publish_error = NULL
+	WHERE id = $20 AND status = 'draft'
+	`,
+		p.Name,
+		p.Body,
+		p.Choices,
+		p.Start_time,
+		p.End_time,
+		p.Strategy,
+		p.Voting_type,
+		p.Is_private,
+		p.Publish_at,
+		p.Max_weight,
+		p.Min_balance,
+		p.Quorum,
+		p.Quorum_type,
+		p.Pass_threshold,
+		p.Strategies,
+		p.Strategy_combination,
+		p.Snapshot_time,
+		p.Snapshot_block_height,
+		p.Pass_choice,
+		p.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("Proposal is not a draft.")
+	}
+
+	return p.GetProposalById(db)
+}
+
+// Stores the snapshot and cid taken at publish time and moves the draft
+// to published.
+func (p *Proposal) PublishProposal(db *s.Database) error {
+	if p.Pin_status == "" {
+		p.Pin_status = PinPinned
+	}
+
+	tag, err := db.Conn.Exec(db.Context,
+		`
+	UPDATE proposals
+	SET status = 'published',
+		start_time = $1,
+		block_height = $2,
+		snapshot_status = COALESCE($3, snapshot_status),
+		cid = $4,
+		total_supply = $5,
+		voting_type = $6,
+		min_balance = $7,
+		max_weight = $8,
+		quorum = $9,
+		quorum_type = $10,
+		pass_threshold = $11,
+		strategy = $12,
+		strategies = $13,
+		strategy_combination = $14,
+		pin_status = $15,
+		publish_at = NULL,
+		publish_retry_at = NULL,
+		publish_error = NULL
+	WHERE id = $16 AND status = 'draft'
+	`,
+		p.Start_time,
+		p.Block_height,
+		p.Snapshot_status,
+		p.Cid,
+		p.Total_supply,
+		p.Voting_type,
+		p.Min_balance,
+		p.Max_weight,
+		p.Quorum,
+		p.Quorum_type,
+		p.Pass_threshold,
+		p.Strategy,
+		p.Strategies,
+		p.Strategy_combination,
+		p.Pin_status,
+		p.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("Proposal is not a draft.")
+	}
+
+	return p.GetProposalById(db)
+}
+
+// Returns drafts whose scheduled publish time has passed, leaving out
+// those waiting to retry a failed publish.
+func GetScheduledDrafts(db *s.Database) ([]*Proposal, error) {
+	var proposals []*Proposal
+	err := pgxscan.Select(db.Context, db.Conn, &proposals,
+		`
+	SELECT * FROM proposals
+	WHERE status = 'draft' AND publish_at <= (now() at time zone 'utc')
+	AND (publish_retry_at IS NULL OR publish_retry_at <= (now() at time zone 'utc'))
+	ORDER BY publish_at ASC
+	`)
+
+	return proposals, err
+}
+
+// Defers the scheduled publish of the draft until retryAt without
+// counting an attempt, for drafts that cannot be published yet. The
+// reason, if any, is kept so the author can see why.
+func (p *Proposal) DeferScheduledPublish(db *s.Database, retryAt time.Time, reason error) error {
+	p.Publish_retry_at = &retryAt
+	p.Publish_error = nil
+	if reason != nil {
+		errMsg := reason.Error()
+		p.Publish_error = &errMsg
+	}
+
+	_, err := db.Conn.Exec(db.Context,
+		`
+	UPDATE proposals
+	SET publish_retry_at = $1, publish_error = $2
+	WHERE id = $3 AND status = 'draft'
+	`, p.Publish_retry_at, p.Publish_error, p.ID)
+	return err
+}
+
+// Records a failed scheduled publish of the draft. The publish is retried
+// after retryIn, or, when retryIn is zero, the draft is unscheduled until
+// it is edited. The error is kept so the author can see why.
+func (p *Proposal) FailScheduledPublish(db *s.Database, err error, retryIn time.Duration) error {
+	errMsg := err.Error()
+	p.Publish_attempts++
+	p.Publish_error = &errMsg
+	p.Publish_retry_at = nil
+	if retryIn > 0 {
+		retryAt := time.Now().UTC().Add(retryIn)
+		p.Publish_retry_at = &retryAt
+	} else {
+		p.Publish_at = nil
+	}
+
+	_, err = db.Conn.Exec(db.Context,
+		`
+	UPDATE proposals
+	SET publish_at = $1, publish_attempts = $2, publish_retry_at = $3, publish_error = $4
+	WHERE id = $5 AND status = 'draft'
+	`, p.Publish_at, p.Publish_attempts, p.Publish_retry_at, p.Publish_error, p.ID)
+	return err
+}
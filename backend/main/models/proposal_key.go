@@ -0,0 +1,217 @@
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+// The key that seals ballots on a private proposal. It is held by the
+// server until the proposal ends, then published so anyone can open the
+// ballots pinned to IPFS and recompute the tally.
+type ProposalKey struct {
+	Proposal_id int        `json:"proposalId"`
+	Key         string     `json:"key"`
+	Cid         *string    `json:"cid,omitempty"`
+	Pin_status  string     `json:"pinStatus"`
+	Created_at  *time.Time `json:"createdAt,omitempty"`
+	Revealed_at *time.Time `json:"revealedAt,omitempty"`
+}
+
+// The contents of a vote on a private proposal that are sealed until
+// the proposal ends.
+type SealedBallot struct {
+	Choice               string                  `json:"choice"`
+	Choices              *[]string               `json:"choices,omitempty"`
+	Allocations          *map[string]float64     `json:"allocations,omitempty"`
+	Message              string                  `json:"message"`
+	Voucher              *s.Voucher              `json:"voucher,omitempty"`
+	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures,omitempty"`
+}
+
+const BallotSealAlgorithm = "AES-256-GCM"
+
+func GetOrCreateProposalKey(db *s.Database, proposalId int) (ProposalKey, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return ProposalKey{}, err
+	}
+
+	_, err := db.Conn.Exec(db.Context,
+		`
+		INSERT INTO proposal_keys(proposal_id, key)
+		VALUES($1, $2)
+		ON CONFLICT (proposal_id) DO NOTHING
+		`, proposalId, hex.EncodeToString(key))
+	if err != nil {
+		return ProposalKey{}, err
+	}
+
+	k := ProposalKey{Proposal_id: proposalId}
+	err = k.GetProposalKey(db)
+	return k, err
+}
+
+func (k *ProposalKey) GetProposalKey(db *s.Database) error {
+	return pgxscan.Get(db.Context, db.Conn, k,
+		`SELECT * FROM proposal_keys WHERE proposal_id = $1`,
+		k.Proposal_id)
+}
+
+// Records that the key was published, along with the Cid of the
+// published key. A key is only revealed once, and false is returned if
+// it already was.
+func (k *ProposalKey) RevealProposalKey(db *s.Database) (bool, error) {
+	tag, err := db.Conn.Exec(db.Context,
+		`
+		UPDATE proposal_keys
+		SET cid = $1, pin_status = $2, revealed_at = (now() at time zone 'utc')
+		WHERE proposal_id = $3 AND revealed_at IS NULL
+		`, k.Cid, k.Pin_status, k.Proposal_id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, k.GetProposalKey(db)
+}
+
+// Seals the ballot contents of the vote with the proposal key, clearing
+// them from the vote. The signatures are sealed along with the message,
+// as the few possible messages could otherwise be checked against them.
+// The ballot is bound to the proposal and voter, so a sealed ballot
+// cannot be moved to another vote.
+func (v *Vote) SealBallot(key string) error {
+	gcm, err := ballotCipher(key)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(SealedBallot{
+		Choice:               v.Choice,
+		Choices:              v.Choices,
+		Allocations:          v.Allocations,
+		Message:              v.Message,
+		Voucher:              v.Voucher,
+		Composite_signatures: v.Composite_signatures,
+	})
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	sealed := base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, v.ballotData()))
+	v.Sealed_ballot = &sealed
+	v.Choice = ""
+	v.Choices = nil
+	v.Allocations = nil
+	v.Message = ""
+	v.Voucher = nil
+	v.Composite_signatures = nil
+
+	return nil
+}
+
+// Restores the ballot contents of a sealed vote.
+func (v *Vote) OpenBallot(key string) error {
+	if v.Sealed_ballot == nil {
+		return nil
+	}
+
+	gcm, err := ballotCipher(key)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(*v.Sealed_ballot)
+	if err != nil {
+		return err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return errors.New("sealed ballot is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, v.ballotData())
+	if err != nil {
+		return fmt.Errorf("unable to open ballot for address %s", v.Addr)
+	}
+
+	var ballot SealedBallot
+	if err := json.Unmarshal(plaintext, &ballot); err != nil {
+		return err
+	}
+
+	v.Choice = ballot.Choice
+	v.Choices = ballot.Choices
+	v.Allocations = ballot.Allocations
+	v.Message = ballot.Message
+	v.Voucher = ballot.Voucher
+	v.Composite_signatures = ballot.Composite_signatures
+
+	return nil
+}
+
+// Hides when a sealed ballot was cast, as it narrows down the timestamp
+// of the signed message.
+func (v *Vote) HideCastTime() {
+	if v.Sealed_ballot == nil {
+		return
+	}
+	v.Created_at = time.Time{}
+	v.Updated_at = nil
+}
+
+// Restores the ballot contents of a sealed vote in the vote history.
+func (h *VoteHistory) OpenBallot(key string) error {
+	v := Vote{Proposal_id: h.Proposal_id, Addr: h.Addr, Sealed_ballot: h.Sealed_ballot}
+	if err := v.OpenBallot(key); err != nil {
+		return err
+	}
+
+	h.Choice = v.Choice
+	h.Choices = v.Choices
+	h.Allocations = v.Allocations
+	h.Message = v.Message
+	h.Composite_signatures = v.Composite_signatures
+
+	return nil
+}
+
+// Hides when a sealed ballot in the vote history was cast and replaced.
+func (h *VoteHistory) HideCastTime() {
+	if h.Sealed_ballot == nil {
+		return
+	}
+	h.Cast_at = time.Time{}
+	h.Replaced_at = time.Time{}
+}
+
+func (v *Vote) ballotData() []byte {
+	return []byte(fmt.Sprintf("%d:%s", v.Proposal_id, v.Addr))
+}
+
+func ballotCipher(key string) (cipher.AEAD, error) {
+	k, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	s "github.com/DapperCollectives/CAST/backend/main/shared"
-	"github.com/georgysavva/scany/pgxscan"
 )
 
 type ProposalResults struct {
@@ -13,7 +12,30 @@ type ProposalResults struct {
 	Results_float 	   map[string]float64  `json:"resultsFloat" validate:"required"`
 	Updated_at        time.Time          `json:"updatedAt" validate:"required"`
 	Cid           	  *string            `json:"cid,omitempty"`
+	Pin_status        string             `json:"-"`
 	Achievements_done bool               `json:"achievementsDone"`
+	Rounds            []*RankedChoiceRound `json:"rounds,omitempty"`
+	Turnout           float64              `json:"turnout"`
+	Outcome           *string              `json:"outcome,omitempty"`
+	Winning_choice    *string              `json:"winningChoice,omitempty"`
+	// the tally of each strategy of a multi-strategy proposal
+	Strategy_results map[string]*ProposalResults `json:"strategyResults,omitempty"`
+}
+
+const (
+	Passed       = "passed"
+	Failed       = "failed"
+	QuorumNotMet = "quorum-not-met"
+)
+
+// A single instant-runoff round. Results are the strategy tally of each
+// ballot's highest ranked choice still in the running.
+type RankedChoiceRound struct {
+	Round         int                `json:"round"`
+	Results       map[string]int     `json:"results"`
+	Results_float map[string]float64 `json:"resultsFloat"`
+	Eliminated    []string           `json:"eliminated,omitempty"`
+	Exhausted     int                `json:"exhaustedBallots"`
 }
 
 func NewProposalResults(id int, choices []s.Choice) *ProposalResults {
@@ -30,12 +52,76 @@ func NewProposalResults(id int, choices []s.Choice) *ProposalResults {
 	return p
 }
 
+// Sets the outcome of the proposal from its tallied results. Turnout is
+// the total weight of the votes cast. A proposal passes when turnout
+// reaches quorum and its pass choice is the single leading choice,
+// holding at least the pass threshold percentage of that turnout.
+func (r *ProposalResults) ComputeOutcome(p *Proposal, turnout float64) {
+	r.Turnout = turnout
+
+	outcome := Failed
+	r.Outcome = &outcome
+
+	if p.Quorum != nil && (turnout == 0 || turnout < p.QuorumWeight()) {
+		outcome = QuorumNotMet
+		return
+	}
+
+	var leader string
+	var leading float64
+	tied := false
+	for _, c := range p.Choices {
+		weight := r.Results_float[c.Choice_text]
+		switch {
+		case weight > leading:
+			leader, leading, tied = c.Choice_text, weight, false
+		case weight == leading:
+			tied = true
+		}
+	}
+
+	if leader == "" || tied {
+		return
+	}
+	r.Winning_choice = &leader
+
+	if leader != p.PassChoice() {
+		return
+	}
+	if p.Pass_threshold != nil && leading/turnout*100 < *p.Pass_threshold {
+		return
+	}
+
+	outcome = Passed
+}
+
+// The cid of results whose pin was queued is set once they are pinned.
 func (r *ProposalResults) GetLatestProposalResultsById(db *s.Database) error {
-	return pgxscan.Get(db.Context, db.Conn, r,
+	return db.Conn.QueryRow(db.Context,
 		`
-		SELECT * FROM proposal_results
+		SELECT tally || jsonb_build_object('cid', cid) FROM proposal_results
 		WHERE proposal_id = $1
 		ORDER BY updated_at DESC
 		LIMIT 1
-		`, r.Proposal_id)
+		`, r.Proposal_id).Scan(r)
+}
+
+// Writes the final results of a closed proposal. Results are written
+// once; if another request already finalized the proposal, the
+// existing row is kept and false is returned.
+func (r *ProposalResults) CreateProposalResults(db *s.Database) (bool, error) {
+	if r.Pin_status == "" {
+		r.Pin_status = PinPinned
+	}
+
+	tag, err := db.Conn.Exec(db.Context,
+		`
+		INSERT INTO proposal_results(proposal_id, results, tally, cid, updated_at, pin_status)
+		VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (proposal_id) DO NOTHING
+		`, r.Proposal_id, r.Results, r, r.Cid, r.Updated_at, r.Pin_status)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+// Status of the transaction storing a proposal's final
+// results in the VotingCommunity contract.
+const (
+	ResultsTxSubmitted = "submitted"
+	ResultsTxSealed    = "sealed"
+	ResultsTxFailed    = "failed"
+)
+
+// Returns finalized proposals of communities linked to the
+// VotingCommunity contract whose results are not yet sealed on-chain.
+// Failed submissions are returned once their retry time has passed,
+// until they run out of attempts.
+func GetProposalsPendingResultsTx(db *s.Database, maxAttempts, limit int) ([]*Proposal, error) {
+	var proposals []*Proposal
+	sql := fmt.Sprintf(`
+		SELECT p.*, %s FROM proposals p
+		JOIN proposal_results r ON r.proposal_id = p.id
+		JOIN communities c ON c.id = p.community_id
+		WHERE c.onchain_id IS NOT NULL
+		AND (
+			p.results_tx_status IS NULL
+			OR p.results_tx_status = 'submitted'
+			OR (
+				p.results_tx_status = 'failed'
+				AND p.results_tx_attempts < $1
+				AND p.results_tx_retry_at <= (now() at time zone 'utc')
+			)
+		)
+		ORDER BY p.id ASC
+		LIMIT $2
+	`, computedStatusSQL)
+
+	err := pgxscan.Select(db.Context, db.Conn, &proposals, sql, maxAttempts, limit)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+func (p *Proposal) UpdateResultsTx(db *s.Database) error {
+	_, err := db.Conn.Exec(db.Context,
+		`
+		UPDATE proposals
+		SET results_tx_id = $1, results_tx_status = $2, results_tx_attempts = $3,
+		results_tx_error = $4, results_tx_retry_at = $5, results_tx_submitted_at = $6
+		WHERE id = $7
+		`, p.Results_tx_id, p.Results_tx_status, p.Results_tx_attempts,
+		p.Results_tx_error, p.Results_tx_retry_at, p.Results_tx_submitted_at, p.ID)
+	return err
+}
+
+// Marks the results transaction as failed and schedules a retry.
+func (p *Proposal) FailResultsTx(db *s.Database, err error, retryIn time.Duration) error {
+	status := ResultsTxFailed
+	errMsg := err.Error()
+	retryAt := time.Now().UTC().Add(retryIn)
+
+	p.Results_tx_status = &status
+	p.Results_tx_error = &errMsg
+	p.Results_tx_retry_at = &retryAt
+
+	return p.UpdateResultsTx(db)
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+// A version of a proposal's name, body and choices. The first revision
+// is the proposal as created, and each edit adds a revision with the cid
+// the edited proposal was pinned to.
+type ProposalRevision struct {
+	ID                   int                     `json:"id"`
+	Proposal_id          int                     `json:"proposalId"`
+	Revision             int                     `json:"revision"`
+	Name                 string                  `json:"name"`
+	Body                 *string                 `json:"body,omitempty"`
+	Choices              []s.Choice              `json:"choices"`
+	Cid                  *string                 `json:"cid"`
+	Editor_addr          string                  `json:"editorAddr"`
+	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures"`
+	Created_at           time.Time               `json:"createdAt"`
+}
+
+// The fields of a pending proposal that can be edited. Omitted fields
+// are left unchanged.
+type EditProposalPayload struct {
+	Name    *string     `json:"name,omitempty"`
+	Body    *string     `json:"body,omitempty"`
+	Choices *[]s.Choice `json:"choices,omitempty"`
+
+	s.TimestampSignaturePayload
+}
+
+var ErrProposalNotEditable = errors.New("Proposals can only be edited before voting starts and while they have no votes.")
+
+const proposalEditableSQL = `
+	SELECT status = 'published'
+		AND start_time > (now() at time zone 'utc')
+		AND NOT EXISTS (SELECT 1 FROM votes WHERE proposal_id = $1)
+	FROM proposals
+	WHERE id = $1
+	`
+
+// Returns ErrProposalNotEditable if voting on the proposal has started
+// or a vote was cast.
+func EnsureProposalEditable(db *s.Database, proposalId int) error {
+	var editable bool
+	if err := db.Conn.QueryRow(db.Context, proposalEditableSQL, proposalId).Scan(&editable); err != nil {
+		return err
+	}
+	if !editable {
+		return ErrProposalNotEditable
+	}
+	return nil
+}
+
+func GetProposalRevisions(db *s.Database, proposalId int) ([]*ProposalRevision, error) {
+	revisions := []*ProposalRevision{}
+	err := pgxscan.Select(db.Context, db.Conn, &revisions,
+		`
+		SELECT * FROM proposal_revisions
+		WHERE proposal_id = $1
+		ORDER BY revision DESC
+		`, proposalId)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Applies the edited fields of the payload to the proposal.
+func (p *Proposal) ApplyEdit(e EditProposalPayload) {
+	if e.Name != nil {
+		p.Name = *e.Name
+	}
+	if e.Body != nil {
+		p.Body = e.Body
+	}
+	if e.Choices != nil {
+		p.Choices = *e.Choices
+	}
+}
+
+// Stores the edited name, body, choices, cid and pin status of the
+// proposal and records them as a new revision. The proposal as created
+// is recorded as the first revision on its first edit. Returns
+// ErrProposalNotEditable if voting has started or a vote was cast.
+func (p *Proposal) ReviseProposal(
+	db *s.Database,
+	editorAddr string,
+	compositeSignatures *[]s.CompositeSignature,
+) (ProposalRevision, error) {
+	tx, err := db.Conn.Begin(db.Context)
+	if err != nil {
+		return ProposalRevision{}, err
+	}
+	defer tx.Rollback(db.Context)
+
+	var editable bool
+	err = tx.QueryRow(db.Context, proposalEditableSQL+"FOR UPDATE", p.ID).Scan(&editable)
+	if err != nil {
+		return ProposalRevision{}, err
+	}
+	if !editable {
+		return ProposalRevision{}, ErrProposalNotEditable
+	}
+
+	_, err = tx.Exec(db.Context,
+		`
+		INSERT INTO proposal_revisions(proposal_id, revision, name, body, choices, cid,
+			editor_addr, composite_signatures, created_at)
+		SELECT id, 1, name, body, choices, cid, creator_addr, composite_signatures, created_at
+		FROM proposals
+		WHERE id = $1
+		ON CONFLICT (proposal_id, revision) DO NOTHING
+		`, p.ID)
+	if err != nil {
+		return ProposalRevision{}, err
+	}
+
+	r := ProposalRevision{
+		Proposal_id:          p.ID,
+		Name:                 p.Name,
+		Body:                 p.Body,
+		Choices:              p.Choices,
+		Cid:                  p.Cid,
+		Editor_addr:          editorAddr,
+		Composite_signatures: compositeSignatures,
+	}
+	err = tx.QueryRow(db.Context,
+		`
+		INSERT INTO proposal_revisions(proposal_id, revision, name, body, choices, cid,
+			editor_addr, composite_signatures)
+		SELECT $1, MAX(revision) + 1, $2, $3, $4, $5, $6, $7
+		FROM proposal_revisions
+		WHERE proposal_id = $1
+		RETURNING id, revision, created_at
+		`, r.Proposal_id, r.Name, r.Body, r.Choices, r.Cid, r.Editor_addr,
+		r.Composite_signatures).Scan(&r.ID, &r.Revision, &r.Created_at)
+	if err != nil {
+		return ProposalRevision{}, err
+	}
+
+	_, err = tx.Exec(db.Context,
+		`
+		UPDATE proposals
+		SET name = $2, body = $3, choices = $4, cid = $5, pin_status = $6
+		WHERE id = $1
+		`, p.ID, p.Name, p.Body, p.Choices, p.Cid, p.Pin_status)
+	if err != nil {
+		return ProposalRevision{}, err
+	}
+
+	if err := tx.Commit(db.Context); err != nil {
+		return ProposalRevision{}, err
+	}
+
+	return r, p.GetProposalById(db)
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// A strategy a multi-strategy proposal is tallied with. Weight scales
+// the strategy's share of the combined results and defaults to 1.
+type ProposalStrategy struct {
+	Name   string   `json:"name"`
+	Weight *float64 `json:"weight,omitempty"`
+}
+
+// How the tallies of a multi-strategy proposal are combined.
+//
+// Both rules normalize each strategy's tally to the share of its own
+// turnout each choice received, and report the weighted sum of those
+// shares as a percentage. With sum-normalized the proposal's outcome is
+// decided on that sum. With sub-tallies each strategy decides its own
+// outcome, and the proposal passes only if every strategy passes with
+// the same winning choice.
+const (
+	CombineSumNormalized = "sum-normalized"
+	CombineSubTallies    = "sub-tallies"
+)
+
+func (p *Proposal) IsMultiStrategy() bool {
+	return p.Strategies != nil && len(*p.Strategies) > 1
+}
+
+// Returns the names of the strategies the proposal is tallied with.
+func (p *Proposal) StrategyNames() []string {
+	if !p.IsMultiStrategy() {
+		if p.Strategy == nil {
+			return []string{}
+		}
+		return []string{*p.Strategy}
+	}
+
+	names := make([]string, len(*p.Strategies))
+	for i, ps := range *p.Strategies {
+		names[i] = ps.Name
+	}
+	return names
+}
+
+// Returns a copy of a multi-strategy proposal as it is tallied by a
+// single one of its strategies.
+func (p *Proposal) ForStrategy(name string) Proposal {
+	sp := *p
+	sp.Strategy = &name
+	sp.Strategies = nil
+	sp.Strategy_combination = nil
+	sp.Max_weight = nil
+	sp.Min_balance = nil
+	sp.Quorum = nil
+	sp.Quorum_type = nil
+	sp.Total_supply = nil
+	return sp
+}
+
+// Validates the strategies of a multi-strategy proposal and sets its
+// primary strategy, used to take the snapshot, to the strategy that
+// stores balances, if any.
+//
+// Balances and NFTs are stored per proposal rather than per strategy, so
+// a proposal can combine at most one strategy of each kind. Max weight,
+// min balance and quorum are in the units of a single strategy, so they
+// are not supported across strategies.
+func (p *Proposal) ValidateStrategies() error {
+	if p.Strategies == nil || len(*p.Strategies) == 0 {
+		p.Strategies = nil
+		return nil
+	}
+
+	if len(*p.Strategies) == 1 {
+		name := (*p.Strategies)[0].Name
+		p.Strategy = &name
+		p.Strategies = nil
+		p.Strategy_combination = nil
+		return nil
+	}
+
+	if p.Strategy_combination == nil {
+		combination := CombineSumNormalized
+		p.Strategy_combination = &combination
+	}
+	if *p.Strategy_combination != CombineSumNormalized && *p.Strategy_combination != CombineSubTallies {
+		return fmt.Errorf("invalid strategy combination: %s", *p.Strategy_combination)
+	}
+
+	if p.Voting_type != nil && *p.Voting_type != SingleChoice {
+		return errors.New("multi-strategy proposals only support single-choice voting")
+	}
+	if p.Max_weight != nil || p.Min_balance != nil || p.Quorum != nil {
+		return errors.New("multi-strategy proposals do not support max weight, min balance or quorum")
+	}
+
+	primary := (*p.Strategies)[0].Name
+	seen := map[string]bool{}
+	var balances, nfts int
+	for _, ps := range *p.Strategies {
+		if seen[ps.Name] {
+			return fmt.Errorf("duplicate strategy: %s", ps.Name)
+		}
+		seen[ps.Name] = true
+
+		if _, ok := GetStrategyDescriptor(ps.Name); !ok {
+			return fmt.Errorf("strategy not found: %s", ps.Name)
+		}
+
+		if ps.Weight != nil && *ps.Weight <= 0 {
+			return fmt.Errorf("strategy weight must be positive: %s", ps.Name)
+		}
+
+		if RequiresSnapshot(ps.Name) {
+			balances++
+			primary = ps.Name
+		}
+		if IsNFTStrategy(ps.Name) {
+			nfts++
+		}
+	}
+	if balances > 1 || nfts > 1 {
+		return errors.New("multi-strategy proposals can combine at most one token strategy and one NFT strategy")
+	}
+
+	p.Strategy = &primary
+	return nil
+}
+
+// Combines the tallies of each strategy of a multi-strategy proposal
+// into its results, keeping each tally as a per-strategy breakdown.
+func CombineStrategyResults(p *Proposal, tallies map[string]*ProposalResults) ProposalResults {
+	r := NewProposalResults(p.ID, p.Choices)
+	r.Strategy_results = tallies
+
+	var totalWeight float64
+	for _, ps := range *p.Strategies {
+		weight := 1.0
+		if ps.Weight != nil {
+			weight = *ps.Weight
+		}
+		totalWeight += weight
+
+		tally := tallies[ps.Name]
+		if tally == nil || tally.Turnout == 0 {
+			continue
+		}
+		for choice, amount := range tally.Results_float {
+			r.Results_float[choice] += weight * amount / tally.Turnout
+		}
+	}
+
+	var turnout float64
+	for choice, share := range r.Results_float {
+		percentage := share / totalWeight * 100
+		r.Results_float[choice] = percentage
+		r.Results[choice] = int(math.Round(percentage))
+		turnout += percentage
+	}
+
+	r.ComputeOutcome(p, turnout)
+	if *p.Strategy_combination == CombineSubTallies {
+		r.combineSubTallyOutcomes(tallies)
+	}
+
+	return *r
+}
+
+// The proposal passes only if every strategy passed with the same
+// winning choice.
+func (r *ProposalResults) combineSubTallyOutcomes(tallies map[string]*ProposalResults) {
+	outcome := Passed
+	var winner *string
+	for _, tally := range tallies {
+		if tally.Outcome == nil || *tally.Outcome != Passed || tally.Winning_choice == nil {
+			outcome = Failed
+			break
+		}
+		if winner != nil && *winner != *tally.Winning_choice {
+			outcome = Failed
+			break
+		}
+		winner = tally.Winning_choice
+	}
+
+	r.Outcome = &outcome
+	if outcome == Passed {
+		r.Winning_choice = winner
+	}
+}
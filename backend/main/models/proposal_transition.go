@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+type ProposalTransition struct {
+	ID          int       `json:"id"`
+	Proposal_id int       `json:"proposalId"`
+	From_status *string   `json:"fromStatus,omitempty"`
+	To_status   string    `json:"toStatus"`
+	Created_at  time.Time `json:"createdAt"`
+}
+
+const transitionBatchSize = 100
+
+// Returns proposals whose computed status has changed since
+// the scheduler last processed them. Proposals whose transition failed
+// are returned once their retry time has passed, after those not yet
+// attempted, so they do not hold up the others.
+func GetProposalsPendingTransition(db *s.Database) ([]*Proposal, error) {
+	var proposals []*Proposal
+	sql := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT *, %s FROM proposals
+		) p
+		WHERE p.computed_status IS DISTINCT FROM p.processed_status
+		AND (p.transition_retry_at IS NULL OR p.transition_retry_at <= (now() at time zone 'utc'))
+		ORDER BY p.transition_retry_at ASC NULLS FIRST, p.id ASC
+		LIMIT $1
+	`, computedStatusSQL)
+
+	err := pgxscan.Select(db.Context, db.Conn, &proposals, sql, transitionBatchSize)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+// Returns open proposals whose snapshot is still being processed.
+func GetProposalsWithProcessingSnapshot(db *s.Database) ([]*Proposal, error) {
+	var proposals []*Proposal
+	sql := fmt.Sprintf(`
+		SELECT *, %s FROM proposals
+		WHERE snapshot_status = 'processing'
+		AND status = 'published'
+		AND end_time > (now() at time zone 'utc')
+		ORDER BY id ASC
+		LIMIT $1
+	`, computedStatusSQL)
+
+	err := pgxscan.Select(db.Context, db.Conn, &proposals, sql, transitionBatchSize)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+// Records the proposal's move to its computed status. Returns false if
+// the transition was already recorded by another worker.
+func (p *Proposal) RecordTransition(db *s.Database) (bool, error) {
+	tx, err := db.Conn.Begin(db.Context)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(db.Context)
+
+	tag, err := tx.Exec(db.Context,
+		`
+		UPDATE proposals
+		SET processed_status = $1, transition_attempts = 0, transition_retry_at = NULL
+		WHERE id = $2 AND processed_status IS NOT DISTINCT FROM $3
+		`, p.Computed_status, p.ID, p.Processed_status)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(db.Context,
+		`
+		INSERT INTO proposal_transitions(proposal_id, from_status, to_status)
+		VALUES($1, $2, $3)
+		`, p.ID, p.Processed_status, p.Computed_status); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(db.Context); err != nil {
+		return false, err
+	}
+
+	p.Processed_status = p.Computed_status
+	p.Transition_attempts = 0
+	p.Transition_retry_at = nil
+	return true, nil
+}
+
+// Records a failed attempt at the proposal's transition and schedules
+// a retry.
+func (p *Proposal) FailTransition(db *s.Database, retryIn time.Duration) error {
+	retryAt := time.Now().UTC().Add(retryIn)
+	p.Transition_attempts++
+	p.Transition_retry_at = &retryAt
+
+	_, err := db.Conn.Exec(db.Context,
+		`
+		UPDATE proposals
+		SET transition_attempts = $1, transition_retry_at = $2
+		WHERE id = $3
+		`, p.Transition_attempts, p.Transition_retry_at, p.ID)
+	return err
+}
+
+func GetProposalTransitions(db *s.Database, proposalId int) ([]*ProposalTransition, error) {
+	var transitions []*ProposalTransition
+	err := pgxscan.Select(db.Context, db.Conn, &transitions,
+		`
+		SELECT * FROM proposal_transitions
+		WHERE proposal_id = $1
+		ORDER BY created_at ASC
+		`, proposalId)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return transitions, nil
+}
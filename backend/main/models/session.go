@@ -0,0 +1,137 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+// A session an account signed in to with an FCL account proof. Requests
+// made with the session's token are authenticated as the account, in
+// place of a signed timestamp. Only a hash of the token is stored.
+type Session struct {
+	ID         int        `json:"id"`
+	Addr       string     `json:"address"`
+	Token      string     `json:"token,omitempty" db:"-"`
+	Token_hash string     `json:"-"`
+	Expires_at time.Time  `json:"expiresAt"`
+	Revoked_at *time.Time `json:"revokedAt,omitempty"`
+	Created_at *time.Time `json:"createdAt,omitempty"`
+}
+
+// The proof an account signed to sign in, as FCL's account proof
+// service data.
+type LoginPayload struct {
+	Addr       string                  `json:"address" validate:"required"`
+	Nonce      string                  `json:"nonce" validate:"required"`
+	Signatures *[]s.CompositeSignature `json:"signatures" validate:"required"`
+}
+
+type AuthNonce struct {
+	Nonce      string    `json:"nonce"`
+	Expires_at time.Time `json:"expiresAt"`
+}
+
+var ErrInvalidSession = errors.New("Invalid or expired session.")
+var ErrInvalidNonce = errors.New("Invalid or expired nonce.")
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (n *AuthNonce) CreateAuthNonce(db *s.Database) error {
+	_, err := db.Conn.Exec(db.Context,
+		`INSERT INTO auth_nonces(nonce, expires_at) VALUES($1, $2)`,
+		n.Nonce, n.Expires_at)
+	return err
+}
+
+// Nonces are single use, so an unexpired nonce is deleted as it is
+// consumed.
+func ConsumeAuthNonce(db *s.Database, nonce string) error {
+	tag, err := db.Conn.Exec(db.Context,
+		`
+		DELETE FROM auth_nonces
+		WHERE nonce = $1 AND expires_at > (now() at time zone 'utc')
+		`, nonce)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvalidNonce
+	}
+	return nil
+}
+
+func (sn *Session) CreateSession(db *s.Database) error {
+	sn.Token_hash = hashSessionToken(sn.Token)
+	return db.Conn.QueryRow(db.Context,
+		`
+		INSERT INTO sessions(addr, token_hash, expires_at)
+		VALUES($1, $2, $3)
+		RETURNING id, created_at
+		`, sn.Addr, sn.Token_hash, sn.Expires_at,
+	).Scan(&sn.ID, &sn.Created_at)
+}
+
+// Returns the session for the token, unless it has expired or been
+// revoked.
+func GetActiveSession(db *s.Database, token string) (*Session, error) {
+	var sn Session
+	err := pgxscan.Get(db.Context, db.Conn, &sn,
+		`
+		SELECT * FROM sessions
+		WHERE token_hash = $1 AND revoked_at IS NULL
+			AND expires_at > (now() at time zone 'utc')
+		`, hashSessionToken(token))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return nil, ErrInvalidSession
+		}
+		return nil, err
+	}
+	return &sn, nil
+}
+
+func (sn *Session) RevokeSession(db *s.Database) error {
+	return db.Conn.QueryRow(db.Context,
+		`
+		UPDATE sessions SET revoked_at = (now() at time zone 'utc')
+		WHERE id = $1
+		RETURNING revoked_at
+		`, sn.ID).Scan(&sn.Revoked_at)
+}
+
+// Signs the account out of all its sessions.
+func RevokeSessionsForAddr(db *s.Database, addr string) (int64, error) {
+	tag, err := db.Conn.Exec(db.Context,
+		`
+		UPDATE sessions SET revoked_at = (now() at time zone 'utc')
+		WHERE addr = $1 AND revoked_at IS NULL
+			AND expires_at > (now() at time zone 'utc')
+		`, addr)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Deletes expired nonces and sessions, which can no longer be used.
+func DeleteExpiredSessions(db *s.Database) error {
+	if _, err := db.Conn.Exec(db.Context,
+		`DELETE FROM auth_nonces WHERE expires_at <= (now() at time zone 'utc')`,
+	); err != nil {
+		return err
+	}
+
+	_, err := db.Conn.Exec(db.Context,
+		`DELETE FROM sessions WHERE expires_at <= (now() at time zone 'utc')`,
+	)
+	return err
+}
@@ -1,38 +1,155 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+
 	s "github.com/DapperCollectives/CAST/backend/main/shared"
-	"github.com/georgysavva/scany/pgxscan"
 )
 
+// Describes a voting strategy. Each strategy describes itself when it
+// registers with the strategies package, which records the description
+// here so models can look strategies up without importing them.
+type StrategyDescriptor struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// json keys of the contract fields a community must set to use it
+	Contract_fields []string `json:"contractFields"`
+	// fetches balances from the snapshot at the proposal's block height
+	// and stores them in the balances table
+	Requires_snapshot bool `json:"requiresSnapshot"`
+	// stores the NFTs a voter holds in the nfts table
+	Tracks_nfts bool `json:"tracksNfts"`
+	// counts balances delegated to a voter
+	Delegable bool `json:"delegable"`
+	// supports weighted voting, splitting a vote's weight across choices
+	Split_voting bool `json:"splitVoting"`
+	// weighs a vote one to one by the voter's token balance, so turnout
+	// can be measured against the token supply
+	Token_weighted bool `json:"tokenWeighted"`
+	// chains its contract can be on, Flow if empty
+	Chains []string `json:"chains,omitempty"`
+	// kept for existing communities but not offered for new ones
+	Hidden bool `json:"-"`
+}
+
 type VotingStrategy struct {
-	Key         string           `json:"key" validate:"required"`
-	Name        string           `json:"name" validate:"required"`
-	Description string 		     `json:"description,omitempty"`
-	Scripts 	[]s.CustomScript `json:"scripts,omitempty"`
+	StrategyDescriptor
+	Scripts []s.CustomScript `json:"scripts,omitempty"`
 }
 
-// type CustomScript struct {
-// 	Key 		string `json:"key" validate:"required"`
-// 	Name 		string `json:"name" validate:"required"`
-// 	Description string `json:"description" validate:"required"`
-// 	Src 		string `json:"src" validate:"required"`
-// }
+var strategyDescriptors = map[string]StrategyDescriptor{}
+
+func RegisterStrategyDescriptor(d StrategyDescriptor) {
+	strategyDescriptors[d.Key] = d
+}
 
-func GetVotingStrategies(db *s.Database) ([]*VotingStrategy, error) {
-	var votingStrategies []*VotingStrategy
-	err := pgxscan.Select(db.Context, db.Conn, &votingStrategies,
-		`
-		SELECT * FROM voting_strategies
-		`)
+func GetStrategyDescriptor(key string) (StrategyDescriptor, bool) {
+	d, ok := strategyDescriptors[key]
+	return d, ok
+}
 
-	if err != nil {
-		return nil, err
+func (d StrategyDescriptor) SupportsChain(chain string) bool {
+	if len(d.Chains) == 0 {
+		return chain == s.FlowChainKey
 	}
+	for _, c := range d.Chains {
+		if c == chain {
+			return true
+		}
+	}
+	return false
+}
 
-	return votingStrategies, nil
+// Returns the strategies offered to communities, ordered by key.
+func GetVotingStrategies() []*VotingStrategy {
+	votingStrategies := []*VotingStrategy{}
+	for _, d := range strategyDescriptors {
+		if d.Hidden {
+			continue
+		}
+		votingStrategies = append(votingStrategies, &VotingStrategy{StrategyDescriptor: d})
+	}
+
+	sort.Slice(votingStrategies, func(i, j int) bool {
+		return votingStrategies[i].Key < votingStrategies[j].Key
+	})
+	return votingStrategies
+}
+
+// Returns an error if the strategy is not registered or the contract is
+// missing a field the strategy requires.
+func (st *Strategy) Validate() error {
+	if st.Name == nil {
+		return errors.New("strategy name is required")
+	}
+
+	d, ok := GetStrategyDescriptor(*st.Name)
+	if !ok {
+		return fmt.Errorf("strategy not found: %s", *st.Name)
+	}
+
+	chain := st.Contract.ChainKey()
+	if !d.SupportsChain(chain) {
+		return fmt.Errorf("strategy %s does not support chain %s", d.Key, chain)
+	}
+	if chain == s.EVMChainKey && (st.Contract.Chain_id == nil || st.Contract.Addr == nil) {
+		return fmt.Errorf("strategy %s requires contract fields chainId and addr on evm", d.Key)
+	}
+
+	for _, field := range d.Contract_fields {
+		// EVM contracts have no public paths
+		if field == "publicPath" && chain == s.EVMChainKey {
+			continue
+		}
+		var set bool
+		switch field {
+		case "name":
+			set = st.Contract.Name != nil
+		case "addr":
+			set = st.Contract.Addr != nil
+		case "publicPath":
+			set = st.Contract.Public_path != nil
+		case "floatEventId":
+			set = st.Contract.Float_event_id != nil
+		case "script":
+			set = st.Contract.Script != nil
+		}
+		if !set {
+			return fmt.Errorf("strategy %s requires contract field %s", d.Key, field)
+		}
+	}
+
+	return nil
 }
 
 func IsNFTStrategy(name string) bool {
-	return name == "balance-of-nfts" || name == "float-nfts"
+	d, ok := GetStrategyDescriptor(name)
+	return ok && d.Tracks_nfts
+}
+
+// Strategies that count balances delegated to a voter.
+func IsDelegableStrategy(name string) bool {
+	d, ok := GetStrategyDescriptor(name)
+	return ok && d.Delegable
+}
+
+// Strategies whose weight can be split by percentage across choices.
+func IsSplitVotingStrategy(name string) bool {
+	d, ok := GetStrategyDescriptor(name)
+	return ok && d.Split_voting
+}
+
+// Strategies whose turnout a percentage quorum can be measured by.
+func IsTokenWeightedStrategy(name string) bool {
+	d, ok := GetStrategyDescriptor(name)
+	return ok && d.Token_weighted
+}
+
+// Strategies that store snapshot balances in the balances table.
+func RequiresSnapshot(name string) bool {
+	d, ok := GetStrategyDescriptor(name)
+	return ok && d.Requires_snapshot
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -29,20 +30,28 @@ type Vote struct {
 	IsCancelled          bool                    `json:"isCancelled"`
 	IsEarly		 	 	 bool					 `json:"isEarly"`
 	IsWinning		 	 bool					 `json:"isWinning"`
+	Choices              *[]string               `json:"choices,omitempty"`
+	Allocations          *map[string]float64     `json:"allocations,omitempty"`
+	Updated_at           *time.Time              `json:"updatedAt,omitempty"`
+	Sealed_ballot        *string                 `json:"sealedBallot,omitempty"`
+	Pin_status           string                  `json:"pinStatus,omitempty"`
 }
 
 type VoteWithBalance struct {
 	// Extend Vote
 	Vote
 	// Balance
-	BlockHeight             *uint64  `json:"blockHeight" pg:"block_height"`
-	Balance                 *uint64  `json:"balance"`
-	PrimaryAccountBalance   *uint64  `json:"primaryAccountBalance"`
-	SecondaryAccountBalance *uint64  `json:"secondaryAccountBalance"`
-	StakingBalance          *uint64  `json:"stakingBalance"`
-	Weight                  *float64 `json:"weight"`
-
-	NFTs []*NFT
+	BlockHeight             *uint64             `json:"blockHeight" pg:"block_height"`
+	Balance                 *uint64             `json:"balance"`
+	PrimaryAccountBalance   *uint64             `json:"primaryAccountBalance"`
+	SecondaryAccountBalance *uint64             `json:"secondaryAccountBalance"`
+	StakingBalance          *uint64             `json:"stakingBalance"`
+	RawBalance              *float64            `json:"rawBalance,omitempty"`
+	Weight                  *float64            `json:"weight"`
+	ChoiceWeights           *map[string]float64 `json:"choiceWeights,omitempty"`
+
+	NFTs        []*NFT
+	Delegations []*DelegatedBalance `json:"delegations,omitempty"`
 }
 
 type NFT struct {
@@ -61,6 +70,7 @@ type VotingStreak struct {
 const (
 	timestampExpiry     = 60
 	defaultStreakLength = 3
+	allocationTolerance = 0.0001
 )
 
 const (
@@ -82,12 +92,11 @@ func GetVotesForAddress(
 	var votes []*VoteWithBalance
 	var err error
 
-	sql := `select v.*, 
-		b.primary_account_balance,
-		b.secondary_account_balance,
-		b.staking_balance
+	// proposals weigh votes with different tokens, so balances are read
+	// for each vote
+	sql := `select v.*, p.block_height
 		from votes v
-		left join balances b on b.addr = v.addr
+		join proposals p on p.id = v.proposal_id
 		WHERE v.addr = $3`
 
 	// Conditionally add proposal_id condition
@@ -108,6 +117,16 @@ func GetVotesForAddress(
 		return []*VoteWithBalance{}, 0, nil
 	}
 
+	for _, vb := range votes {
+		tokenId, err := proposalBalanceTokenId(db, vb.Proposal_id)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := vb.getBalance(db, tokenId); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	// Get total number of votes on proposal
 	var totalRecords int
 	countSql := `
@@ -120,6 +139,11 @@ func GetVotesForAddress(
 func GetAllVotesForProposal(db *s.Database, proposalId int, strategy string) ([]*VoteWithBalance, error) {
 	var votes []*VoteWithBalance
 
+	tokenId, err := BalanceTokenId(db, proposalId, strategy)
+	if err != nil {
+		return nil, err
+	}
+
 	//return all balances, strategy will do rest of the work
 	sql := `select v.*, 
 		b.primary_account_balance,
@@ -130,15 +154,20 @@ func GetAllVotesForProposal(db *s.Database, proposalId int, strategy string) ([]
     join proposals p on p.id = $1
   	left join balances b on b.addr = v.addr 
 		and p.block_height = b.block_height
+		and b.fungible_token_id = $2
     where proposal_id = $1
 `
-	err := pgxscan.Select(db.Context, db.Conn, &votes, sql, proposalId)
+	err = pgxscan.Select(db.Context, db.Conn, &votes, sql, proposalId, tokenId)
 	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
 		return nil, err
 	} else if err != nil && err.Error() == pgx.ErrNoRows.Error() {
 		return []*VoteWithBalance{}, nil
 	}
 
+	if err := attachDelegations(db, proposalId, strategy, votes); err != nil {
+		return nil, err
+	}
+
 	if IsNFTStrategy(strategy) {
 		votesWithNFTs, err := getUsersNFTs(db, votes)
 		if err != nil {
@@ -150,6 +179,54 @@ func GetAllVotesForProposal(db *s.Database, proposalId int, strategy string) ([]
 	return votes, nil
 }
 
+// Returns up to limit votes on the proposal with an id after afterId,
+// ordered by id, so all votes can be read in batches.
+func GetVotesForProposalAfter(
+	db *s.Database,
+	proposalId int,
+	strategy string,
+	afterId int,
+	limit int,
+) ([]*VoteWithBalance, error) {
+	var votes []*VoteWithBalance
+
+	tokenId, err := BalanceTokenId(db, proposalId, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `select v.*,
+		b.primary_account_balance,
+		b.secondary_account_balance,
+		b.staking_balance,
+		COALESCE(p.block_height, 0) as block_height
+	from votes v
+	join proposals p on p.id = $1
+	left join balances b on b.addr = v.addr
+		and p.block_height = b.block_height
+		and b.fungible_token_id = $4
+	where proposal_id = $1 and v.id > $2
+	order by v.id asc
+	limit $3
+`
+	err = pgxscan.Select(db.Context, db.Conn, &votes, sql, proposalId, afterId, limit, tokenId)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	} else if err != nil && err.Error() == pgx.ErrNoRows.Error() {
+		return []*VoteWithBalance{}, nil
+	}
+
+	if err := attachDelegations(db, proposalId, strategy, votes); err != nil {
+		return nil, err
+	}
+
+	if IsNFTStrategy(strategy) {
+		return getUsersNFTs(db, votes)
+	}
+
+	return votes, nil
+}
+
 func GetVotesForProposal(
 	db *s.Database,
 	proposalId int,
@@ -165,6 +242,11 @@ func GetVotesForProposal(
 		orderBySql = "ORDER BY b.created_at ASC"
 	}
 
+	tokenId, err := BalanceTokenId(db, proposalId, strategy)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	//return all balances, strategy will do rest of the work
 	sql := `select v.*, p.block_height, 
 		b.primary_account_balance,
@@ -174,12 +256,13 @@ func GetVotesForProposal(
     join proposals p on p.id = v.proposal_id
   	left join balances b on b.addr = v.addr 
 		and p.block_height = b.block_height
+		and b.fungible_token_id = $4
     where v.proposal_id = $3`
 
 	sql = sql + " " + orderBySql
 	sql = sql + " LIMIT $1 OFFSET $2"
 
-	err := pgxscan.Select(
+	err = pgxscan.Select(
 		db.Context,
 		db.Conn,
 		&votes,
@@ -187,6 +270,7 @@ func GetVotesForProposal(
 		pageParams.Count,
 		pageParams.Start,
 		proposalId,
+		tokenId,
 	)
 
 	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
@@ -196,6 +280,11 @@ func GetVotesForProposal(
 		return []*VoteWithBalance{}, 0, nil
 	}
 
+	if err := attachDelegations(db, proposalId, strategy, votes); err != nil {
+		log.Error().Err(err).Msg("Error getting delegations for votes")
+		return nil, 0, err
+	}
+
 	if IsNFTStrategy(strategy) {
 		votes, err = getUsersNFTs(db, votes)
 		if err != nil {
@@ -219,15 +308,23 @@ func (v *Vote) GetVote(db *s.Database) error {
 }
 
 func (vb *VoteWithBalance) GetVote(db *s.Database) error {
-	err := pgxscan.Get(db.Context, db.Conn, vb,
-		`select v.*, 
+	tokenId, err := proposalBalanceTokenId(db, vb.Proposal_id)
+	if err != nil {
+		return err
+	}
+
+	err = pgxscan.Get(db.Context, db.Conn, vb,
+		`select v.*, p.block_height,
 		b.primary_account_balance,
 		b.secondary_account_balance,
 		b.staking_balance
 		from votes v
+		join proposals p on p.id = v.proposal_id
 		left join balances b on b.addr = v.addr
+			and p.block_height = b.block_height
+			and b.fungible_token_id = $3
 		WHERE proposal_id = $1 AND v.addr = $2`,
-		vb.Proposal_id, vb.Addr)
+		vb.Proposal_id, vb.Addr, tokenId)
 
 	if err != nil {
 		return err
@@ -238,6 +335,27 @@ func (vb *VoteWithBalance) GetVote(db *s.Database) error {
 	return err
 }
 
+// Sets the vote's balance of the token at its block height, leaving it
+// unset when no balance is stored.
+func (vb *VoteWithBalance) getBalance(db *s.Database, tokenId string) error {
+	if vb.BlockHeight == nil {
+		return nil
+	}
+
+	err := db.Conn.QueryRow(db.Context,
+		`
+		SELECT primary_account_balance, secondary_account_balance, staking_balance
+		FROM balances
+		WHERE addr = $1 AND block_height = $2 AND fungible_token_id = $3
+		LIMIT 1
+		`, vb.Addr, *vb.BlockHeight, tokenId,
+	).Scan(&vb.PrimaryAccountBalance, &vb.SecondaryAccountBalance, &vb.StakingBalance)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return err
+	}
+	return nil
+}
+
 func (v *Vote) GetVoteById(db *s.Database) error {
 	return pgxscan.Get(db.Context, db.Conn, v,
 		`SELECT * from votes
@@ -275,24 +393,21 @@ func ValidateVoteMessage(message string, proposal Proposal) error {
 	vars := strings.Split(message, ":")
 
 	// check proposal choices to see if choice is valid
-	encodedChoice := vars[1]
-	choiceBytes, err := hex.DecodeString(encodedChoice)
-
+	choices, err := DecodeVoteMessageChoices(message)
 	if err != nil {
-		return errors.New("couldnt decode choice in message from hex string")
+		return err
 	}
 
-	validChoice := false
-	for _, choice := range proposal.Choices {
-		if choice.Choice_text == string(choiceBytes) {
-			validChoice = true
-			break
-		}
-	}
-	if !validChoice {
+	if !proposal.AcceptsMultipleChoices() && !proposal.IsWeighted() && len(choices) != 1 {
 		return errors.New("invalid choice for proposal")
 	}
 
+	for _, c := range choices {
+		if !proposal.HasChoice(c) {
+			return errors.New("invalid choice for proposal")
+		}
+	}
+
 	// check timestamp and ensure no longer than 60 seconds has passed
 	timestamp, _ := strconv.ParseInt(vars[2], 10, 64)
 	uxTime := time.Unix(timestamp/1000, (timestamp%1000)*1000*1000)
@@ -304,20 +419,207 @@ func ValidateVoteMessage(message string, proposal Proposal) error {
 	return nil
 }
 
-func (v *Vote) ValidateChoice(proposal Proposal) error {
-	validChoice := false
-	for _, choice := range proposal.Choices {
-		if choice.Choice_text == v.Choice {
-			validChoice = true
-			break
+// Decodes the choices from a <proposalId>:<choice>:<timestamp> message.
+// Ranked choice and approval ballots encode each choice as hex, comma
+// separated. Ranked choices are listed in order of preference.
+// Weighted ballots encode each choice as <choice>=<percentage>.
+func DecodeVoteMessageChoices(message string) ([]string, error) {
+	vars := strings.Split(message, ":")
+	if len(vars) != 3 {
+		return nil, errors.New("invalid vote message format")
+	}
+
+	var choices []string
+	for _, entry := range strings.Split(vars[1], ",") {
+		encodedChoice := strings.SplitN(entry, "=", 2)[0]
+		choiceBytes, err := hex.DecodeString(encodedChoice)
+		if err != nil {
+			return nil, errors.New("couldnt decode choice in message from hex string")
 		}
+		choices = append(choices, string(choiceBytes))
 	}
-	if !validChoice {
+
+	return choices, nil
+}
+
+// Decodes the percentage allocated to each choice in a weighted ballot.
+func DecodeVoteMessageAllocations(message string) (map[string]float64, error) {
+	vars := strings.Split(message, ":")
+	if len(vars) != 3 {
+		return nil, errors.New("invalid vote message format")
+	}
+
+	allocations := make(map[string]float64)
+	for _, entry := range strings.Split(vars[1], ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("weighted vote message must include a percentage for each choice")
+		}
+
+		choiceBytes, err := hex.DecodeString(parts[0])
+		if err != nil {
+			return nil, errors.New("couldnt decode choice in message from hex string")
+		}
+
+		percentage, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, errors.New("couldnt parse percentage in message")
+		}
+
+		allocations[string(choiceBytes)] += percentage
+	}
+
+	return allocations, nil
+}
+
+func (v *Vote) ValidateChoice(proposal Proposal) error {
+	if proposal.AcceptsMultipleChoices() {
+		return v.validateMultipleChoices(proposal)
+	}
+	if proposal.IsWeighted() {
+		return v.validateAllocations(proposal)
+	}
+
+	if !proposal.HasChoice(v.Choice) {
 		return errors.New("invalid choice for proposal")
 	}
 	return nil
 }
 
+// A ranked or approval ballot must list at least one valid choice,
+// each at most once.
+func (v *Vote) validateMultipleChoices(proposal Proposal) error {
+	if v.Choices == nil || len(*v.Choices) == 0 {
+		return fmt.Errorf("%s vote must include choices", *proposal.Voting_type)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range *v.Choices {
+		if !proposal.HasChoice(c) {
+			return errors.New("invalid choice for proposal")
+		}
+		if seen[c] {
+			return errors.New("choices may only be selected once")
+		}
+		seen[c] = true
+	}
+
+	return nil
+}
+
+// A weighted ballot must allocate a positive percentage to each listed
+// choice, adding up to 100.
+func (v *Vote) validateAllocations(proposal Proposal) error {
+	if v.Allocations == nil || len(*v.Allocations) == 0 {
+		return errors.New("weighted vote must include allocations")
+	}
+
+	total := 0.0
+	for c, percentage := range *v.Allocations {
+		if !proposal.HasChoice(c) {
+			return errors.New("invalid choice for proposal")
+		}
+		if percentage <= 0 {
+			return errors.New("allocations must be greater than zero")
+		}
+		total += percentage
+	}
+
+	if math.Abs(total-100) > allocationTolerance {
+		return fmt.Errorf("allocations must add up to 100, got %v", total)
+	}
+
+	return nil
+}
+
+// Sets the vote's choice for ballots that carry more than one choice.
+// Ranked and approval ballots store the first listed choice, weighted
+// ballots store the choice with the largest allocation.
+func (v *Vote) SetPrimaryChoice(proposal Proposal) {
+	if proposal.AcceptsMultipleChoices() && v.Choices != nil && len(*v.Choices) > 0 {
+		v.Choice = (*v.Choices)[0]
+	}
+
+	if proposal.IsWeighted() && v.Allocations != nil {
+		largest := 0.0
+		for _, c := range proposal.Choices {
+			if percentage := (*v.Allocations)[c.Choice_text]; percentage > largest {
+				largest = percentage
+				v.Choice = c.Choice_text
+			}
+		}
+	}
+}
+
+// Returns an error if the ballot signed in the vote message
+// does not match the ballot submitted with the vote.
+func (v *Vote) ValidateMessageBallot(message string, proposal Proposal) error {
+	if proposal.IsWeighted() {
+		return v.validateMessageAllocations(message)
+	}
+	if proposal.AcceptsMultipleChoices() {
+		return v.validateMessageChoices(message)
+	}
+	return nil
+}
+
+func (v *Vote) validateMessageChoices(message string) error {
+	signedChoices, err := DecodeVoteMessageChoices(message)
+	if err != nil {
+		return err
+	}
+
+	choices := []string{v.Choice}
+	if v.Choices != nil {
+		choices = *v.Choices
+	}
+
+	if len(signedChoices) != len(choices) {
+		return errors.New("vote choices do not match signed message")
+	}
+	for i := range choices {
+		if signedChoices[i] != choices[i] {
+			return errors.New("vote choices do not match signed message")
+		}
+	}
+
+	return nil
+}
+
+func (v *Vote) validateMessageAllocations(message string) error {
+	signedAllocations, err := DecodeVoteMessageAllocations(message)
+	if err != nil {
+		return err
+	}
+
+	if v.Allocations == nil || len(signedAllocations) != len(*v.Allocations) {
+		return errors.New("vote allocations do not match signed message")
+	}
+	for c, percentage := range *v.Allocations {
+		signed, ok := signedAllocations[c]
+		if !ok || math.Abs(signed-percentage) > allocationTolerance {
+			return errors.New("vote allocations do not match signed message")
+		}
+	}
+
+	return nil
+}
+
+// Splits an amount across the choices of a weighted ballot
+// in proportion to the ballot's allocations.
+func (v *Vote) Apportion(amount float64) map[string]float64 {
+	if v.Allocations == nil {
+		return map[string]float64{v.Choice: amount}
+	}
+
+	split := make(map[string]float64)
+	for c, percentage := range *v.Allocations {
+		split[c] = amount * percentage / 100
+	}
+
+	return split
+}
+
 func getUsersNFTs(db *s.Database, votes []*VoteWithBalance) ([]*VoteWithBalance, error) {
 	for _, vote := range votes {
 		nftIds, err := GetUserNFTs(db, vote)
@@ -379,13 +681,19 @@ func DoesNFTExist(db *s.Database, v *VoteWithBalance) (bool, error) {
 }
 
 func createVote(db *s.Database, v *Vote) error {
+	if v.Pin_status == "" {
+		v.Pin_status = PinPinned
+	}
+
 	// Create Vote
 	err := db.Conn.QueryRow(db.Context,
 		`
-			INSERT INTO votes(proposal_id, addr, choice, composite_signatures, cid, message)
-			VALUES($1, $2, $3, $4, $5, $6)
+			INSERT INTO votes(proposal_id, addr, choice, composite_signatures, cid, message, choices, allocations, sealed_ballot,
+				pin_status)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 			RETURNING id, created_at
-		`, v.Proposal_id, v.Addr, v.Choice, v.Composite_signatures, v.Cid, v.Message).Scan(&v.ID, &v.Created_at)
+		`, v.Proposal_id, v.Addr, v.Choice, v.Composite_signatures, v.Cid, v.Message, v.Choices, v.Allocations,
+		v.Sealed_ballot, v.Pin_status).Scan(&v.ID, &v.Created_at)
 
 	return err
 }
@@ -479,7 +787,7 @@ func getUserVotes(db *s.Database, addr string, communityId int) ([]VotingStreak,
 		LEFT OUTER JOIN (
 			SELECT * FROM votes where addr = '%s'
 		) v ON v.proposal_id = p.id 
-		where p.community_id = $1
+		where p.community_id = $1 AND p.status <> 'draft'
 		ORDER BY start_time ASC
 	`, addr)
 	var votingStreak []VotingStreak
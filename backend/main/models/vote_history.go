@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+// A vote that was replaced when the voter changed their vote.
+type VoteHistory struct {
+	ID                   int                     `json:"id"`
+	Vote_id              int                     `json:"voteId"`
+	Proposal_id          int                     `json:"proposalId"`
+	Addr                 string                  `json:"addr"`
+	Choice               string                  `json:"choice"`
+	Choices              *[]string               `json:"choices,omitempty"`
+	Allocations          *map[string]float64     `json:"allocations,omitempty"`
+	Composite_signatures *[]s.CompositeSignature `json:"compositeSignatures"`
+	Cid                  *string                 `json:"cid"`
+	Message              string                  `json:"message"`
+	Cast_at              time.Time               `json:"castAt"`
+	Replaced_at          time.Time               `json:"replacedAt"`
+	Sealed_ballot        *string                 `json:"sealedBallot,omitempty"`
+}
+
+func GetVoteHistory(db *s.Database, proposalId int, addr string) ([]*VoteHistory, error) {
+	history := []*VoteHistory{}
+	err := pgxscan.Select(db.Context, db.Conn, &history,
+		`
+		SELECT * FROM vote_history
+		WHERE proposal_id = $1 AND addr = $2
+		ORDER BY cast_at DESC
+		`, proposalId, addr)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// Replaces the voter's current vote with v, moving the current vote
+// into the vote history.
+func (v *Vote) UpdateVote(db *s.Database) error {
+	if v.Pin_status == "" {
+		v.Pin_status = PinPinned
+	}
+
+	tx, err := db.Conn.Begin(db.Context)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(db.Context)
+
+	_, err = tx.Exec(db.Context,
+		`
+		INSERT INTO vote_history(vote_id, proposal_id, addr, choice, choices, allocations,
+			composite_signatures, cid, message, cast_at, sealed_ballot)
+		SELECT id, proposal_id, addr, choice, choices, allocations,
+			composite_signatures, cid, message, COALESCE(updated_at, created_at), sealed_ballot
+		FROM votes
+		WHERE proposal_id = $1 AND addr = $2
+		FOR UPDATE
+		`, v.Proposal_id, v.Addr)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(db.Context,
+		`
+		UPDATE votes
+		SET choice = $3, choices = $4, allocations = $5, composite_signatures = $6,
+			cid = $7, message = $8, sealed_ballot = $9, pin_status = $10, updated_at = (now() at time zone 'utc')
+		WHERE proposal_id = $1 AND addr = $2
+		RETURNING id, created_at, updated_at
+		`, v.Proposal_id, v.Addr, v.Choice, v.Choices, v.Allocations, v.Composite_signatures,
+		v.Cid, v.Message, v.Sealed_ballot, v.Pin_status).Scan(&v.ID, &v.Created_at, &v.Updated_at)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(db.Context)
+}
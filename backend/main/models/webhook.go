@@ -0,0 +1,236 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+)
+
+type Webhook struct {
+	ID           int        `json:"id"`
+	Community_id int        `json:"communityId"`
+	Url          string     `json:"url" validate:"required,url"`
+	Secret       string     `json:"secret,omitempty"`
+	Events       []string   `json:"events" validate:"required,min=1"`
+	Is_active    bool       `json:"isActive"`
+	Created_at   *time.Time `json:"createdAt,omitempty"`
+}
+
+type WebhookPayload struct {
+	Webhook
+	s.TimestampSignaturePayload
+}
+
+type WebhookDelivery struct {
+	ID              int         `json:"id"`
+	Webhook_id      int         `json:"webhookId"`
+	Event           string      `json:"event"`
+	Payload         interface{} `json:"payload"`
+	Status          string      `json:"status"`
+	Attempts        int         `json:"attempts"`
+	Response_status *int        `json:"responseStatus,omitempty"`
+	Error           *string     `json:"error,omitempty"`
+	Next_attempt_at *time.Time  `json:"nextAttemptAt,omitempty"`
+	Delivered_at    *time.Time  `json:"deliveredAt,omitempty"`
+	Created_at      *time.Time  `json:"createdAt,omitempty"`
+}
+
+// The JSON body sent to a webhook's URL.
+type WebhookEvent struct {
+	Event        string      `json:"event"`
+	Community_id int         `json:"communityId"`
+	Data         interface{} `json:"data"`
+	Created_at   time.Time   `json:"createdAt"`
+}
+
+const (
+	ProposalCreatedEvent   = "proposal.created"
+	ProposalEditedEvent    = "proposal.edited"
+	ProposalOpenedEvent    = "proposal.opened"
+	ProposalClosedEvent    = "proposal.closed"
+	ProposalCancelledEvent = "proposal.cancelled"
+	VoteCastEvent          = "vote.cast"
+)
+
+var webhookEvents = []string{
+	ProposalCreatedEvent,
+	ProposalEditedEvent,
+	ProposalOpenedEvent,
+	ProposalClosedEvent,
+	ProposalCancelledEvent,
+	VoteCastEvent,
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+func GetWebhooksForCommunity(db *s.Database, communityId int) ([]*Webhook, error) {
+	webhooks := []*Webhook{}
+	err := pgxscan.Select(db.Context, db.Conn, &webhooks,
+		`SELECT * FROM webhooks WHERE community_id = $1 ORDER BY id ASC`,
+		communityId)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func GetActiveWebhooksForEvent(db *s.Database, communityId int, event string) ([]*Webhook, error) {
+	webhooks := []*Webhook{}
+	err := pgxscan.Select(db.Context, db.Conn, &webhooks,
+		`
+		SELECT * FROM webhooks
+		WHERE community_id = $1 AND is_active = 'true' AND $2 = ANY(events)
+		`, communityId, event)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (w *Webhook) GetWebhookById(db *s.Database) error {
+	return pgxscan.Get(db.Context, db.Conn, w,
+		`SELECT * FROM webhooks WHERE id = $1`,
+		w.ID)
+}
+
+func (w *Webhook) CreateWebhook(db *s.Database) error {
+	return db.Conn.QueryRow(db.Context,
+		`
+		INSERT INTO webhooks(community_id, url, secret, events, is_active)
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+		`, w.Community_id, w.Url, w.Secret, w.Events, w.Is_active).Scan(&w.ID, &w.Created_at)
+}
+
+func (w *Webhook) UpdateWebhook(db *s.Database) error {
+	_, err := db.Conn.Exec(db.Context,
+		`
+		UPDATE webhooks
+		SET url = $1, events = $2, is_active = $3
+		WHERE id = $4
+		`, w.Url, w.Events, w.Is_active, w.ID)
+	return err
+}
+
+func (w *Webhook) DeleteWebhook(db *s.Database) error {
+	_, err := db.Conn.Exec(db.Context,
+		`DELETE FROM webhooks WHERE id = $1`,
+		w.ID)
+	return err
+}
+
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.Url)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return errors.New("webhook url must be an https url")
+	}
+	if err := s.ValidatePublicHost(u.Hostname()); err != nil {
+		return fmt.Errorf("webhook url must be public: %w", err)
+	}
+
+	if len(w.Events) == 0 {
+		return errors.New("webhook must subscribe to at least one event")
+	}
+	for _, e := range w.Events {
+		if !isWebhookEvent(e) {
+			return fmt.Errorf("invalid webhook event: %s", e)
+		}
+	}
+
+	return nil
+}
+
+func isWebhookEvent(event string) bool {
+	for _, e := range webhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *WebhookDelivery) CreateWebhookDelivery(db *s.Database) error {
+	return db.Conn.QueryRow(db.Context,
+		`
+		INSERT INTO webhook_deliveries(webhook_id, event, payload, status, next_attempt_at)
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+		`, d.Webhook_id, d.Event, d.Payload, d.Status, d.Next_attempt_at).Scan(&d.ID, &d.Created_at)
+}
+
+// Claims pending deliveries that are due for an attempt. Claimed
+// deliveries are leased until the lease expires, so concurrent
+// workers do not attempt the same delivery.
+func ClaimDueWebhookDeliveries(db *s.Database, limit int, lease time.Duration) ([]*WebhookDelivery, error) {
+	deliveries := []*WebhookDelivery{}
+	leaseUntil := time.Now().UTC().Add(lease)
+	err := pgxscan.Select(db.Context, db.Conn, &deliveries,
+		`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= (now() at time zone 'utc')
+			ORDER BY next_attempt_at ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+		`, leaseUntil, limit)
+
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Records the result of a delivery attempt.
+func (d *WebhookDelivery) UpdateWebhookDelivery(db *s.Database) error {
+	_, err := db.Conn.Exec(db.Context,
+		`
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, response_status = $3, error = $4,
+		next_attempt_at = $5, delivered_at = $6
+		WHERE id = $7
+		`, d.Status, d.Attempts, d.Response_status, d.Error, d.Next_attempt_at, d.Delivered_at, d.ID)
+	return err
+}
+
+func GetDeliveriesForWebhook(
+	db *s.Database,
+	webhookId int,
+	params s.PageParams,
+) ([]*WebhookDelivery, int, error) {
+	deliveries := []*WebhookDelivery{}
+	sql := fmt.Sprintf(`
+		SELECT * FROM webhook_deliveries
+		WHERE webhook_id = $3
+		ORDER BY created_at %s
+		LIMIT $1 OFFSET $2
+	`, params.Order)
+
+	err := pgxscan.Select(db.Context, db.Conn, &deliveries, sql, params.Count, params.Start, webhookId)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return nil, 0, err
+	}
+
+	var totalRecords int
+	countSql := `SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = $1`
+	_ = db.Conn.QueryRow(db.Context, countSql, webhookId).Scan(&totalRecords)
+
+	return deliveries, totalRecords, nil
+}
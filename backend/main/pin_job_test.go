@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*   Pin Jobs    */
+/*****************/
+
+func TestPinJobs(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("votes")
+	clearTable("pin_jobs")
+	communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+	proposalId := otu.AddActiveProposals(communityId, 1)[0]
+
+	votePayload := otu.GenerateValidVotePayload("user1", proposalId, "a")
+	response := otu.CreateVoteAPI(proposalId, votePayload)
+	CheckResponseCode(t, http.StatusCreated, response.Code)
+
+	response = otu.GetVoteForProposalByAccountNameAPI(proposalId, "user1")
+	var vote models.Vote
+	json.Unmarshal(response.Body.Bytes(), &vote)
+
+	t.Run("Votes pinned when cast should not be queued", func(t *testing.T) {
+		assert.Equal(t, models.PinPinned, vote.Pin_status)
+
+		response := otu.GetStuckPinsAPI(communityId, "", "user1")
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var body shared.PaginatedResponse
+		json.Unmarshal(response.Body.Bytes(), &body)
+		assert.Equal(t, 0, body.TotalRecords)
+	})
+
+	t.Run("Should list pending pins and set the cid once pinned", func(t *testing.T) {
+		job := otu.AddPendingVotePin(communityId, vote.ID)
+
+		response := otu.GetStuckPinsAPI(communityId, models.PinPending, "user1")
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var body struct {
+			Data         []models.PinJob `json:"data"`
+			TotalRecords int             `json:"totalRecords"`
+		}
+		json.Unmarshal(response.Body.Bytes(), &body)
+		assert.Equal(t, 1, body.TotalRecords)
+		assert.Equal(t, models.PinRecordVote, body.Data[0].Record_type)
+		assert.Equal(t, vote.ID, body.Data[0].Record_id)
+
+		job.Attempts = 1
+		assert.Nil(t, job.CompletePinJob(otu.A.DB, "dummy-hash"))
+
+		response = otu.GetVoteForProposalByAccountNameAPI(proposalId, "user1")
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		var pinned models.Vote
+		json.Unmarshal(response.Body.Bytes(), &pinned)
+		assert.Equal(t, models.PinPinned, pinned.Pin_status)
+		assert.Equal(t, "dummy-hash", *pinned.Cid)
+
+		response = otu.GetStuckPinsAPI(communityId, "", "user1")
+		json.Unmarshal(response.Body.Bytes(), &body)
+		assert.Equal(t, 0, body.TotalRecords)
+	})
+
+	t.Run("Queueing a pin again should replace the pending pin", func(t *testing.T) {
+		otu.AddPendingVotePin(communityId, vote.ID)
+		otu.AddPendingVotePin(communityId, vote.ID)
+
+		response := otu.GetStuckPinsAPI(communityId, models.PinPending, "user1")
+
+		var body shared.PaginatedResponse
+		json.Unmarshal(response.Body.Bytes(), &body)
+		assert.Equal(t, 1, body.TotalRecords)
+	})
+
+	t.Run("Should set the cid of final results once pinned", func(t *testing.T) {
+		clearTable("proposal_results")
+
+		results := models.NewProposalResults(proposalId, []shared.Choice{{Choice_text: "a"}})
+		results.Pin_status = models.PinPending
+		created, err := results.CreateProposalResults(otu.A.DB)
+		assert.Nil(t, err)
+		assert.True(t, created)
+
+		job := models.PinJob{
+			Community_id: communityId,
+			Record_type:  models.PinRecordProposalResults,
+			Record_id:    proposalId,
+			Payload:      []byte(`{"proposalId":1}`),
+			Status:       models.PinPending,
+		}
+		assert.Nil(t, job.CreatePinJob(otu.A.DB))
+		assert.Nil(t, job.CompletePinJob(otu.A.DB, "dummy-hash"))
+
+		final := models.ProposalResults{Proposal_id: proposalId}
+		assert.Nil(t, final.GetLatestProposalResultsById(otu.A.DB))
+		assert.Equal(t, "dummy-hash", *final.Cid)
+
+		created, err = results.CreateProposalResults(otu.A.DB)
+		assert.Nil(t, err)
+		assert.False(t, created)
+	})
+
+	t.Run("Should set the cid of a revealed proposal key once pinned", func(t *testing.T) {
+		key, err := models.GetOrCreateProposalKey(otu.A.DB, proposalId)
+		assert.Nil(t, err)
+
+		key.Pin_status = models.PinPending
+		revealed, err := key.RevealProposalKey(otu.A.DB)
+		assert.Nil(t, err)
+		assert.True(t, revealed)
+		assert.Nil(t, key.Cid)
+
+		job := models.PinJob{
+			Community_id: communityId,
+			Record_type:  models.PinRecordProposalKey,
+			Record_id:    proposalId,
+			Payload:      []byte(`{"proposalId":1}`),
+			Status:       models.PinPending,
+		}
+		assert.Nil(t, job.CreatePinJob(otu.A.DB))
+		assert.Nil(t, job.CompletePinJob(otu.A.DB, "dummy-hash"))
+
+		assert.Nil(t, key.GetProposalKey(otu.A.DB))
+		assert.Equal(t, "dummy-hash", *key.Cid)
+		assert.Equal(t, models.PinPinned, key.Pin_status)
+
+		revealed, err = key.RevealProposalKey(otu.A.DB)
+		assert.Nil(t, err)
+		assert.False(t, revealed)
+	})
+
+	t.Run("Only community admins should list pins", func(t *testing.T) {
+		response := otu.GetStuckPinsAPI(communityId, "", "")
+		checkResponseCode(t, http.StatusUnauthorized, response.Code)
+
+		response = otu.GetStuckPinsAPI(communityId, "", "user2")
+		checkResponseCode(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("A retried pin should keep its payload as queued", func(t *testing.T) {
+		payload := []byte(`{"vote": {"choice": "a", "addr": "0x01"}}`)
+		job := models.PinJob{
+			Community_id: communityId,
+			Record_type:  models.PinRecordVote,
+			Record_id:    vote.ID,
+			Payload:      payload,
+			Status:       models.PinPending,
+		}
+		assert.Nil(t, job.CreatePinJob(otu.A.DB))
+
+		response := otu.GetStuckPinsAPI(communityId, models.PinPending, "user1")
+		var body struct {
+			Data []struct {
+				Payload json.RawMessage `json:"payload"`
+			} `json:"data"`
+		}
+		json.Unmarshal(response.Body.Bytes(), &body)
+		assert.Equal(t, 1, len(body.Data))
+		assert.Equal(t, string(payload), string(body.Data[0].Payload))
+	})
+
+	t.Run("Should reject unknown statuses", func(t *testing.T) {
+		response := otu.GetStuckPinsAPI(communityId, "pinned", "user1")
+		checkResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/server"
 	"github.com/DapperCollectives/CAST/backend/main/shared"
 	"github.com/stretchr/testify/assert"
 )
@@ -112,6 +114,103 @@ func TestCreateProposal(t *testing.T) {
 
 		assert.Equal(t, "Timestamp on request has expired.", m["error"])
 	})
+
+	t.Run("Should throw an error if the snapshot block is not sealed", func(t *testing.T) {
+		latest, _ := otu.Adapter.GetLatestSealedBlockHeader()
+		blockHeight := latest.Height + 1000
+
+		proposalStruct := otu.GenerateProposalStruct("user1", communityId)
+		proposalStruct.Snapshot_block_height = &blockHeight
+		payload := otu.GenerateProposalPayload("user1", proposalStruct)
+
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Should snapshot the proposal at the given block height", func(t *testing.T) {
+		latest, _ := otu.Adapter.GetLatestSealedBlockHeader()
+
+		proposalStruct := otu.GenerateProposalStruct("user1", communityId)
+		proposalStruct.Snapshot_block_height = &latest.Height
+		payload := otu.GenerateProposalPayload("user1", proposalStruct)
+
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var p models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &p)
+		assert.Equal(t, latest.Height, *p.Block_height)
+	})
+
+	t.Run("Should snapshot the proposal at the last block before the given time", func(t *testing.T) {
+		latest, _ := otu.Adapter.GetLatestSealedBlockHeader()
+		snapshotTime := latest.Timestamp
+
+		proposalStruct := otu.GenerateProposalStruct("user1", communityId)
+		proposalStruct.Snapshot_time = &snapshotTime
+		payload := otu.GenerateProposalPayload("user1", proposalStruct)
+
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var p models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &p)
+		assert.Equal(t, latest.Height, *p.Block_height)
+	})
+
+	t.Run("Should throw an error if both a snapshot time and block are given", func(t *testing.T) {
+		latest, _ := otu.Adapter.GetLatestSealedBlockHeader()
+
+		proposalStruct := otu.GenerateProposalStruct("user1", communityId)
+		proposalStruct.Snapshot_block_height = &latest.Height
+		proposalStruct.Snapshot_time = &latest.Timestamp
+		payload := otu.GenerateProposalPayload("user1", proposalStruct)
+
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusBadRequest, response.Code)
+
+		var m map[string]interface{}
+		json.Unmarshal(response.Body.Bytes(), &m)
+
+		assert.Equal(t, "snapshot time and snapshot block height are exclusive", m["error"])
+	})
+}
+
+func TestExportProposals(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("votes")
+	communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+	proposalId := otu.AddActiveProposals(communityId, 1)[0]
+
+	for _, account := range []string{"user1", "user2"} {
+		votePayload := otu.GenerateValidVotePayload(account, proposalId, "a")
+		response := otu.CreateVoteAPI(proposalId, votePayload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+	}
+
+	proposalStruct := otu.GenerateProposalStruct("user1", communityId)
+	proposalStruct.Name = "=1+1"
+	response := otu.CreateProposalAPI(otu.GenerateProposalPayload("user1", proposalStruct))
+	CheckResponseCode(t, http.StatusCreated, response.Code)
+
+	response = otu.ExportProposalsAPI(communityId, "csv")
+	CheckResponseCode(t, http.StatusOK, response.Code)
+
+	rows, err := csv.NewReader(response.Body).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(rows))
+
+	t.Run("should export the vote count of each proposal", func(t *testing.T) {
+		assert.Equal(t, "total_votes", rows[0][9])
+		assert.Equal(t, "2", rows[1][9])
+		assert.Equal(t, "0", rows[2][9])
+	})
+
+	t.Run("should not export cells as spreadsheet formulas", func(t *testing.T) {
+		assert.Equal(t, "'=1+1", rows[2][1])
+	})
 }
 
 func TestUpdateProposal(t *testing.T) {
@@ -217,6 +316,222 @@ func TestUpdateProposal(t *testing.T) {
 	})
 }
 
+func TestProposalDrafts(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	authorName := "user1"
+	communityId := otu.AddCommunitiesWithUsers(1, authorName)[0]
+
+	createDraft := func(t *testing.T) models.Proposal {
+		proposalStruct := otu.GenerateProposalStruct(authorName, communityId)
+		draft := "draft"
+		proposalStruct.Status = &draft
+		payload := otu.GenerateProposalPayload(authorName, proposalStruct)
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var p models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &p)
+		assert.Nil(t, p.Cid)
+		assert.Nil(t, p.Block_height)
+		return p
+	}
+
+	t.Run("Drafts are hidden from the proposal list", func(t *testing.T) {
+		createDraft(t)
+
+		response := otu.GetProposalsForCommunityAPI(communityId)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var body shared.PaginatedResponse
+		json.Unmarshal(response.Body.Bytes(), &body)
+		assert.Equal(t, 0, body.TotalRecords)
+	})
+
+	t.Run("A new proposal must be a draft or published", func(t *testing.T) {
+		proposalStruct := otu.GenerateProposalStruct(authorName, communityId)
+		cancelled := "cancelled"
+		proposalStruct.Status = &cancelled
+		payload := otu.GenerateProposalPayload(authorName, proposalStruct)
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Only authors should be able to read a draft", func(t *testing.T) {
+		p := createDraft(t)
+
+		response := otu.GetDraftByIdAPI(communityId, p.ID, "")
+		checkResponseCode(t, http.StatusUnauthorized, response.Code)
+
+		response = otu.GetDraftByIdAPI(communityId, p.ID, "user2")
+		checkResponseCode(t, http.StatusForbidden, response.Code)
+
+		response = otu.GetDraftByIdAPI(communityId, p.ID, authorName)
+		checkResponseCode(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("A scheduled draft that cannot be published should be unscheduled", func(t *testing.T) {
+		p := createDraft(t)
+		otu.ScheduleDraft(p.ID, time.Now().UTC().Add(-time.Minute), time.Now().UTC().Add(-time.Hour))
+
+		server.NewScheduler(otu.A).PublishScheduledDrafts()
+
+		response := otu.GetDraftByIdAPI(communityId, p.ID, authorName)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var failed models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &failed)
+		assert.Equal(t, "draft", *failed.Status)
+		assert.Nil(t, failed.Publish_at)
+		assert.NotNil(t, failed.Publish_error)
+
+		drafts, err := models.GetScheduledDrafts(otu.A.DB)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(drafts))
+	})
+
+	t.Run("A scheduled draft snapshotting a future time should wait for it", func(t *testing.T) {
+		proposalStruct := otu.GenerateProposalStruct(authorName, communityId)
+		draft := "draft"
+		snapshotTime := time.Now().UTC().Add(time.Hour)
+		proposalStruct.Status = &draft
+		proposalStruct.Snapshot_time = &snapshotTime
+		payload := otu.GenerateProposalPayload(authorName, proposalStruct)
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var p models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &p)
+		otu.ScheduleDraft(p.ID, time.Now().UTC().Add(-time.Minute), time.Now().UTC().Add(2*time.Hour))
+
+		server.NewScheduler(otu.A).PublishScheduledDrafts()
+
+		deferred := models.Proposal{ID: p.ID}
+		assert.Nil(t, deferred.GetProposalById(otu.A.DB))
+		assert.Equal(t, "draft", *deferred.Status)
+		assert.NotNil(t, deferred.Publish_at)
+		assert.Equal(t, 0, deferred.Publish_attempts)
+		assert.False(t, deferred.Publish_retry_at.Before(snapshotTime))
+
+		drafts, err := models.GetScheduledDrafts(otu.A.DB)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(drafts))
+	})
+
+	t.Run("An author should be able to edit a draft", func(t *testing.T) {
+		p := createDraft(t)
+
+		response := otu.UpdateDraftAPI(p.ID, otu.GenerateDraftPayload(authorName, "Edited Draft"))
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var edited models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &edited)
+		assert.Equal(t, "Edited Draft", edited.Name)
+		assert.Equal(t, "draft", *edited.Status)
+	})
+
+	t.Run("Publishing a draft snapshots and pins it", func(t *testing.T) {
+		p := createDraft(t)
+
+		response := otu.UpdateProposalAPI(p.ID, otu.GeneratePublishProposalStruct(authorName, p.ID))
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var published models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &published)
+		assert.Equal(t, "published", *published.Status)
+		assert.NotNil(t, published.Cid)
+		assert.NotNil(t, published.Block_height)
+
+		// published proposals can no longer be edited as drafts
+		response = otu.UpdateDraftAPI(p.ID, otu.GenerateDraftPayload(authorName, "Too Late"))
+		checkResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestEditProposal(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("proposal_revisions")
+	authorName := "user1"
+	communityId := otu.AddCommunitiesWithUsers(1, authorName)[0]
+
+	t.Run("An author should be able to edit a pending proposal", func(t *testing.T) {
+		proposalStruct := otu.GenerateProposalStruct(authorName, communityId)
+		payload := otu.GenerateProposalPayload(authorName, proposalStruct)
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var p models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &p)
+
+		response = otu.EditProposalAPI(p.ID, otu.GenerateEditProposalPayload(authorName, "Fixed a typo"))
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var edited models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &edited)
+		assert.Equal(t, "Fixed a typo", *edited.Body)
+		assert.NotEqual(t, *p.Cid, *edited.Cid)
+
+		response = otu.GetProposalRevisionsAPI(p.ID)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var revisions []models.ProposalRevision
+		json.Unmarshal(response.Body.Bytes(), &revisions)
+		assert.Equal(t, 2, len(revisions))
+		assert.Equal(t, 2, revisions[0].Revision)
+		assert.Equal(t, *edited.Cid, *revisions[0].Cid)
+		assert.Equal(t, *p.Cid, *revisions[1].Cid)
+	})
+
+	t.Run("An active proposal should not be editable", func(t *testing.T) {
+		proposalStruct := otu.GenerateProposalStruct(authorName, communityId)
+		proposalStruct.Start_time = time.Now().AddDate(0, -1, 0)
+		payload := otu.GenerateProposalPayload(authorName, proposalStruct)
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var p models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &p)
+
+		response = otu.EditProposalAPI(p.ID, otu.GenerateEditProposalPayload(authorName, "Too late"))
+		checkResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("An edit should replace a pin still pending for the proposal", func(t *testing.T) {
+		clearTable("pin_jobs")
+		proposalStruct := otu.GenerateProposalStruct(authorName, communityId)
+		payload := otu.GenerateProposalPayload(authorName, proposalStruct)
+		response := otu.CreateProposalAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var p models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &p)
+
+		job := models.PinJob{
+			Community_id: communityId,
+			Record_type:  models.PinRecordProposal,
+			Record_id:    p.ID,
+			Payload:      []byte(`{"name":"before the edit"}`),
+			Status:       models.PinPending,
+		}
+		assert.Nil(t, job.CreatePinJob(otu.A.DB))
+
+		response = otu.EditProposalAPI(p.ID, otu.GenerateEditProposalPayload(authorName, "Edited"))
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		// the replaced pin leaves the edited proposal's cid untouched
+		assert.Nil(t, job.CompletePinJob(otu.A.DB, "stale-hash"))
+
+		response = otu.GetProposalByIdAPI(communityId, p.ID)
+		var edited models.Proposal
+		json.Unmarshal(response.Body.Bytes(), &edited)
+		assert.Equal(t, "Edited", *edited.Body)
+		assert.NotEqual(t, "stale-hash", *edited.Cid)
+	})
+}
+
 func TestCreateManyProposals(t *testing.T) {
 	clearTable("communities")
 	clearTable("community_users")
@@ -248,3 +563,72 @@ func TestCreateManyProposals(t *testing.T) {
 	})
 
 }
+
+func TestProposalOutcome(t *testing.T) {
+	quorum := 50.0
+	percentage := models.QuorumPercentage
+	totalSupply := 200.0
+	passThreshold := 60.0
+
+	proposal := &models.Proposal{
+		ID: 1,
+		Choices: []shared.Choice{
+			{Choice_text: "yes"},
+			{Choice_text: "no"},
+		},
+		Quorum:         &quorum,
+		Quorum_type:    &percentage,
+		Total_supply:   &totalSupply,
+		Pass_threshold: &passThreshold,
+	}
+
+	tally := func(yes, no float64) *models.ProposalResults {
+		r := models.NewProposalResults(proposal.ID, proposal.Choices)
+		r.Results_float["yes"] = yes
+		r.Results_float["no"] = no
+		r.ComputeOutcome(proposal, yes+no)
+		return r
+	}
+
+	t.Run("Percentage quorums require a strategy weighing token balances one to one", func(t *testing.T) {
+		strategy := "quadratic-token-weighted"
+		p := *proposal
+		p.Strategy = &strategy
+		assert.NotNil(t, p.ValidateQuorum())
+
+		strategy = "token-weighted-default"
+		assert.Nil(t, p.ValidateQuorum())
+	})
+
+	t.Run("Quorum not met below the percentage of total supply", func(t *testing.T) {
+		r := tally(60, 30)
+		assert.Equal(t, models.QuorumNotMet, *r.Outcome)
+	})
+
+	t.Run("Fails below the pass threshold", func(t *testing.T) {
+		r := tally(55, 45)
+		assert.Equal(t, models.Failed, *r.Outcome)
+		assert.Equal(t, "yes", *r.Winning_choice)
+	})
+
+	t.Run("Passes when quorum and threshold are met", func(t *testing.T) {
+		r := tally(70, 30)
+		assert.Equal(t, models.Passed, *r.Outcome)
+		assert.Equal(t, 100.0, r.Turnout)
+	})
+
+	t.Run("Fails when another choice than the pass choice leads", func(t *testing.T) {
+		r := tally(30, 70)
+		assert.Equal(t, models.Failed, *r.Outcome)
+		assert.Equal(t, "no", *r.Winning_choice)
+	})
+
+	t.Run("Passes when the given pass choice leads", func(t *testing.T) {
+		passChoice := "no"
+		proposal.Pass_choice = &passChoice
+		defer func() { proposal.Pass_choice = nil }()
+
+		r := tally(30, 70)
+		assert.Equal(t, models.Passed, *r.Outcome)
+	})
+}
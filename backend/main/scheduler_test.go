@@ -0,0 +1,162 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/server"
+	utils "github.com/DapperCollectives/CAST/backend/main/test_utils"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*   Scheduler   */
+/*****************/
+
+func TestProposalTransitions(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("proposal_results")
+	clearTable("proposal_transitions")
+	communityId := otu.AddCommunities(1)[0]
+	scheduler := server.NewScheduler(otu.A)
+
+	getProposal := func(id int) models.Proposal {
+		p := models.Proposal{ID: id}
+		if err := p.GetProposalById(otu.A.DB); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	t.Run("Should record the transition of a closed proposal", func(t *testing.T) {
+		proposalId := otu.AddActiveProposals(communityId, 1)[0]
+		otu.UpdateProposalEndTime(proposalId, time.Now().UTC().Add(-time.Hour))
+
+		scheduler.ProcessTransitions()
+
+		p := getProposal(proposalId)
+		assert.Equal(t, "closed", *p.Processed_status)
+
+		transitions, err := models.GetProposalTransitions(otu.A.DB, proposalId)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(transitions))
+		assert.Equal(t, "active", transitions[0].To_status)
+		assert.Equal(t, "closed", transitions[1].To_status)
+		assert.Equal(t, "active", *transitions[1].From_status)
+
+		results := models.ProposalResults{Proposal_id: proposalId}
+		assert.Nil(t, results.GetLatestProposalResultsById(otu.A.DB))
+	})
+
+	t.Run("A proposal seen open should only record its closing", func(t *testing.T) {
+		proposalId := otu.AddActiveProposals(communityId, 1)[0]
+
+		scheduler.ProcessTransitions()
+		assert.Equal(t, "active", *getProposal(proposalId).Processed_status)
+
+		otu.UpdateProposalEndTime(proposalId, time.Now().UTC().Add(-time.Hour))
+		scheduler.ProcessTransitions()
+
+		transitions, err := models.GetProposalTransitions(otu.A.DB, proposalId)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(transitions))
+		assert.Equal(t, "closed", transitions[1].To_status)
+	})
+
+	t.Run("A failing transition should be retried later without holding up others", func(t *testing.T) {
+		failingId, _ := otu.AddProposalsForStrategy(communityId, "unknown-strategy", 1)
+		otu.UpdateProposalEndTime(failingId[0], time.Now().UTC().Add(-time.Hour))
+
+		scheduler.ProcessTransitions()
+
+		failing := getProposal(failingId[0])
+		assert.Nil(t, failing.Processed_status)
+		assert.Equal(t, 1, failing.Transition_attempts)
+		assert.True(t, failing.Transition_retry_at.After(time.Now().UTC()))
+
+		pending, err := models.GetProposalsPendingTransition(otu.A.DB)
+		assert.Nil(t, err)
+		for _, p := range pending {
+			assert.NotEqual(t, failing.ID, p.ID)
+		}
+
+		proposalId := otu.AddActiveProposals(communityId, 1)[0]
+		otu.UpdateProposalEndTime(proposalId, time.Now().UTC().Add(-time.Hour))
+
+		scheduler.ProcessTransitions()
+
+		p := getProposal(proposalId)
+		assert.Equal(t, "closed", *p.Processed_status)
+		assert.Equal(t, 0, p.Transition_attempts)
+		assert.Nil(t, p.Transition_retry_at)
+	})
+
+	t.Run("Should not record a transition twice", func(t *testing.T) {
+		proposalId := otu.AddProposals(communityId, 1)[0]
+		p := getProposal(proposalId)
+
+		recorded, err := p.RecordTransition(otu.A.DB)
+		assert.Nil(t, err)
+		assert.True(t, recorded)
+
+		stale := getProposal(proposalId)
+		stale.Processed_status = nil
+		recorded, err = stale.RecordTransition(otu.A.DB)
+		assert.Nil(t, err)
+		assert.False(t, recorded)
+	})
+}
+
+func TestResultsTx(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("proposal_results")
+	clearTable("proposal_transitions")
+
+	os.Setenv("FLOW_TX_SIGNER_ADDR", utils.ServiceAccountAddress)
+	os.Setenv("FLOW_TX_SIGNER_KEY", utils.ValidServiceAccountKey)
+	defer func() {
+		os.Unsetenv("FLOW_TX_SIGNER_ADDR")
+		os.Unsetenv("FLOW_TX_SIGNER_KEY")
+		otu.A.FlowAdapter.TxSigner = nil
+	}()
+	if err := otu.A.FlowAdapter.InitTxSigner(); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, otu.A.FlowAdapter.CanSubmitResults())
+
+	communityId, onchainId := otu.AddOnchainCommunity()
+	scheduler := server.NewScheduler(otu.A)
+
+	t.Run("Should store results with the VotingCommunity contract", func(t *testing.T) {
+		txId, err := otu.A.FlowAdapter.SubmitResults(onchainId, 1, []string{"a:1", "b:2"})
+		assert.Nil(t, err)
+
+		result, err := otu.A.FlowAdapter.WaitForTransaction(txId.String())
+		assert.Nil(t, err)
+		assert.Nil(t, result.Error)
+	})
+
+	t.Run("Should submit the results of a closed proposal once", func(t *testing.T) {
+		proposalId := otu.AddActiveProposals(communityId, 1)[0]
+		otu.UpdateProposalEndTime(proposalId, time.Now().UTC().Add(-time.Hour))
+
+		scheduler.ProcessTransitions()
+		scheduler.SubmitPendingResults()
+
+		p := models.Proposal{ID: proposalId}
+		assert.Nil(t, p.GetProposalById(otu.A.DB))
+		assert.Equal(t, models.ResultsTxSealed, *p.Results_tx_status)
+		assert.Equal(t, 1, p.Results_tx_attempts)
+		assert.NotNil(t, p.Results_tx_submitted_at)
+
+		pending, err := models.GetProposalsPendingResultsTx(otu.A.DB, 5, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(pending))
+	})
+}
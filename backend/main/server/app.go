@@ -44,34 +44,22 @@ type TxOptionsAddresses []string
 type App struct {
 	Router      *mux.Router
 	DB          *shared.Database
-	IpfsClient  *shared.IpfsClient
+	Storage     shared.Storage
 	FlowAdapter *shared.FlowAdapter
+	Chains      *shared.Chains
+	// runs community scripts on the emulator before they are accepted
+	DryRunAdapter *shared.FlowAdapter
 
-	SnapshotClient     *shared.SnapshotClient
+	SnapshotClient     shared.Snapshotter
 	TxOptionsAddresses []string
 	Env                string
 	AdminAllowlist     shared.Allowlist
 	CommunityBlocklist shared.Allowlist
 	Config             shared.Config
+	Scheduler          *Scheduler
 }
 
-type Strategy interface {
-	TallyVotes(votes []*models.VoteWithBalance, p *models.ProposalResults, proposal *models.Proposal) (models.ProposalResults, error)
-	GetVotes(votes []*models.VoteWithBalance, proposal *models.Proposal) ([]*models.VoteWithBalance, error)
-	GetVoteWeightForBalance(vote *models.VoteWithBalance, proposal *models.Proposal) (float64, error)
-	InitStrategy(f *shared.FlowAdapter, db *shared.Database, sc *shared.SnapshotClient)
-	FetchBalance(b *models.Balance, p *models.Proposal) (*models.Balance, error)
-	RequiresSnapshot() bool
-}
-
-var strategyMap = map[string]Strategy{
-	"token-weighted-default":        &strategies.TokenWeightedDefault{},
-	"staked-token-weighted-default": &strategies.StakedTokenWeightedDefault{},
-	"one-address-one-vote":          &strategies.OneAddressOneVote{},
-	"balance-of-nfts":               &strategies.BalanceOfNfts{},
-	"float-nfts":                    &strategies.FloatNFTs{},
-	"custom-script":                 &strategies.CustomScript{},
-}
+type Strategy = strategies.Strategy
 
 var customScripts []shared.CustomScript
 
@@ -141,7 +129,11 @@ func (a *App) Initialize() {
 	)
 
 	// IPFS
-	a.IpfsClient = shared.NewIpfsClient(os.Getenv("IPFS_KEY"), os.Getenv("IPFS_SECRET"))
+	a.Storage, err = shared.NewStorage()
+	if err != nil {
+		log.Error().Err(err).Msg("Error creating storage backend.")
+		os.Exit(1)
+	}
 
 	// Flow
 
@@ -166,13 +158,24 @@ func (a *App) Initialize() {
 		os.Setenv("FLOW_ENV", "emulator")
 	}
 	a.FlowAdapter = shared.NewFlowClient(os.Getenv("FLOW_ENV"), customScriptsMap)
+	if err := a.FlowAdapter.InitTxSigner(); err != nil {
+		log.Error().Err(err).Msg("Error loading Flow transaction signer.")
+	}
+
+	a.DryRunAdapter = a.FlowAdapter
+	if a.FlowAdapter.Env != "emulator" {
+		a.DryRunAdapter = shared.NewFlowClient("emulator", customScriptsMap)
+	}
+
+	// Chains strategies can read from
+	a.Chains = shared.NewChains(a.FlowAdapter, os.Getenv("EVM_RPC_URLS"))
 
 	// Snapshot
-	log.Info().Msgf("SNAPSHOT_BASE_URL: %s", os.Getenv("SNAPSHOT_BASE_URL"))
-	a.SnapshotClient = shared.NewSnapshotClient(
-		os.Getenv("SNAPSHOT_BASE_URL"),
-		*a.FlowAdapter,
-	)
+	a.SnapshotClient, err = shared.NewSnapshotter(a.FlowAdapter, a.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("Error creating snapshotter.")
+		os.Exit(1)
+	}
 	a.TxOptionsAddresses = strings.Fields(os.Getenv("TX_OPTIONS_ADDRS"))
 
 	// Router
@@ -188,6 +191,9 @@ func (a *App) Initialize() {
 }
 
 func (a *App) Run() {
+	a.Scheduler = NewScheduler(a)
+	a.Scheduler.Start()
+
 	addr := fmt.Sprintf(":%s", os.Getenv("API_PORT"))
 	log.Info().Msgf("Starting server on %s ...", addr)
 	log.Fatal().Err(http.ListenAndServe(addr, a.Router)).Msgf("Server at %s crashed!", addr)
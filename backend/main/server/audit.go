@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/DapperCollectives/CAST/backend/main/strategies"
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Exports every vote on the proposal with the snapshot balances it was
+// tallied with, along with the stored results if the proposal is closed.
+func (h *Helpers) exportProposalAudit(p models.Proposal) (models.ProposalAudit, error) {
+	if p.BallotsSealed() {
+		return models.ProposalAudit{}, errors.New("Votes are sealed until the proposal ends.")
+	}
+
+	votes, err := models.GetAllVotesForProposal(h.A.DB, p.ID, *p.Strategy)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting votes for proposal.")
+		return models.ProposalAudit{}, err
+	}
+
+	if err := h.fetchDelegatedBalances(p, votes); err != nil {
+		return models.ProposalAudit{}, err
+	}
+
+	if err := h.openBallots(p, votes); err != nil {
+		return models.ProposalAudit{}, err
+	}
+
+	audit := models.ProposalAudit{
+		Proposal:     p,
+		Block_height: p.Block_height,
+		Votes:        votes,
+	}
+
+	results := models.ProposalResults{Proposal_id: p.ID}
+	err = results.GetLatestProposalResultsById(h.A.DB)
+	if err == nil {
+		audit.Results = &results
+	} else if err.Error() != pgx.ErrNoRows.Error() {
+		return models.ProposalAudit{}, err
+	}
+
+	if p.Is_private {
+		key, err := h.revealProposalKey(p)
+		if err != nil {
+			return models.ProposalAudit{}, err
+		}
+		audit.Ballot_key = &key
+	}
+
+	return audit, nil
+}
+
+// Returns the verification report of a proposal's audit. The report is
+// only given once the proposal's results are stored, as until then the
+// tally can still change, and is verified a single time and stored, so
+// the signatures of every vote are not re-checked on chain for each
+// request.
+func (h *Helpers) getAuditReport(audit *models.ProposalAudit) (*models.AuditReport, error) {
+	if audit.Results == nil {
+		return nil, nil
+	}
+
+	p := audit.Proposal
+	report, err := models.GetAuditReport(h.A.DB, p.ID)
+	if err == nil {
+		return report, nil
+	}
+	if err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+
+	verified, err := VerifyAudit(h.A.FlowAdapter, audit)
+	if err != nil {
+		return nil, err
+	}
+	if err := verified.CreateAuditReport(h.A.DB, p.ID); err != nil {
+		log.Error().Err(err).Msgf("Error storing audit report for proposal %d.", p.ID)
+	}
+
+	return &verified, nil
+}
+
+// Verifies an exported proposal audit. Each vote's composite signatures
+// are re-checked against the voter's account keys on chain, each signed
+// message is matched to the vote's ballot, and the proposal's strategy is
+// re-run over the exported votes and compared with the stored results.
+// Snapshot balances are taken from the export as is.
+func VerifyAudit(fa *shared.FlowAdapter, audit *models.ProposalAudit) (models.AuditReport, error) {
+	p := audit.Proposal
+	if p.Strategy == nil {
+		return models.AuditReport{}, errors.New("proposal has no strategy")
+	}
+
+	// the votes of a multi-strategy proposal are counted from balances
+	// and NFTs of each strategy that are not part of the export
+	if p.IsMultiStrategy() {
+		return models.AuditReport{}, errors.New("multi-strategy proposals cannot be verified from an export")
+	}
+
+	s := strategies.Lookup(*p.Strategy)
+	if s == nil {
+		return models.AuditReport{}, fmt.Errorf("strategy not found: %s", *p.Strategy)
+	}
+
+	report := models.AuditReport{
+		Invalid_signatures: []string{},
+		Invalid_ballots:    []string{},
+	}
+
+	for _, vote := range audit.Votes {
+		report.Votes_checked++
+
+		if !voteSignatureIsValid(fa, vote.Vote) {
+			report.Invalid_signatures = append(report.Invalid_signatures, vote.Addr)
+		}
+
+		// votes cast with a transaction voucher sign the encoded voucher,
+		// which does not decode to a vote message
+		decoded, err := hex.DecodeString(vote.Message)
+		if err == nil && strings.HasPrefix(string(decoded), fmt.Sprintf("%d:", p.ID)) {
+			if err := vote.MatchesSignedMessage(string(decoded), p); err != nil {
+				report.Invalid_ballots = append(report.Invalid_ballots, vote.Addr)
+			}
+		}
+	}
+
+	tally, err := tallyProposal(s, p, audit.Votes)
+	if err != nil {
+		return models.AuditReport{}, err
+	}
+	report.Tally = tally
+
+	if audit.Results != nil {
+		matches := tally.Matches(audit.Results)
+		report.Results_match = &matches
+	}
+
+	report.Verified = len(report.Invalid_signatures) == 0 &&
+		len(report.Invalid_ballots) == 0 &&
+		(report.Results_match == nil || *report.Results_match)
+
+	return report, nil
+}
+
+func voteSignatureIsValid(fa *shared.FlowAdapter, v models.Vote) bool {
+	if v.Composite_signatures == nil || len(*v.Composite_signatures) == 0 {
+		return false
+	}
+
+	if err := fa.ValidateSignature(v.Addr, v.Message, v.Composite_signatures, "USER"); err == nil {
+		return true
+	}
+	return fa.ValidateSignature(v.Addr, v.Message, v.Composite_signatures, "TRANSACTION") == nil
+}
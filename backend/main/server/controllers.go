@@ -39,31 +39,118 @@ func (a *App) upload(w http.ResponseWriter, r *http.Request) {
 func (a *App) getResultsForProposal(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	proposal, err := helpers.fetchProposal(vars, "proposalId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	votes, err := models.GetAllVotesForProposal(a.DB, proposal.ID, *proposal.Strategy)
+	if proposal.BallotsSealed() {
+		respondWithError(w, http.StatusForbidden, "Results are hidden until the proposal ends.")
+		return
+	}
+
+	results, err := helpers.getProposalResults(proposal)
 	if err != nil {
-		log.Error().Err(err).Msg("Error getting votes for proposal.")
+		log.Error().Err(err).Msg("Error getting proposal results.")
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	results, err := helpers.useStrategyTally(proposal, votes)
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+func (a *App) getProposalKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	proposal, err := helpers.fetchProposal(vars, "proposalId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !proposal.Is_private {
+		respondWithError(w, http.StatusNotFound, "Proposal does not have private ballots.")
+		return
+	}
+	if proposal.BallotsSealed() {
+		respondWithError(w, http.StatusForbidden, "Proposal key is sealed until the proposal ends.")
+		return
+	}
+
+	key, err := helpers.revealProposalKey(proposal)
 	if err != nil {
-		log.Error().Err(err).Msg("Error tallying votes.")
+		log.Error().Err(err).Msg("Error revealing proposal key.")
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if *proposal.Computed_status == "closed" && !proposal.Achievements_done {
-		if err := models.AddWinningVoteAchievement(a.DB, votes, results); err != nil {
-			errMsg := "Error calculating winning votes"
-			log.Error().Err(err).Msg(errMsg)
-			respondWithError(w, http.StatusInternalServerError, errors.New(errMsg).Error())
-		}
+	respondWithJSON(w, http.StatusOK, key)
+}
 
+func (a *App) getProposalAudit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	proposal, err := helpers.fetchProposal(vars, "proposalId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, results)
+	if proposal.BallotsSealed() {
+		respondWithError(w, http.StatusForbidden, "Votes are sealed until the proposal ends.")
+		return
+	}
+
+	audit, err := helpers.exportProposalAudit(proposal)
+	if err != nil {
+		log.Error().Err(err).Msg("Error exporting proposal audit.")
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	report, err := helpers.getAuditReport(&audit)
+	if err != nil {
+		log.Error().Err(err).Msg("Error verifying proposal audit.")
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	audit.Report = report
+
+	respondWithJSON(w, http.StatusOK, audit)
+}
+
+func (a *App) exportVotesForProposal(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	proposal, err := helpers.fetchProposal(vars, "proposalId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	httpStatus, err := helpers.exportVotes(w, proposal, r.FormValue("format"))
+	if err != nil && httpStatus != http.StatusOK {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Error exporting votes for proposal %d.", proposal.ID)
+	}
+}
+
+func (a *App) getProposalTransitions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	proposal, err := helpers.fetchProposal(vars, "proposalId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transitions, err := models.GetProposalTransitions(a.DB, proposal.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting proposal transitions.")
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, transitions)
 }
 
 func (a *App) getVotesForProposal(w http.ResponseWriter, r *http.Request) {
@@ -83,7 +170,11 @@ func (a *App) getVotesForProposal(w http.ResponseWriter, r *http.Request) {
 
 func (a *App) getVoteForAddress(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	addr := vars["addr"]
+	addr, err := normalizeVoterAddr(vars["addr"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	proposal, err := helpers.fetchProposal(vars, "proposalId")
 	if err != nil {
@@ -142,6 +233,57 @@ func (a *App) createVoteForProposal(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusCreated, vote)
 }
 
+func (a *App) updateVoteForProposal(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	addr, err := normalizeVoterAddr(vars["addr"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	proposal, err := helpers.fetchProposal(vars, "proposalId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Proposal ID.")
+		return
+	}
+
+	vote, httpStatus, err := helpers.updateVote(r, proposal, addr)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, vote)
+}
+
+func (a *App) getVoteHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	addr, err := normalizeVoterAddr(vars["addr"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	proposal, err := helpers.fetchProposal(vars, "proposalId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Proposal ID.")
+		return
+	}
+
+	history, err := models.GetVoteHistory(a.DB, proposal.ID, addr)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := helpers.openBallotHistory(proposal, history); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, history)
+}
+
 // Proposals
 func (a *App) getProposalsForCommunity(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -155,6 +297,14 @@ func (a *App) getProposalsForCommunity(w http.ResponseWriter, r *http.Request) {
 	pageParams := getPageParams(*r, 25)
 	status := r.FormValue("status")
 
+	// drafts are only listed for the community's authors
+	if status == "draft" {
+		if httpStatus, err := helpers.authorizeRequest(r, communityId, "author"); err != nil {
+			respondWithError(w, httpStatus, err.Error())
+			return
+		}
+	}
+
 	proposals, totalRecords, err := models.GetProposalsForCommunity(
 		a.DB,
 		communityId,
@@ -180,6 +330,16 @@ func (a *App) getProposal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// drafts are not snapshotted until they are published
+	if p.IsDraft() {
+		if httpStatus, err := helpers.authorizeDraftRead(r, p); err != nil {
+			respondWithError(w, httpStatus, err.Error())
+			return
+		}
+		respondWithJSON(w, http.StatusOK, p)
+		return
+	}
+
 	c, httpStatus, err := helpers.fetchCommunity(p.Community_id)
 	if err != nil {
 		respondWithError(w, httpStatus, err.Error())
@@ -238,33 +398,41 @@ func (a *App) updateProposal(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-
-	// Check that status update is valid
-	// For now we are assuming proposals are creating with status 'published' and may be cancelled.
-	if payload.Status != "cancelled" {
-		respondWithError(w, http.StatusBadRequest, "You may only change a proposal's status to 'cancelled'.")
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	if payload.Voucher != nil {
-		if err := helpers.validateUserWithRoleViaVoucher(
-			payload.Signing_addr,
-			payload.Voucher,
-			p.Community_id,
-			"author"); err != nil {
+	// Drafts are published by their creator or an author. Published
+	// proposals may only be cancelled.
+	if p.IsDraft() && payload.Status == models.ProposalPublished {
+		if err := helpers.validateProposalEditor(p, payload.TimestampSignaturePayload); err != nil {
 			respondWithError(w, http.StatusForbidden, err.Error())
 			return
 		}
-	} else {
-		if err := helpers.validateUserWithRole(
-			payload.Signing_addr,
-			payload.Timestamp,
-			payload.Composite_signatures,
-			p.Community_id,
-			"author"); err != nil {
-			respondWithError(w, http.StatusForbidden, err.Error())
+
+		proposal, httpStatus, err := helpers.publishProposal(p)
+		if err != nil {
+			respondWithError(w, httpStatus, err.Error())
 			return
 		}
+
+		respondWithJSON(w, http.StatusOK, proposal)
+		return
+	}
+
+	if payload.Status != "cancelled" {
+		respondWithError(w, http.StatusBadRequest, "You may only change a proposal's status to 'cancelled'.")
+		return
+	}
+
+	if err := helpers.validateSignerWithRole(
+		payload.TimestampSignaturePayload,
+		payload.Voucher,
+		p.Community_id,
+		"author"); err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
 	}
 
 	p.Status = &payload.Status
@@ -282,6 +450,77 @@ func (a *App) updateProposal(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, p)
 }
 
+func (a *App) editProposal(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	p, err := helpers.fetchProposal(vars, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Proposal ID.")
+		return
+	}
+
+	var payload models.EditProposalPayload
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	proposal, httpStatus, err := helpers.editProposal(p, payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, proposal)
+}
+
+func (a *App) getProposalRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	p, err := helpers.fetchProposal(vars, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Proposal ID.")
+		return
+	}
+
+	revisions, err := models.GetProposalRevisions(a.DB, p.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
+
+func (a *App) updateDraftProposal(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	p, err := helpers.fetchProposal(vars, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Proposal ID.")
+		return
+	}
+
+	var payload models.DraftProposalPayload
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	proposal, httpStatus, err := helpers.updateDraft(p, payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, proposal)
+}
+
 // Communities
 func (a *App) getCommunities(w http.ResponseWriter, r *http.Request) {
 	pageParams := getPageParams(*r, 25)
@@ -353,6 +592,15 @@ func (a *App) createCommunity(w http.ResponseWriter, r *http.Request) {
 
 	//Validate Strategies & Proposal Thresholds
 	if payload.Strategies != nil {
+		if err := validateStrategies(*payload.Strategies); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// new communities can only use the custom scripts bundled with the server
+		if err := helpers.validateStrategyContracts(0, *payload.Strategies); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		err = validateContractThreshold(*payload.Strategies)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, err.Error())
@@ -390,9 +638,21 @@ func (a *App) updateCommunity(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
 
 	//Validate Strategies & Proposal Thresholds
 	if payload.Strategies != nil {
+		if err := validateStrategies(*payload.Strategies); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := helpers.validateStrategyContracts(id, *payload.Strategies); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		err = validateContractThreshold(*payload.Strategies)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, err.Error())
@@ -419,7 +679,7 @@ func (a *App) updateCommunity(w http.ResponseWriter, r *http.Request) {
 
 // Voting Strategies
 func (a *App) getVotingStrategies(w http.ResponseWriter, r *http.Request) {
-	vs, err := models.GetVotingStrategies(a.DB)
+	vs := models.GetVotingStrategies()
 
 	// Add custom scripts for the custom-script strategy
 	for _, strategy := range vs {
@@ -428,10 +688,6 @@ func (a *App) getVotingStrategies(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
 	respondWithJSON(w, http.StatusOK, vs)
 }
 
@@ -517,6 +773,10 @@ func (a *App) createListForCommunity(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
 
 	l, httpStatus, err := helpers.createListForCommunity(payload)
 	if err != nil {
@@ -540,6 +800,10 @@ func (a *App) addAddressesToList(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
 
 	httpStatus, err := helpers.updateAddressesInList(id, payload, "add")
 	if err != nil {
@@ -563,6 +827,10 @@ func (a *App) removeAddressesFromList(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
 
 	httpStatus, err := helpers.updateAddressesInList(id, payload, "remove")
 	if err != nil {
@@ -611,6 +879,41 @@ func (a *App) getCommunityBlocklist(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, a.CommunityBlocklist.Addresses)
 }
 
+// Lists the community's votes and proposals whose IPFS pins are pending
+// or failed.
+func (a *App) getStuckPins(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	if httpStatus, err := helpers.authorizeRequest(r, communityId, "admin"); err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	status := r.FormValue("status")
+	if status != "" && status != models.PinPending && status != models.PinFailed {
+		respondWithError(w, http.StatusBadRequest, "Invalid status, expected pending or failed.")
+		return
+	}
+
+	pageParams := getPageParams(*r, 100)
+
+	jobs, totalRecords, err := models.GetStuckPinJobs(a.DB, communityId, status, pageParams)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	pageParams.TotalRecords = totalRecords
+
+	response := shared.GetPaginatedResponseWithPayload(jobs, pageParams)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
 func (a *App) getLatestSnapshot(w http.ResponseWriter, r *http.Request) {
 	snapshot, err := a.SnapshotClient.GetLatestFlowSnapshot()
 	if err != nil {
@@ -661,6 +964,10 @@ func (a *App) createCommunityUser(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
 
 	httpStatus, err := helpers.createCommunityUser(payload)
 	if err != nil {
@@ -790,6 +1097,10 @@ func (a *App) removeUserRole(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
 
 	httpStatus, err := helpers.removeUserRole(payload)
 	if err != nil {
@@ -800,6 +1111,432 @@ func (a *App) removeUserRole(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, "OK")
 }
 
+func (a *App) exportProposalsForCommunity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	if _, httpStatus, err := helpers.fetchCommunity(communityId); err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	httpStatus, err := helpers.exportProposals(w, communityId, r.FormValue("format"))
+	if err != nil && httpStatus != http.StatusOK {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Error exporting proposals for community %d.", communityId)
+	}
+}
+
+// Webhooks
+func (a *App) getWebhooksForCommunity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	if httpStatus, err := helpers.authorizeRequest(r, communityId, "admin"); err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	webhooks, err := models.GetWebhooksForCommunity(a.DB, communityId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+
+	respondWithJSON(w, http.StatusOK, webhooks)
+}
+
+func (a *App) createWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	payload := models.WebhookPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	payload.Community_id = communityId
+
+	webhook, httpStatus, err := helpers.createWebhook(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, webhook)
+}
+
+func (a *App) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Webhook ID.")
+		return
+	}
+
+	payload := models.WebhookPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	payload.ID = id
+	payload.Community_id = communityId
+
+	webhook, httpStatus, err := helpers.updateWebhook(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, webhook)
+}
+
+func (a *App) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Webhook ID.")
+		return
+	}
+
+	payload := models.WebhookPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	payload.ID = id
+	payload.Community_id = communityId
+
+	httpStatus, err := helpers.deleteWebhook(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, "OK")
+}
+
+func (a *App) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Webhook ID.")
+		return
+	}
+
+	if httpStatus, err := helpers.authorizeRequest(r, communityId, "admin"); err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	webhook, httpStatus, err := helpers.fetchWebhook(id, communityId)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	pageParams := getPageParams(*r, 100)
+
+	deliveries, totalRecords, err := models.GetDeliveriesForWebhook(a.DB, webhook.ID, pageParams)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	pageParams.TotalRecords = totalRecords
+
+	response := shared.GetPaginatedResponseWithPayload(deliveries, pageParams)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// Community Scripts
+func (a *App) getCommunityScripts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	scripts, err := models.GetCommunityScripts(a.DB, communityId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, scripts)
+}
+
+func (a *App) createCommunityScript(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 2*models.MaxCommunityScriptSize)
+	payload := models.CommunityScriptPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	payload.Community_id = communityId
+
+	script, httpStatus, err := helpers.createCommunityScript(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, script)
+}
+
+func (a *App) updateCommunityScript(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 2*models.MaxCommunityScriptSize)
+	payload := models.CommunityScriptPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	payload.Community_id = communityId
+	payload.Key = vars["key"]
+
+	script, httpStatus, err := helpers.updateCommunityScript(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, script)
+}
+
+func (a *App) deleteCommunityScript(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	payload := models.CommunityScriptPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := helpers.bindSession(r, &payload.TimestampSignaturePayload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	payload.Community_id = communityId
+	payload.Key = vars["key"]
+
+	httpStatus, err := helpers.deleteCommunityScript(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, "OK")
+}
+
+// Delegations
+func (a *App) createDelegation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+
+	payload := models.Delegation{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	payload.Community_id = communityId
+
+	delegation, httpStatus, err := helpers.createDelegation(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, delegation)
+}
+
+func (a *App) revokeDelegation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	communityId, err := strconv.Atoi(vars["communityId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Community ID.")
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid Delegation ID.")
+		return
+	}
+
+	payload := models.RevokeDelegationPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	payload.ID = id
+	payload.Community_id = communityId
+
+	delegation, httpStatus, err := helpers.revokeDelegation(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, delegation)
+}
+
+func (a *App) getDelegationsToAddress(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	addr, err := normalizeVoterAddr(vars["addr"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	delegations, err := models.GetDelegationsToAddress(a.DB, addr)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, delegations)
+}
+
+func (a *App) getDelegationsFromAddress(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	addr, err := normalizeVoterAddr(vars["addr"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	delegations, err := models.GetDelegationsFromAddress(a.DB, addr)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, delegations)
+}
+
+// Sessions
+func (a *App) createAuthNonce(w http.ResponseWriter, r *http.Request) {
+	nonce, httpStatus, err := helpers.createAuthNonce()
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, nonce)
+}
+
+func (a *App) login(w http.ResponseWriter, r *http.Request) {
+	payload := models.LoginPayload{}
+	if err := validatePayload(r.Body, &payload); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	session, httpStatus, err := helpers.login(payload)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, session)
+}
+
+func (a *App) logout(w http.ResponseWriter, r *http.Request) {
+	httpStatus, err := helpers.logout(r, false)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, "OK")
+}
+
+func (a *App) revokeSessions(w http.ResponseWriter, r *http.Request) {
+	httpStatus, err := helpers.logout(r, true)
+	if err != nil {
+		respondWithError(w, httpStatus, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, "OK")
+}
+
 /////////////
 // HELPERS //
 /////////////
@@ -0,0 +1,354 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+)
+
+const exportBatchSize = 500
+
+const (
+	exportCSV    = "csv"
+	exportNDJSON = "ndjson"
+)
+
+// Streams records as CSV rows or newline delimited JSON, flushing the
+// response after each batch so large exports are not held in memory.
+type exportWriter struct {
+	w      http.ResponseWriter
+	format string
+	csv    *csv.Writer
+	json   *json.Encoder
+}
+
+func newExportWriter(w http.ResponseWriter, format, filename string, header []string) (*exportWriter, error) {
+	if format == "" {
+		format = exportCSV
+	}
+
+	e := &exportWriter{w: w, format: format}
+	switch format {
+	case exportCSV:
+		w.Header().Set("Content-Type", "text/csv")
+		e.csv = csv.NewWriter(w)
+	case exportNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		e.json = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("Invalid export format: %s.", format)
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", filename, format))
+	w.WriteHeader(http.StatusOK)
+
+	if e.csv != nil {
+		if err := e.csv.Write(header); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// Writes a record, as the CSV row or as the JSON encoding of record.
+func (e *exportWriter) Write(row []string, record interface{}) error {
+	if e.csv != nil {
+		for i, cell := range row {
+			row[i] = escapeFormula(cell)
+		}
+		return e.csv.Write(row)
+	}
+	return e.json.Encode(record)
+}
+
+// Prefixes cells that a spreadsheet would read as a formula with a
+// quote, as names, choices and ballots are written by users.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func (e *exportWriter) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+type voteExport struct {
+	Addr                    string              `json:"addr"`
+	Choice                  string              `json:"choice"`
+	Choices                 *[]string           `json:"choices,omitempty"`
+	Allocations             *map[string]float64 `json:"allocations,omitempty"`
+	Weight                  float64             `json:"weight"`
+	PrimaryAccountBalance   *uint64             `json:"primaryAccountBalance"`
+	SecondaryAccountBalance *uint64             `json:"secondaryAccountBalance"`
+	StakingBalance          *uint64             `json:"stakingBalance"`
+	BlockHeight             *uint64             `json:"blockHeight"`
+	Delegators              []string            `json:"delegators,omitempty"`
+	Created_at              time.Time           `json:"createdAt"`
+	Updated_at              *time.Time          `json:"updatedAt,omitempty"`
+	Cid                     *string             `json:"cid"`
+}
+
+var voteExportHeader = []string{
+	"addr", "choice", "ballot", "weight", "primary_account_balance", "secondary_account_balance",
+	"staking_balance", "block_height", "delegators", "created_at", "updated_at", "cid",
+}
+
+func (v voteExport) row() []string {
+	return []string{
+		v.Addr,
+		v.Choice,
+		formatBallot(v.Choices, v.Allocations),
+		strconv.FormatFloat(v.Weight, 'f', -1, 64),
+		formatUint(v.PrimaryAccountBalance),
+		formatUint(v.SecondaryAccountBalance),
+		formatUint(v.StakingBalance),
+		formatUint(v.BlockHeight),
+		strings.Join(v.Delegators, ";"),
+		v.Created_at.Format(time.RFC3339),
+		formatTime(v.Updated_at),
+		formatString(v.Cid),
+	}
+}
+
+// Streams every vote on the proposal with its weight under the
+// proposal's strategy.
+//
+// Errors before the export starts are returned with their http status.
+// Once the export has started streaming the status is 200 and errors can
+// only end the response early.
+func (h *Helpers) exportVotes(w http.ResponseWriter, p models.Proposal, format string) (int, error) {
+	if p.BallotsSealed() {
+		return http.StatusForbidden, errors.New("Votes are sealed until the proposal ends.")
+	}
+
+	filename := fmt.Sprintf("proposal-%d-votes", p.ID)
+	e, err := newExportWriter(w, format, filename, voteExportHeader)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	afterId := 0
+	for {
+		votes, err := models.GetVotesForProposalAfter(h.A.DB, p.ID, *p.Strategy, afterId, exportBatchSize)
+		if err != nil {
+			return http.StatusOK, err
+		}
+		if len(votes) == 0 {
+			break
+		}
+
+		if err := h.fetchDelegatedBalances(p, votes); err != nil {
+			return http.StatusOK, err
+		}
+		if err := h.openBallots(p, votes); err != nil {
+			return http.StatusOK, err
+		}
+
+		for _, vote := range votes {
+			weight, err := h.useStrategyGetVoteWeight(p, vote)
+			if err != nil {
+				return http.StatusOK, err
+			}
+
+			record := voteExport{
+				Addr:                    vote.Addr,
+				Choice:                  vote.Choice,
+				Choices:                 vote.Choices,
+				Allocations:             vote.Allocations,
+				Weight:                  weight,
+				PrimaryAccountBalance:   vote.PrimaryAccountBalance,
+				SecondaryAccountBalance: vote.SecondaryAccountBalance,
+				StakingBalance:          vote.StakingBalance,
+				BlockHeight:             vote.BlockHeight,
+				Created_at:              vote.Created_at,
+				Updated_at:              vote.Updated_at,
+				Cid:                     vote.Cid,
+			}
+			for _, d := range vote.Delegations {
+				record.Delegators = append(record.Delegators, d.Addr)
+			}
+
+			if err := e.Write(record.row(), record); err != nil {
+				return http.StatusOK, err
+			}
+		}
+
+		if err := e.Flush(); err != nil {
+			return http.StatusOK, err
+		}
+		afterId = votes[len(votes)-1].ID
+	}
+
+	return http.StatusOK, e.Flush()
+}
+
+type proposalExport struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	Status         *string            `json:"status"`
+	Strategy       *string            `json:"strategy"`
+	Voting_type    *string            `json:"votingType"`
+	Creator_addr   string             `json:"creatorAddr"`
+	Start_time     time.Time          `json:"startTime"`
+	End_time       time.Time          `json:"endTime"`
+	Block_height   *uint64            `json:"blockHeight"`
+	Total_votes    int                `json:"totalVotes"`
+	Cid            *string            `json:"cid"`
+	Outcome        *string            `json:"outcome,omitempty"`
+	Winning_choice *string            `json:"winningChoice,omitempty"`
+	Turnout        *float64           `json:"turnout,omitempty"`
+	Results        map[string]float64 `json:"results,omitempty"`
+	Results_cid    *string            `json:"resultsCid,omitempty"`
+}
+
+var proposalExportHeader = []string{
+	"id", "name", "status", "strategy", "voting_type", "creator_addr", "start_time", "end_time",
+	"block_height", "total_votes", "cid", "outcome", "winning_choice", "turnout", "results", "results_cid",
+}
+
+func (p proposalExport) row() []string {
+	var turnout string
+	if p.Turnout != nil {
+		turnout = strconv.FormatFloat(*p.Turnout, 'f', -1, 64)
+	}
+
+	return []string{
+		strconv.Itoa(p.ID),
+		p.Name,
+		formatString(p.Status),
+		formatString(p.Strategy),
+		formatString(p.Voting_type),
+		p.Creator_addr,
+		p.Start_time.Format(time.RFC3339),
+		p.End_time.Format(time.RFC3339),
+		formatUint(p.Block_height),
+		strconv.Itoa(p.Total_votes),
+		formatString(p.Cid),
+		formatString(p.Outcome),
+		formatString(p.Winning_choice),
+		turnout,
+		formatResults(p.Results),
+		formatString(p.Results_cid),
+	}
+}
+
+// Streams every proposal of the community with its final results.
+func (h *Helpers) exportProposals(w http.ResponseWriter, communityId int, format string) (int, error) {
+	filename := fmt.Sprintf("community-%d-proposals", communityId)
+	e, err := newExportWriter(w, format, filename, proposalExportHeader)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	afterId := 0
+	for {
+		proposals, err := models.GetProposalsWithResultsAfter(h.A.DB, communityId, afterId, exportBatchSize)
+		if err != nil {
+			return http.StatusOK, err
+		}
+		if len(proposals) == 0 {
+			break
+		}
+
+		for _, p := range proposals {
+			record := proposalExport{
+				ID:           p.ID,
+				Name:         p.Name,
+				Status:       p.Computed_status,
+				Strategy:     p.Strategy,
+				Voting_type:  p.Voting_type,
+				Creator_addr: p.Creator_addr,
+				Start_time:   p.Start_time,
+				End_time:     p.End_time,
+				Block_height: p.Block_height,
+				Total_votes:  p.Total_votes,
+				Cid:          p.Cid,
+			}
+			if r := p.Tally; r != nil {
+				record.Outcome = r.Outcome
+				record.Winning_choice = r.Winning_choice
+				record.Turnout = &r.Turnout
+				record.Results = r.Results_float
+				record.Results_cid = r.Cid
+			}
+
+			if err := e.Write(record.row(), record); err != nil {
+				return http.StatusOK, err
+			}
+		}
+
+		if err := e.Flush(); err != nil {
+			return http.StatusOK, err
+		}
+		afterId = proposals[len(proposals)-1].ID
+	}
+
+	return http.StatusOK, e.Flush()
+}
+
+// Formats a ranked or approval ballot as "a;b" and a weighted
+// ballot as "a=60;b=40".
+func formatBallot(choices *[]string, allocations *map[string]float64) string {
+	if allocations != nil {
+		return formatResults(*allocations)
+	}
+	if choices != nil {
+		return strings.Join(*choices, ";")
+	}
+	return ""
+}
+
+func formatResults(results map[string]float64) string {
+	keys := make([]string, 0, len(results))
+	for k := range results {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%s", k, strconv.FormatFloat(results[k], 'f', -1, 64))
+	}
+	return strings.Join(parts, ";")
+}
+
+func formatUint(n *uint64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatUint(*n, 10)
+}
+
+func formatString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/DapperCollectives/CAST/backend/main/models"
 	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/DapperCollectives/CAST/backend/main/strategies"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v4"
 	"github.com/rs/zerolog/log"
@@ -37,25 +38,206 @@ func (h *Helpers) useStrategyTally(
 	v []*models.VoteWithBalance,
 ) (models.ProposalResults, error) {
 
+	if p.IsMultiStrategy() {
+		return h.tallyMultiStrategy(p)
+	}
+
 	s := h.initStrategy(*p.Strategy)
 	if s == nil {
 		return models.ProposalResults{}, errors.New("Strategy not found.")
 	}
 
-	proposalInitialized := models.NewProposalResults(p.ID, p.Choices)
-	results, err := s.TallyVotes(v, proposalInitialized, &p)
+	return tallyProposal(s, p, v)
+}
+
+// Tallies a multi-strategy proposal with each of its strategies over
+// the votes and balances that strategy counts, then combines the tallies.
+func (h *Helpers) tallyMultiStrategy(p models.Proposal) (models.ProposalResults, error) {
+	tallies := map[string]*models.ProposalResults{}
+
+	for _, name := range p.StrategyNames() {
+		s := h.initStrategy(name)
+		if s == nil {
+			return models.ProposalResults{}, fmt.Errorf("Strategy not found: %s.", name)
+		}
+
+		sp := p.ForStrategy(name)
+		votes, err := models.GetAllVotesForProposal(h.A.DB, p.ID, name)
+		if err != nil {
+			return models.ProposalResults{}, err
+		}
+		if err := h.openBallots(sp, votes); err != nil {
+			return models.ProposalResults{}, err
+		}
+		if err := h.fetchDelegatedBalances(sp, votes); err != nil {
+			return models.ProposalResults{}, err
+		}
+
+		tally, err := tallyProposal(s, sp, votes)
+		if err != nil {
+			return models.ProposalResults{}, err
+		}
+		tallies[name] = &tally
+	}
+
+	return models.CombineStrategyResults(&p, tallies), nil
+}
+
+// Tallies the votes with the strategy according to the proposal's voting
+// type, and computes the outcome from the turnout.
+func tallyProposal(
+	s Strategy,
+	p models.Proposal,
+	v []*models.VoteWithBalance,
+) (models.ProposalResults, error) {
+
+	var results models.ProposalResults
+	var err error
+
+	switch {
+	case p.IsRankedChoice():
+		results, err = strategies.TallyRankedChoice(s, v, &p)
+	case p.IsApproval():
+		results, err = strategies.TallyApproval(s, v, &p)
+	default:
+		proposalInitialized := models.NewProposalResults(p.ID, p.Choices)
+		results, err = s.TallyVotes(v, proposalInitialized, &p)
+	}
 	if err != nil {
 		return models.ProposalResults{}, err
 	}
 
+	var turnout float64
+	for _, vote := range v {
+		weight, err := s.GetVoteWeightForBalance(vote, &p)
+		if err != nil {
+			return models.ProposalResults{}, err
+		}
+		turnout += weight
+	}
+	results.ComputeOutcome(&p, turnout)
+
 	return results, nil
 }
 
+// Returns the results of a proposal. Once a proposal is closed its
+// results are tallied a single time, pinned to IPFS and stored, and all
+// later reads are served from the stored results.
+func (h *Helpers) getProposalResults(p models.Proposal) (models.ProposalResults, error) {
+	isClosed := p.Computed_status != nil && *p.Computed_status == "closed"
+
+	if isClosed {
+		results := models.ProposalResults{Proposal_id: p.ID}
+		err := results.GetLatestProposalResultsById(h.A.DB)
+		if err == nil {
+			return results, nil
+		}
+		if err.Error() != pgx.ErrNoRows.Error() {
+			log.Error().Err(err).Msg("Error getting final proposal results.")
+			return models.ProposalResults{}, err
+		}
+	}
+
+	if p.BallotsSealed() {
+		return models.ProposalResults{}, errors.New("Results are hidden until the proposal ends.")
+	}
+
+	votes, err := models.GetAllVotesForProposal(h.A.DB, p.ID, *p.Strategy)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting votes for proposal.")
+		return models.ProposalResults{}, err
+	}
+
+	if err := h.openBallots(p, votes); err != nil {
+		return models.ProposalResults{}, err
+	}
+
+	if err := h.fetchDelegatedBalances(p, votes); err != nil {
+		return models.ProposalResults{}, err
+	}
+
+	results, err := h.useStrategyTally(p, votes)
+	if err != nil {
+		log.Error().Err(err).Msg("Error tallying votes.")
+		return models.ProposalResults{}, err
+	}
+
+	if !isClosed {
+		return results, nil
+	}
+
+	if p.Is_private {
+		if _, err := h.revealProposalKey(p); err != nil {
+			log.Error().Err(err).Msg("Error revealing proposal key.")
+			return models.ProposalResults{}, err
+		}
+	}
+
+	if !p.Achievements_done {
+		if err := models.AddWinningVoteAchievement(h.A.DB, votes, results); err != nil {
+			errMsg := "Error calculating winning votes"
+			log.Error().Err(err).Msg(errMsg)
+			return models.ProposalResults{}, errors.New(errMsg)
+		}
+		results.Achievements_done = true
+	}
+
+	return h.finalizeProposalResults(results, p.Community_id)
+}
+
+// Stores the final results before pinning them, so a failed pin does
+// not fail the request. The pin is queued and the results' cid is set
+// once it is pinned.
+func (h *Helpers) finalizeProposalResults(results models.ProposalResults, communityId int) (models.ProposalResults, error) {
+	results.Updated_at = time.Now().UTC()
+
+	cid, pinJob, err := h.pinOrQueue(results)
+	if err != nil {
+		errMsg := "Error pinning JSON to IPFS."
+		log.Error().Err(err).Msg(errMsg)
+		return models.ProposalResults{}, errors.New(errMsg)
+	}
+	results.Cid = cid
+	if pinJob != nil {
+		results.Pin_status = models.PinPending
+	}
+
+	created, err := results.CreateProposalResults(h.A.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("Error storing final proposal results.")
+		return models.ProposalResults{}, err
+	}
+	if created && pinJob != nil {
+		h.queuePinJob(pinJob, communityId, models.PinRecordProposalResults, results.Proposal_id)
+	}
+
+	// Another request may have finalized the proposal first,
+	// so always serve the stored row.
+	final := models.ProposalResults{Proposal_id: results.Proposal_id}
+	if err := final.GetLatestProposalResultsById(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error getting final proposal results.")
+		return models.ProposalResults{}, err
+	}
+
+	return final, nil
+}
+
 func (h *Helpers) useStrategyGetVotes(
 	p models.Proposal,
 	v []*models.VoteWithBalance,
 ) ([]*models.VoteWithBalance, error) {
 
+	if p.IsMultiStrategy() {
+		for _, vote := range v {
+			weight, err := h.useStrategyGetVoteWeight(p, vote)
+			if err != nil {
+				return nil, err
+			}
+			vote.Weight = &weight
+		}
+		return v, nil
+	}
+
 	s := h.initStrategy(*p.Strategy)
 	if s == nil {
 		return nil, errors.New("Strategy not found.")
@@ -73,7 +255,21 @@ func (h *Helpers) useStrategyGetVoteWeight(
 	p models.Proposal,
 	v *models.VoteWithBalance,
 ) (float64, error) {
-	s := strategyMap[*p.Strategy]
+	// the weight of a vote on a multi-strategy proposal is the sum of
+	// its weights under each strategy, each in that strategy's units
+	if p.IsMultiStrategy() {
+		var total float64
+		for _, name := range p.StrategyNames() {
+			weight, err := h.useStrategyGetVoteWeight(p.ForStrategy(name), v)
+			if err != nil {
+				return 0, err
+			}
+			total += weight
+		}
+		return total, nil
+	}
+
+	s := h.initStrategy(*p.Strategy)
 	if s == nil {
 		return 0, errors.New("Strategy not found.")
 	}
@@ -116,6 +312,190 @@ func (h *Helpers) useStrategyFetchBalance(
 	return vb, nil
 }
 
+// Fetches the voter's balance with each strategy of the proposal. On a
+// multi-strategy proposal the balance of the primary strategy is returned,
+// as the others store what they count themselves.
+func (h *Helpers) useStrategiesFetchBalance(v models.Vote, p models.Proposal) (models.VoteWithBalance, error) {
+	var vb models.VoteWithBalance
+	for _, name := range p.StrategyNames() {
+		s := h.initStrategy(name)
+		if s == nil {
+			return models.VoteWithBalance{}, errors.New("Proposal strategy not found.")
+		}
+
+		sp := p
+		if p.IsMultiStrategy() {
+			sp = p.ForStrategy(name)
+		}
+		balance, err := h.useStrategyFetchBalance(v, sp, s)
+		if err != nil {
+			return models.VoteWithBalance{}, err
+		}
+		if name == *p.Strategy {
+			vb = balance
+		}
+	}
+
+	return vb, nil
+}
+
+// Fetches the snapshot balances of delegators that have none stored for
+// the proposal, as balances are otherwise only fetched when a vote is cast.
+func (h *Helpers) fetchDelegatedBalances(p models.Proposal, votes []*models.VoteWithBalance) error {
+	s := h.initStrategy(*p.Strategy)
+	if s == nil || !models.RequiresSnapshot(*p.Strategy) {
+		return nil
+	}
+
+	for _, vote := range votes {
+		for _, d := range vote.Delegations {
+			if d.PrimaryAccountBalance != nil {
+				continue
+			}
+
+			vb, err := h.useStrategyFetchBalance(models.Vote{Addr: d.Addr}, p, s)
+			if err != nil {
+				log.Error().Err(err).Msgf("Error fetching delegated balance for address %v.", d.Addr)
+				return err
+			}
+			d.PrimaryAccountBalance = vb.PrimaryAccountBalance
+			d.SecondaryAccountBalance = vb.SecondaryAccountBalance
+			d.StakingBalance = vb.StakingBalance
+		}
+	}
+
+	return nil
+}
+
+// Fetches and stores the balances delegated to an address on a proposal
+// when the address votes or is delegated to, so later reads of the
+// proposal's votes and results find them in the balances table rather
+// than calling the snapshot service. A balance that fails to be fetched
+// here is fetched again when it is read.
+func (h *Helpers) cacheDelegatedBalances(p models.Proposal, delegate string) {
+	for _, name := range p.StrategyNames() {
+		sp := p
+		if p.IsMultiStrategy() {
+			sp = p.ForStrategy(name)
+		}
+
+		delegations, err := models.GetDelegatedBalances(h.A.DB, p.ID, name, delegate)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error getting delegations to %s on proposal %d.", delegate, p.ID)
+			return
+		}
+		if len(delegations) == 0 {
+			continue
+		}
+
+		vote := &models.VoteWithBalance{Vote: models.Vote{Addr: delegate}, Delegations: delegations}
+		if err := h.fetchDelegatedBalances(sp, []*models.VoteWithBalance{vote}); err != nil {
+			log.Error().Err(err).Msgf("Error caching balances delegated to %s on proposal %d.", delegate, p.ID)
+		}
+	}
+}
+
+// Opens the sealed ballots of a private proposal once it has ended.
+// Ballots stay sealed while the proposal is live, and when they were
+// cast is hidden.
+func (h *Helpers) openBallots(p models.Proposal, votes []*models.VoteWithBalance) error {
+	if !p.Is_private || len(votes) == 0 {
+		return nil
+	}
+	if p.BallotsSealed() {
+		for _, vote := range votes {
+			vote.HideCastTime()
+		}
+		return nil
+	}
+
+	key := models.ProposalKey{Proposal_id: p.ID}
+	if err := key.GetProposalKey(h.A.DB); err != nil {
+		log.Error().Err(err).Msgf("Error getting key for proposal %d.", p.ID)
+		return err
+	}
+
+	for _, vote := range votes {
+		if err := vote.OpenBallot(key.Key); err != nil {
+			log.Error().Err(err).Msgf("Error opening ballot for proposal %d.", p.ID)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Opens the sealed ballots in the vote history of a private proposal
+// once it has ended, as openBallots does for votes.
+func (h *Helpers) openBallotHistory(p models.Proposal, history []*models.VoteHistory) error {
+	if !p.Is_private || len(history) == 0 {
+		return nil
+	}
+	if p.BallotsSealed() {
+		for _, entry := range history {
+			entry.HideCastTime()
+		}
+		return nil
+	}
+
+	key := models.ProposalKey{Proposal_id: p.ID}
+	if err := key.GetProposalKey(h.A.DB); err != nil {
+		log.Error().Err(err).Msgf("Error getting key for proposal %d.", p.ID)
+		return err
+	}
+
+	for _, entry := range history {
+		if err := entry.OpenBallot(key.Key); err != nil {
+			log.Error().Err(err).Msgf("Error opening ballot history for proposal %d.", p.ID)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Publishes the key of a private proposal that has ended, pinning it to
+// IPFS so the sealed ballots pinned with each vote can be opened by anyone.
+// The key is revealed even if pinning fails, in which case the pin is
+// queued and the key's cid is set once it is pinned.
+func (h *Helpers) revealProposalKey(p models.Proposal) (models.ProposalKey, error) {
+	if p.BallotsSealed() {
+		return models.ProposalKey{}, errors.New("Proposal key is sealed until the proposal ends.")
+	}
+
+	key := models.ProposalKey{Proposal_id: p.ID}
+	if err := key.GetProposalKey(h.A.DB); err != nil {
+		return models.ProposalKey{}, err
+	}
+	if key.Revealed_at != nil {
+		return key, nil
+	}
+
+	cid, pinJob, err := h.pinOrQueue(map[string]interface{}{
+		"proposalId": p.ID,
+		"algorithm":  models.BallotSealAlgorithm,
+		"key":        key.Key,
+	})
+	if err != nil {
+		return models.ProposalKey{}, err
+	}
+	key.Cid = cid
+	key.Pin_status = models.PinPinned
+	if pinJob != nil {
+		key.Pin_status = models.PinPending
+	}
+
+	revealed, err := key.RevealProposalKey(h.A.DB)
+	if err != nil {
+		return models.ProposalKey{}, err
+	}
+	if revealed && pinJob != nil {
+		h.queuePinJob(pinJob, p.Community_id, models.PinRecordProposalKey, p.ID)
+	}
+
+	return key, nil
+}
+
 func (h *Helpers) fetchProposal(vars map[string]string, query string) (models.Proposal, error) {
 	proposalId, err := strconv.Atoi(vars[query])
 	if err != nil {
@@ -155,7 +535,7 @@ func (h *Helpers) uploadFile(r *http.Request) (interface{}, error) {
 		return nil, errors.New(msg)
 	}
 
-	pin, err := h.A.IpfsClient.PinFile(file, handler.Filename)
+	pin, err := h.A.Storage.PinFile(file, handler.Filename)
 	if err != nil {
 		log.Error().Err(err).Msg("Error pinning file to IPFS.")
 		return nil, err
@@ -191,6 +571,14 @@ func (h *Helpers) getPaginatedVotes(
 		return nil, shared.PageParams{}, err
 	}
 
+	if err := h.fetchDelegatedBalances(p, votes); err != nil {
+		return nil, shared.PageParams{}, err
+	}
+
+	if err := h.openBallots(p, votes); err != nil {
+		return nil, shared.PageParams{}, err
+	}
+
 	pageParams.TotalRecords = totalRecords
 
 	return votes, pageParams, nil
@@ -202,6 +590,10 @@ func (h *Helpers) processVote(addr string, p models.Proposal) (*models.VoteWithB
 		return nil, err
 	}
 
+	if err := h.openBallots(p, []*models.VoteWithBalance{vote}); err != nil {
+		return nil, err
+	}
+
 	weight, err := h.useStrategyGetVoteWeight(p, vote)
 	if err != nil {
 		return nil, err
@@ -265,12 +657,11 @@ func (h *Helpers) processVotes(
 			}
 		}
 
-		s := strategyMap[*proposal.Strategy]
-		if s == nil {
-			return nil, pageParams, errors.New("Strategy not found.")
+		if err := h.openBallots(proposal, []*models.VoteWithBalance{vote}); err != nil {
+			return nil, pageParams, err
 		}
 
-		weight, err := s.GetVoteWeightForBalance(vote, &proposal)
+		weight, err := h.useStrategyGetVoteWeight(proposal, vote)
 		if err != nil {
 			return nil, pageParams, err
 		}
@@ -291,8 +682,15 @@ func (h *Helpers) createVote(r *http.Request, p models.Proposal) (*models.VoteWi
 		return nil, err
 	}
 
+	addr, err := normalizeVoterAddr(v.Addr)
+	if err != nil {
+		return nil, err
+	}
+	v.Addr = addr
 	v.Proposal_id = p.ID
 
+	v.SetPrimaryChoice(p)
+
 	// validate user hasn't already voted
 	existingVote := models.Vote{Proposal_id: v.Proposal_id, Addr: v.Addr}
 	if err := existingVote.GetVote(h.A.DB); err == nil {
@@ -314,12 +712,7 @@ func (h *Helpers) createVote(r *http.Request, p models.Proposal) (*models.VoteWi
 
 	v.Proposal_id = p.ID
 
-	s := h.initStrategy(*p.Strategy)
-	if s == nil {
-		return nil, errors.New("Proposal strategy not found.")
-	}
-
-	vb, err := h.useStrategyFetchBalance(v, p, s)
+	vb, err := h.useStrategiesFetchBalance(v, p)
 	if err != nil {
 		return nil, err
 	}
@@ -328,61 +721,190 @@ func (h *Helpers) createVote(r *http.Request, p models.Proposal) (*models.VoteWi
 		return nil, err
 	}
 
+	h.cacheDelegatedBalances(p, vb.Addr)
+
+	event := vb.Vote
+	event.HideCastTime()
+	go h.dispatchWebhookEvent(p.Community_id, models.VoteCastEvent, event)
+
 	return &vb, nil
 }
 
-func (h *Helpers) insertVote(v models.VoteWithBalance, p models.Proposal) error {
-	weight, err := h.useStrategyGetVoteWeight(p, &v)
+// Replaces an address's vote on a live proposal with a newly signed vote.
+// The replaced vote is kept in the vote history.
+func (h *Helpers) updateVote(r *http.Request, p models.Proposal, addr string) (*models.VoteWithBalance, int, error) {
+	var v models.Vote
+	if err := validatePayload(r.Body, &v); err != nil {
+		log.Error().Err(err).Msg("Invalid request payload.")
+		return nil, http.StatusBadRequest, err
+	}
+
+	voter, err := normalizeVoterAddr(v.Addr)
 	if err != nil {
-		msg := fmt.Sprintf("Error getting vote weight for address %s.", v.Addr)
-		log.Error().Err(err).Msg(msg)
-		return errors.New(msg)
+		return nil, http.StatusBadRequest, err
+	}
+	if voter != addr {
+		return nil, http.StatusBadRequest, errors.New("Vote address does not match request.")
 	}
+	v.Addr = voter
 
-	if err = p.ValidateBalance(weight); err != nil {
-		msg := fmt.Sprintf("Account balance is too low to vote on this proposal.")
-		log.Error().Err(err).Msg(msg)
-		return errors.New(msg)
+	v.Proposal_id = p.ID
+
+	v.SetPrimaryChoice(p)
+
+	existingVote := models.Vote{Proposal_id: v.Proposal_id, Addr: v.Addr}
+	if err := existingVote.GetVote(h.A.DB); err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return nil, http.StatusNotFound, errors.New("Address has not voted for this proposal.")
+		}
+		return nil, http.StatusInternalServerError, err
 	}
 
-	// Include voucher in vote data when pinning
-	ipfsVote := map[string]interface{}{
-		"vote": v,
+	if existingVote.Message == v.Message {
+		return nil, http.StatusBadRequest, errors.New("Vote message has already been used.")
+	}
+
+	// check that proposal is live
+	if os.Getenv("APP_ENV") != "DEV" {
+		if !p.IsLive() {
+			return nil, http.StatusBadRequest, errors.New("User cannot change vote on inactive proposal.")
+		}
 	}
-	v.Cid, err = h.pinJSONToIpfs(ipfsVote)
+
+	if err := h.validateVote(p, v); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// weighed the same way as a new vote, by every strategy of the
+	// proposal; snapshot balances are served from the stored snapshot
+	vb, err := h.useStrategiesFetchBalance(v, p)
 	if err != nil {
-		msg := fmt.Sprintf("Error pinning proposal to IPFS.")
-		log.Error().Err(err).Msg(msg)
-		return errors.New(msg)
+		return nil, http.StatusInternalServerError, err
 	}
 
-	if err := v.CreateVote(h.A.DB); err != nil {
-		msg := fmt.Sprintf("Error creating vote for address %s.", v.Addr)
+	pinJob, err := h.pinVote(&vb, p)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if err := vb.UpdateVote(h.A.DB); err != nil {
+		msg := fmt.Sprintf("Error updating vote for address %s.", v.Addr)
 		log.Error().Err(err).Msg(msg)
-		return errors.New(msg)
+		return nil, http.StatusInternalServerError, errors.New(msg)
 	}
 
-	return nil
+	// a pin still pending for the replaced vote must not overwrite
+	// this vote's cid
+	if pinJob != nil {
+		h.queuePinJob(pinJob, p.Community_id, models.PinRecordVote, vb.ID)
+	} else if err := models.CancelPendingPinJobs(h.A.DB, models.PinRecordVote, vb.ID); err != nil {
+		log.Error().Err(err).Msgf("Error cancelling pins for vote %d.", vb.ID)
+	}
+
+	event := vb.Vote
+	event.HideCastTime()
+	go h.dispatchWebhookEvent(p.Community_id, models.VoteCastEvent, event)
+
+	return &vb, http.StatusOK, nil
 }
 
-func (h *Helpers) validateVote(p models.Proposal, v models.Vote) error {
-	// validate the user is not on community's blocklist
-	if err := h.validateBlocklist(v.Addr, p.Community_id); err != nil {
-		log.Error().Err(err).Msgf(fmt.Sprintf("Address %v is on blocklist for community id %v.\n", v.Addr, p.Community_id))
-		msg := fmt.Sprintf("Address %v is on blocklist for community id %v.", v.Addr, p.Community_id)
-		return errors.New(msg)
+// EVM addresses are stored lowercase and must be hex, before their
+// signatures are checked. Flow addresses are left as given.
+func normalizeVoterAddr(addr string) (string, error) {
+	if len(addr) != shared.EVMAddressLength {
+		return addr, nil
 	}
+	return shared.NormalizeEVMAddress(addr)
+}
 
-	// validate choice exists on proposal
-	if err := v.ValidateChoice(p); err != nil {
-		log.Error().Err(err)
+func (h *Helpers) insertVote(v models.VoteWithBalance, p models.Proposal) error {
+	pinJob, err := h.pinVote(&v, p)
+	if err != nil {
 		return err
 	}
 
-	// If voucher is present
-	if v.Voucher != nil {
-		// Transaction Signature validation
-		voucher := v.Voucher
+	if err := v.CreateVote(h.A.DB); err != nil {
+		msg := fmt.Sprintf("Error creating vote for address %s.", v.Addr)
+		log.Error().Err(err).Msg(msg)
+		return errors.New(msg)
+	}
+
+	if pinJob != nil {
+		h.queuePinJob(pinJob, p.Community_id, models.PinRecordVote, v.ID)
+	}
+
+	return nil
+}
+
+// Checks the vote's weight meets the proposal's minimum balance and
+// pins the vote to IPFS, setting its Cid. If pinning fails the vote's
+// pin status is pending, and the returned job must be queued once the
+// vote is saved.
+func (h *Helpers) pinVote(v *models.VoteWithBalance, p models.Proposal) (*models.PinJob, error) {
+	weight, err := h.useStrategyGetVoteWeight(p, v)
+	if err != nil {
+		msg := fmt.Sprintf("Error getting vote weight for address %s.", v.Addr)
+		log.Error().Err(err).Msg(msg)
+		return nil, errors.New(msg)
+	}
+
+	if err = p.ValidateBalance(weight); err != nil {
+		msg := fmt.Sprintf("Account balance is too low to vote on this proposal.")
+		log.Error().Err(err).Msg(msg)
+		return nil, errors.New(msg)
+	}
+
+	v.Sealed_ballot = nil
+	if p.Is_private {
+		key, err := models.GetOrCreateProposalKey(h.A.DB, p.ID)
+		if err != nil {
+			log.Error().Err(err).Msg("Error getting proposal key.")
+			return nil, errors.New("Error sealing ballot.")
+		}
+		if err := v.SealBallot(key.Key); err != nil {
+			log.Error().Err(err).Msg("Error sealing ballot.")
+			return nil, errors.New("Error sealing ballot.")
+		}
+	}
+
+	// Include voucher in vote data when pinning
+	ipfsVote := map[string]interface{}{
+		"vote": v,
+	}
+	cid, pinJob, err := h.pinOrQueue(ipfsVote)
+	if err != nil {
+		msg := fmt.Sprintf("Error pinning vote to IPFS.")
+		log.Error().Err(err).Msg(msg)
+		return nil, errors.New(msg)
+	}
+
+	v.Cid = cid
+	v.Pin_status = models.PinPinned
+	if pinJob != nil {
+		v.Pin_status = models.PinPending
+	}
+
+	return pinJob, nil
+}
+
+func (h *Helpers) validateVote(p models.Proposal, v models.Vote) error {
+	// validate the user is not on community's blocklist
+	if err := h.validateBlocklist(v.Addr, p.Community_id); err != nil {
+		log.Error().Err(err).Msgf(fmt.Sprintf("Address %v is on blocklist for community id %v.\n", v.Addr, p.Community_id))
+		msg := fmt.Sprintf("Address %v is on blocklist for community id %v.", v.Addr, p.Community_id)
+		return errors.New(msg)
+	}
+
+	// validate choice exists on proposal
+	if err := v.ValidateChoice(p); err != nil {
+		log.Error().Err(err)
+		return err
+	}
+
+	// If voucher is present
+	if v.Voucher != nil {
+		// Transaction Signature validation
+		voucher := v.Voucher
 		authorizer := voucher.Authorizers[0]
 
 		v.Composite_signatures = shared.GetUserCompositeSignatureFromVoucher(voucher)
@@ -405,6 +927,10 @@ func (h *Helpers) validateVote(p models.Proposal, v models.Vote) error {
 			log.Error().Err(err)
 			return err
 		}
+		if err := v.ValidateMessageBallot(voteMessageToValidate, p); err != nil {
+			log.Error().Err(err)
+			return err
+		}
 
 		// re-build message & composite signatures for validation
 		// set v.Message as the encoded message, rather than the colon(:) delimited message above.
@@ -423,7 +949,11 @@ func (h *Helpers) validateVote(p models.Proposal, v models.Vote) error {
 			log.Error().Err(err)
 			return err
 		}
-		if err := h.validateUserSignature(v.Addr, v.Message, v.Composite_signatures); err != nil {
+		if err := v.ValidateMessageBallot(string(decodedMessage), p); err != nil {
+			log.Error().Err(err)
+			return err
+		}
+		if err := h.validateVoteSignature(p, v); err != nil {
 			return err
 		}
 	}
@@ -431,6 +961,37 @@ func (h *Helpers) validateVote(p models.Proposal, v models.Vote) error {
 	return nil
 }
 
+// Validates the vote's signature on the chain of the proposal's strategy,
+// as voters on EVM strategies sign with their EVM account.
+func (h *Helpers) validateVoteSignature(p models.Proposal, v models.Vote) error {
+	if !h.A.Config.Features["validateSigs"] {
+		return nil
+	}
+
+	c, _, err := h.fetchCommunity(p.Community_id)
+	if err != nil {
+		return err
+	}
+	if c.Strategies == nil {
+		return h.validateUserSignature(v.Addr, v.Message, v.Composite_signatures)
+	}
+	strategy, err := models.MatchStrategyByProposal(*c.Strategies, *p.Strategy)
+	if err != nil {
+		return err
+	}
+	if strategy.Contract.IsEVM() {
+		if _, err := shared.NormalizeEVMAddress(v.Addr); err != nil {
+			return err
+		}
+	}
+	chain, err := h.A.Chains.ForContract(&strategy.Contract)
+	if err != nil {
+		return err
+	}
+
+	return chain.ValidateSignature(v.Addr, v.Message, v.Composite_signatures)
+}
+
 func (h *Helpers) fetchCommunity(id int) (models.Community, int, error) {
 	community := models.Community{ID: id}
 
@@ -467,53 +1028,402 @@ func (h *Helpers) createProposal(p models.Proposal) (models.Proposal, int, error
 		}
 	}
 
+	if err := p.ValidateStatus(); err != nil {
+		return models.Proposal{}, http.StatusBadRequest, err
+	}
+	if p.IsDraft() {
+		return h.createDraft(p)
+	}
+
+	pinJob, httpStatus, err := h.prepareProposal(&p)
+	if err != nil {
+		return models.Proposal{}, httpStatus, err
+	}
+
+	if err := p.CreateProposal(h.A.DB); err != nil {
+		return models.Proposal{}, http.StatusInternalServerError, err
+	}
+
+	if pinJob != nil {
+		h.queuePinJob(pinJob, p.Community_id, models.PinRecordProposal, p.ID)
+	}
+
+	if p.Is_private {
+		if _, err := models.GetOrCreateProposalKey(h.A.DB, p.ID); err != nil {
+			log.Error().Err(err).Msg("Error creating proposal key.")
+			return models.Proposal{}, http.StatusInternalServerError, err
+		}
+	}
+
+	go h.dispatchWebhookEvent(p.Community_id, models.ProposalCreatedEvent, p)
+
+	return p, http.StatusCreated, nil
+}
+
+// Saves a draft without snapshotting or pinning it. Drafts are only
+// visible to the community until they are published.
+func (h *Helpers) createDraft(p models.Proposal) (models.Proposal, int, error) {
 	community, httpStatus, err := h.fetchCommunity(p.Community_id)
 	if err != nil {
 		return models.Proposal{}, httpStatus, err
 	}
 
+	if *community.Only_authors_to_submit {
+		if err := models.EnsureRoleForCommunity(h.A.DB, p.Creator_addr, community.ID, "author"); err != nil {
+			errMsg := fmt.Sprintf("Account %s is not an author for community %d.", p.Creator_addr, p.Community_id)
+			log.Error().Err(err).Msg(errMsg)
+			return models.Proposal{}, http.StatusForbidden, errors.New(errMsg)
+		}
+	}
+
+	if p.Voting_type != nil {
+		if err := p.ValidateVotingType(); err != nil {
+			return models.Proposal{}, http.StatusBadRequest, err
+		}
+	}
+
+	p.Block_height = nil
+	p.Cid = nil
+	if err := p.CreateProposal(h.A.DB); err != nil {
+		return models.Proposal{}, http.StatusInternalServerError, err
+	}
+
+	return p, http.StatusCreated, nil
+}
+
+// Drafts and pending proposals can be edited by their creator or by
+// any author of the community.
+func (h *Helpers) validateProposalEditor(p models.Proposal, payload shared.TimestampSignaturePayload) error {
+	if err := h.validateSigner(payload, nil); err != nil {
+		return err
+	}
+
+	if payload.Signing_addr == p.Creator_addr {
+		return nil
+	}
+	if err := models.EnsureRoleForCommunity(h.A.DB, payload.Signing_addr, p.Community_id, "author"); err != nil {
+		errMsg := fmt.Sprintf("Account %s is not an author for community %d.", payload.Signing_addr, p.Community_id)
+		log.Error().Err(err).Msg(errMsg)
+		return errors.New(errMsg)
+	}
+	return nil
+}
+
+// Drafts can only be read by those who can edit them.
+func (h *Helpers) authorizeDraftRead(r *http.Request, p models.Proposal) (int, error) {
+	payload, err := h.requestSigner(r)
+	if err != nil {
+		return http.StatusUnauthorized, err
+	}
+	if err := h.validateProposalEditor(p, payload); err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
+
+func (h *Helpers) updateDraft(p models.Proposal, payload models.DraftProposalPayload) (models.Proposal, int, error) {
+	if !p.IsDraft() {
+		return models.Proposal{}, http.StatusBadRequest, errors.New("Only drafts can be edited.")
+	}
+
+	if err := h.validateProposalEditor(p, payload.TimestampSignaturePayload); err != nil {
+		return models.Proposal{}, http.StatusForbidden, err
+	}
+
+	p.ApplyDraft(payload)
+	if p.Voting_type != nil {
+		if err := p.ValidateVotingType(); err != nil {
+			return models.Proposal{}, http.StatusBadRequest, err
+		}
+	}
+
+	if err := p.UpdateDraft(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error updating draft.")
+		return models.Proposal{}, http.StatusInternalServerError, err
+	}
+
+	return p, http.StatusOK, nil
+}
+
+// Validates the draft, snapshots it and pins it to IPFS, then publishes it.
+func (h *Helpers) publishProposal(p models.Proposal) (models.Proposal, int, error) {
+	if !p.IsDraft() {
+		return models.Proposal{}, http.StatusBadRequest, errors.New("Only drafts can be published.")
+	}
+
+	published := models.ProposalPublished
+	p.Status = &published
+
+	now := time.Now().UTC()
+	if p.Start_time.Before(now) {
+		p.Start_time = now
+	}
+	if !p.End_time.After(p.Start_time) {
+		return models.Proposal{}, http.StatusBadRequest, errors.New("End time must be after start time.")
+	}
+
+	pinJob, httpStatus, err := h.prepareProposal(&p)
+	if err != nil {
+		return models.Proposal{}, httpStatus, err
+	}
+
+	if err := p.PublishProposal(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error publishing draft.")
+		return models.Proposal{}, http.StatusInternalServerError, err
+	}
+
+	if pinJob != nil {
+		h.queuePinJob(pinJob, p.Community_id, models.PinRecordProposal, p.ID)
+	}
+
+	if p.Is_private {
+		if _, err := models.GetOrCreateProposalKey(h.A.DB, p.ID); err != nil {
+			log.Error().Err(err).Msg("Error creating proposal key.")
+			return models.Proposal{}, http.StatusInternalServerError, err
+		}
+	}
+
+	go h.dispatchWebhookEvent(p.Community_id, models.ProposalCreatedEvent, p)
+
+	return p, http.StatusOK, nil
+}
+
+// Edits a proposal that has not started and has no votes, pinning the
+// edited proposal to IPFS as a new revision. If pinning fails the pin
+// is queued, as when a proposal is created.
+func (h *Helpers) editProposal(p models.Proposal, payload models.EditProposalPayload) (models.Proposal, int, error) {
+	if p.IsDraft() {
+		return models.Proposal{}, http.StatusBadRequest, errors.New("Drafts are edited with PUT /proposals/{id}/draft.")
+	}
+
+	if err := h.validateProposalEditor(p, payload.TimestampSignaturePayload); err != nil {
+		return models.Proposal{}, http.StatusForbidden, err
+	}
+
+	p.ApplyEdit(payload)
+
+	validate := validator.New()
+	if err := validate.StructExcept(p, "Timestamp"); err != nil || len(p.Choices) == 0 {
+		log.Error().Err(err).Msg("Invalid proposal edit.")
+		return models.Proposal{}, http.StatusBadRequest, errors.New("Invalid proposal.")
+	}
+
+	if err := p.ValidatePassChoice(); err != nil {
+		log.Error().Err(err).Msg("Invalid pass choice.")
+		return models.Proposal{}, http.StatusBadRequest, err
+	}
+
+	// checked before pinning, so rejected edits are not pinned, and
+	// again when the revision is stored
+	if err := models.EnsureProposalEditable(h.A.DB, p.ID); err != nil {
+		if errors.Is(err, models.ErrProposalNotEditable) {
+			return models.Proposal{}, http.StatusBadRequest, err
+		}
+		log.Error().Err(err).Msg("Error checking proposal is editable.")
+		return models.Proposal{}, http.StatusInternalServerError, err
+	}
+
+	cid, pinJob, err := h.pinOrQueue(p)
+	if err != nil {
+		log.Error().Err(err).Msg("IPFS error: " + err.Error())
+		return models.Proposal{}, http.StatusInternalServerError, errors.New("Error pinning JSON to IPFS.")
+	}
+	p.Cid = cid
+	p.Pin_status = models.PinPinned
+	if pinJob != nil {
+		p.Pin_status = models.PinPending
+	}
+
+	if _, err := p.ReviseProposal(h.A.DB, payload.Signing_addr, payload.Composite_signatures); err != nil {
+		if errors.Is(err, models.ErrProposalNotEditable) {
+			return models.Proposal{}, http.StatusBadRequest, err
+		}
+		log.Error().Err(err).Msg("Error revising proposal.")
+		return models.Proposal{}, http.StatusInternalServerError, err
+	}
+
+	// a pin still pending for the edited proposal must not overwrite
+	// this revision's cid
+	if pinJob != nil {
+		h.queuePinJob(pinJob, p.Community_id, models.PinRecordProposal, p.ID)
+	} else if err := models.CancelPendingPinJobs(h.A.DB, models.PinRecordProposal, p.ID); err != nil {
+		log.Error().Err(err).Msgf("Error cancelling pins for proposal %d.", p.ID)
+	}
+
+	go h.dispatchWebhookEvent(p.Community_id, models.ProposalEditedEvent, p)
+
+	return p, http.StatusOK, nil
+}
+
+// Publishes drafts whose scheduled publish time has passed.
+func (h *Helpers) publishScheduledDrafts() {
+	drafts, err := models.GetScheduledDrafts(h.A.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("Scheduler error getting scheduled drafts.")
+		return
+	}
+
+	for _, p := range drafts {
+		// a draft snapshotting a time still to come is published once
+		// that time is sealed, which is not a failed attempt
+		now := time.Now().UTC()
+		if p.Snapshot_time != nil && p.Snapshot_time.After(now) {
+			h.deferScheduledPublish(p, p.Snapshot_time.Add(retryBaseBackoff), nil)
+			continue
+		}
+
+		_, httpStatus, err := h.publishProposal(*p)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, shared.ErrBlockNotSealed) {
+			h.deferScheduledPublish(p, now.Add(retryBaseBackoff), err)
+			continue
+		}
+		log.Error().Err(err).Msgf("Scheduler error publishing draft %d.", p.ID)
+
+		// an invalid draft will not publish until it is edited, other
+		// errors are retried with a backoff a limited number of times
+		var retryIn time.Duration
+		if httpStatus >= http.StatusInternalServerError && p.Publish_attempts+1 < draftPublishMaxAttempts {
+			retryIn = retryBackoff(p.Publish_attempts + 1)
+		}
+		if err := p.FailScheduledPublish(h.A.DB, err, retryIn); err != nil {
+			log.Error().Err(err).Msgf("Scheduler error recording failed publish of draft %d.", p.ID)
+		}
+	}
+}
+
+func (h *Helpers) deferScheduledPublish(p *models.Proposal, retryAt time.Time, reason error) {
+	log.Info().Msgf("Scheduler deferring publish of draft %d until %s.", p.ID, retryAt.Format(time.RFC3339))
+	if err := p.DeferScheduledPublish(h.A.DB, retryAt, reason); err != nil {
+		log.Error().Err(err).Msgf("Scheduler error deferring publish of draft %d.", p.ID)
+	}
+}
+
+// Validates a proposal, snapshots its strategy and pins it to IPFS.
+// Run when a proposal is created as published and when a draft is
+// published. If pinning fails the proposal's pin status is pending,
+// and the returned job must be queued once the proposal is saved.
+func (h *Helpers) prepareProposal(p *models.Proposal) (*models.PinJob, int, error) {
+	if err := p.ValidateStrategies(); err != nil {
+		log.Error().Err(err).Msg("Invalid strategies.")
+		return nil, http.StatusBadRequest, err
+	}
+	if p.Strategy == nil {
+		return nil, http.StatusBadRequest, errors.New("A strategy is required.")
+	}
+
+	community, httpStatus, err := h.fetchCommunity(p.Community_id)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	for _, name := range p.StrategyNames() {
+		if _, err := models.MatchStrategyByProposal(*community.Strategies, name); err != nil {
+			errMsg := fmt.Sprintf("Community does not have strategy %s available.", name)
+			log.Error().Err(err).Msg(errMsg)
+			return nil, http.StatusBadRequest, errors.New(errMsg)
+		}
+	}
+
 	strategy, err := models.MatchStrategyByProposal(*community.Strategies, *p.Strategy)
 	if err != nil {
 		errMsg := "Community does not have this strategy available."
 		log.Error().Err(err).Msg(errMsg)
-		return models.Proposal{}, http.StatusInternalServerError, errors.New(errMsg)
+		return nil, http.StatusInternalServerError, errors.New(errMsg)
 
 	}
 
-	// Set Min Balance/Max Weight to community defaults if not provided
-	if p.Min_balance == nil {
-		p.Min_balance = strategy.Contract.Threshold
+	if err := p.ValidateVotingType(); err != nil {
+		log.Error().Err(err).Msg("Invalid voting type.")
+		return nil, http.StatusBadRequest, err
 	}
-	if p.Max_weight == nil {
-		p.Max_weight = strategy.Contract.MaxWeight
+
+	if p.IsWeighted() && !models.IsSplitVotingStrategy(*p.Strategy) {
+		errMsg := fmt.Sprintf("Strategy %s does not support weighted voting.", *p.Strategy)
+		log.Error().Msg(errMsg)
+		return nil, http.StatusBadRequest, errors.New(errMsg)
 	}
 
-	if err := h.snapshot(&strategy, &p); err != nil {
-		return models.Proposal{}, http.StatusInternalServerError, err
+	// Set Min Balance/Max Weight/Quorum to community defaults if not
+	// provided. They are in the units of a single strategy, so
+	// multi-strategy proposals do not take them.
+	if !p.IsMultiStrategy() {
+		if p.Min_balance == nil {
+			p.Min_balance = strategy.Contract.Threshold
+		}
+		if p.Max_weight == nil {
+			p.Max_weight = strategy.Contract.MaxWeight
+		}
+		if p.Quorum == nil {
+			p.Quorum = strategy.Contract.Quorum
+			p.Quorum_type = strategy.Contract.QuorumType
+		}
 	}
 
-	if err := h.enforceCommunityRestrictions(community, p, strategy); err != nil {
-		return models.Proposal{}, http.StatusForbidden, err
+	// Set Pass Threshold to community default if not provided
+	if p.Pass_threshold == nil {
+		p.Pass_threshold = strategy.Contract.PassThreshold
+	}
+
+	if err := p.ValidateQuorum(); err != nil {
+		log.Error().Err(err).Msg("Invalid quorum.")
+		return nil, http.StatusBadRequest, err
 	}
 
-	if err := h.processSnapshotStatus(&strategy, &p); err != nil {
+	if err := p.ValidatePassChoice(); err != nil {
+		log.Error().Err(err).Msg("Invalid pass choice.")
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err := p.ValidateSnapshotBlock(&strategy.Contract); err != nil {
+		log.Error().Err(err).Msg("Invalid snapshot block.")
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err := h.snapshot(&strategy, p); err != nil {
+		if errors.Is(err, shared.ErrBlockNotQueryable) {
+			return nil, http.StatusBadRequest, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if p.RequiresTotalSupply() {
+		if err := h.snapshotTotalSupply(&strategy, p); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	if err := h.enforceCommunityRestrictions(community, *p, strategy); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	if err := h.fetchSnapshotStatus(&strategy, p); err != nil {
 		errMsg := "Error processing snapshot status."
 		log.Error().Err(err).Msg(errMsg)
-		return models.Proposal{}, http.StatusInternalServerError, errors.New(errMsg)
+		return nil, http.StatusInternalServerError, errors.New(errMsg)
 	}
 
-	p.Cid, err = h.pinJSONToIpfs(p)
+	cid, pinJob, err := h.pinOrQueue(*p)
 	if err != nil {
 		log.Error().Err(err).Msg("IPFS error: " + err.Error())
 		errMsg := "Error pinning JSON to IPFS."
-		return models.Proposal{}, http.StatusInternalServerError, errors.New(errMsg)
+		return nil, http.StatusInternalServerError, errors.New(errMsg)
+	}
+	p.Cid = cid
+	p.Pin_status = models.PinPinned
+	if pinJob != nil {
+		p.Pin_status = models.PinPending
 	}
 
+	// the signature timestamp is checked before a proposal is prepared,
+	// and is not stored with drafts being published
 	validate := validator.New()
-	vErr := validate.Struct(p)
+	vErr := validate.StructExcept(*p, "Timestamp")
 	if vErr != nil {
 		log.Error().Err(vErr)
-		return models.Proposal{}, http.StatusBadRequest, errors.New("Invalid proposal.")
+		return nil, http.StatusBadRequest, errors.New("Invalid proposal.")
 	}
 
 	if os.Getenv("APP_ENV") == "PRODUCTION" {
@@ -522,11 +1432,7 @@ func (h *Helpers) createProposal(p models.Proposal) (models.Proposal, int, error
 		}
 	}
 
-	if err := p.CreateProposal(h.A.DB); err != nil {
-		return models.Proposal{}, http.StatusInternalServerError, err
-	}
-
-	return p, http.StatusCreated, nil
+	return pinJob, http.StatusOK, nil
 }
 
 func (h *Helpers) enforceCommunityRestrictions(
@@ -563,29 +1469,137 @@ func (h *Helpers) enforceCommunityRestrictions(
 			return errors.New(errMsg)
 		}
 
-		if !hasBalance {
-			errMsg := "Insufficient token balance to create proposal."
+		if !hasBalance {
+			errMsg := "Insufficient token balance to create proposal."
+			log.Error().Err(err).Msg(errMsg)
+			return errors.New(errMsg)
+		}
+	}
+
+	return nil
+}
+
+func (h *Helpers) snapshot(strategy *models.Strategy, p *models.Proposal) error {
+	if p.HasSnapshotBlock() {
+		return h.snapshotAtBlock(strategy, p)
+	}
+
+	// balances on EVM chains are read at the proposal's block by the
+	// chain adapter, so the snapshot is the current block
+	if models.RequiresSnapshot(*strategy.Name) && strategy.Contract.IsEVM() {
+		chain, err := h.A.Chains.ForContract(&strategy.Contract)
+		if err != nil {
+			return err
+		}
+		blockHeight, err := chain.GetCurrentBlockHeight()
+		if err != nil {
+			errMsg := "Error taking snapshot."
+			log.Error().Err(err).Msg(errMsg)
+			return errors.New(errMsg)
+		}
+		height := uint64(blockHeight)
+		status := "success"
+		p.Block_height = &height
+		p.Snapshot_status = &status
+		return nil
+	}
+
+	//var snapshotResponse *shared.SnapshotResponse
+	if models.RequiresSnapshot(*strategy.Name) {
+		snapshotResponse, err := h.A.SnapshotClient.TakeSnapshot(strategy.Contract)
+		if err != nil {
+			errMsg := "Error taking snapshot."
+			return errors.New(errMsg)
+		}
+		p.Block_height = &snapshotResponse.Data.BlockHeight
+		p.Snapshot_status = &snapshotResponse.Data.Status
+	}
+
+	return nil
+}
+
+// Snapshots the proposal at the block height it gives, or at the last
+// sealed block before its snapshot time. Blocks the chain cannot be
+// queried at return an error wrapping shared.ErrBlockNotQueryable.
+func (h *Helpers) snapshotAtBlock(strategy *models.Strategy, p *models.Proposal) error {
+	if strategy.Contract.IsEVM() {
+		chain, err := h.A.Chains.ForContract(&strategy.Contract)
+		if err != nil {
+			return err
+		}
+		current, err := chain.GetCurrentBlockHeight()
+		if err != nil {
+			errMsg := "Error taking snapshot."
+			log.Error().Err(err).Msg(errMsg)
+			return errors.New(errMsg)
+		}
+		if *p.Snapshot_block_height > uint64(current) {
+			return fmt.Errorf(
+				"%w: block %d is after the latest block %d",
+				shared.ErrBlockNotSealed,
+				*p.Snapshot_block_height,
+				current,
+			)
+		}
+		height := *p.Snapshot_block_height
+		status := "success"
+		p.Block_height = &height
+		p.Snapshot_status = &status
+		return nil
+	}
+
+	var height uint64
+	var err error
+	if p.Snapshot_time != nil {
+		height, err = h.A.FlowAdapter.GetBlockHeightAtTime(*p.Snapshot_time)
+	} else {
+		height = *p.Snapshot_block_height
+		err = h.A.FlowAdapter.ValidateQueryableBlockHeight(height)
+	}
+	if err != nil {
+		if errors.Is(err, shared.ErrBlockNotQueryable) {
+			log.Error().Err(err).Msg("Invalid snapshot block.")
+			return err
+		}
+		errMsg := "Error resolving snapshot block."
+		log.Error().Err(err).Msg(errMsg)
+		return errors.New(errMsg)
+	}
+	p.Block_height = &height
+
+	if models.RequiresSnapshot(*strategy.Name) {
+		snapshotResponse, err := h.A.SnapshotClient.GetSnapshotStatusAtBlockHeight(strategy.Contract, height)
+		if err != nil {
+			errMsg := "Error taking snapshot."
 			log.Error().Err(err).Msg(errMsg)
 			return errors.New(errMsg)
 		}
+		p.Snapshot_status = &snapshotResponse.Data.Status
 	}
 
 	return nil
 }
 
-func (h *Helpers) snapshot(strategy *models.Strategy, p *models.Proposal) error {
-	s := h.initStrategy(*strategy.Name)
+func (h *Helpers) snapshotTotalSupply(strategy *models.Strategy, p *models.Proposal) error {
+	if strategy.Contract.Name == nil || strategy.Contract.Addr == nil {
+		return errors.New("Percentage quorum requires a token contract.")
+	}
+	if strategy.Contract.IsEVM() {
+		return errors.New("Percentage quorum is not supported for EVM contracts.")
+	}
 
-	//var snapshotResponse *shared.SnapshotResponse
-	if s.RequiresSnapshot() {
-		snapshotResponse, err := h.A.SnapshotClient.TakeSnapshot(strategy.Contract)
-		if err != nil {
-			errMsg := "Error taking snapshot."
-			return errors.New(errMsg)
-		}
-		p.Block_height = &snapshotResponse.Data.BlockHeight
-		p.Snapshot_status = &snapshotResponse.Data.Status
+	var blockHeight uint64
+	if p.Block_height != nil {
+		blockHeight = *p.Block_height
+	}
+
+	totalSupply, err := h.A.FlowAdapter.GetTotalSupply(&strategy.Contract, blockHeight)
+	if err != nil {
+		errMsg := "Error fetching total supply."
+		log.Error().Err(err).Msg(errMsg)
+		return errors.New(errMsg)
 	}
+	p.Total_supply = &totalSupply
 
 	return nil
 }
@@ -679,16 +1693,9 @@ func (h *Helpers) updateCommunity(id int, payload models.UpdateCommunityRequestP
 		return models.Community{}, http.StatusForbidden, err
 	}
 
-	if payload.Voucher != nil {
-		if err := h.validateUserViaVoucher(payload.Signing_addr, payload.Voucher); err != nil {
-			log.Error().Err(err)
-			return models.Community{}, http.StatusForbidden, err
-		}
-	} else {
-		if err := h.validateUser(payload.Signing_addr, payload.Timestamp, payload.Composite_signatures); err != nil {
-			log.Error().Err(err)
-			return models.Community{}, http.StatusForbidden, err
-		}
+	if err := h.validateSigner(payload.TimestampSignaturePayload, payload.Voucher); err != nil {
+		log.Error().Err(err)
+		return models.Community{}, http.StatusForbidden, err
 	}
 
 	if err := c.UpdateCommunity(h.A.DB, &payload); err != nil {
@@ -705,16 +1712,9 @@ func (h *Helpers) updateCommunity(id int, payload models.UpdateCommunityRequestP
 }
 
 func (h *Helpers) removeUserRole(payload models.CommunityUserPayload) (int, error) {
-	if payload.Voucher != nil {
-		if err := h.validateUserViaVoucher(payload.Signing_addr, payload.Voucher); err != nil {
-			log.Error().Err(err)
-			return http.StatusForbidden, err
-		}
-	} else {
-		if err := h.validateUser(payload.Signing_addr, payload.Timestamp, payload.Composite_signatures); err != nil {
-			log.Error().Err(err)
-			return http.StatusForbidden, err
-		}
+	if err := h.validateSigner(payload.TimestampSignaturePayload, payload.Voucher); err != nil {
+		log.Error().Err(err)
+		return http.StatusForbidden, err
 	}
 
 	if payload.User_type == "member" {
@@ -802,16 +1802,9 @@ func (h *Helpers) createCommunityUser(payload models.CommunityUserPayload) (int,
 		return http.StatusForbidden, CANNOT_ADD_MEMBER_ERR
 	}
 
-	if payload.Voucher != nil {
-		if err := h.validateUserViaVoucher(payload.Signing_addr, payload.Voucher); err != nil {
-			log.Error().Err(err)
-			return http.StatusForbidden, err
-		}
-	} else {
-		if err := h.validateUser(payload.Signing_addr, payload.Timestamp, payload.Composite_signatures); err != nil {
-			log.Error().Err(err)
-			return http.StatusForbidden, err
-		}
+	if err := h.validateSigner(payload.TimestampSignaturePayload, payload.Voucher); err != nil {
+		log.Error().Err(err)
+		return http.StatusForbidden, err
 	}
 
 	// check that community user doesnt already exist
@@ -864,7 +1857,7 @@ func (h *Helpers) updateAddressesInList(id int, payload models.ListUpdatePayload
 		return http.StatusBadRequest, errors.New(errMsg)
 	}
 
-	if err := h.validateUserWithRole(payload.Signing_addr, payload.Timestamp, payload.Composite_signatures, l.Community_id, "admin"); err != nil {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, l.Community_id, "admin"); err != nil {
 		log.Error().Err(err)
 		return http.StatusForbidden, err
 	}
@@ -905,7 +1898,7 @@ func (h *Helpers) createListForCommunity(payload models.ListPayload) (models.Lis
 		return models.List{}, http.StatusBadRequest, errors.New(errMsg)
 	}
 
-	if err := h.validateUserWithRole(payload.Signing_addr, payload.Timestamp, payload.Composite_signatures, payload.Community_id, "admin"); err != nil {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, payload.Community_id, "admin"); err != nil {
 		log.Error().Err(err)
 		return models.List{}, http.StatusForbidden, err
 	}
@@ -986,6 +1979,241 @@ func (h *Helpers) validateTimestamp(timestamp string, expiry int) error {
 	return nil
 }
 
+func (h *Helpers) createWebhook(payload models.WebhookPayload) (models.Webhook, int, error) {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, payload.Community_id, "admin"); err != nil {
+		log.Error().Err(err)
+		return models.Webhook{}, http.StatusForbidden, err
+	}
+
+	w := payload.Webhook
+	if err := w.Validate(); err != nil {
+		return models.Webhook{}, http.StatusBadRequest, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		log.Error().Err(err).Msg("Error generating webhook secret.")
+		return models.Webhook{}, http.StatusInternalServerError, err
+	}
+	w.Secret = secret
+	w.Is_active = true
+
+	if err := w.CreateWebhook(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error creating webhook.")
+		return models.Webhook{}, http.StatusInternalServerError, err
+	}
+
+	// the secret is only returned when the webhook is created
+	return w, http.StatusCreated, nil
+}
+
+func (h *Helpers) updateWebhook(payload models.WebhookPayload) (models.Webhook, int, error) {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, payload.Community_id, "admin"); err != nil {
+		log.Error().Err(err)
+		return models.Webhook{}, http.StatusForbidden, err
+	}
+
+	w, httpStatus, err := h.fetchWebhook(payload.ID, payload.Community_id)
+	if err != nil {
+		return models.Webhook{}, httpStatus, err
+	}
+
+	w.Url = payload.Url
+	w.Events = payload.Events
+	w.Is_active = payload.Is_active
+	if err := w.Validate(); err != nil {
+		return models.Webhook{}, http.StatusBadRequest, err
+	}
+
+	if err := w.UpdateWebhook(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error updating webhook.")
+		return models.Webhook{}, http.StatusInternalServerError, err
+	}
+
+	w.Secret = ""
+	return w, http.StatusOK, nil
+}
+
+func (h *Helpers) deleteWebhook(payload models.WebhookPayload) (int, error) {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, payload.Community_id, "admin"); err != nil {
+		log.Error().Err(err)
+		return http.StatusForbidden, err
+	}
+
+	w, httpStatus, err := h.fetchWebhook(payload.ID, payload.Community_id)
+	if err != nil {
+		return httpStatus, err
+	}
+
+	if err := w.DeleteWebhook(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error deleting webhook.")
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (h *Helpers) createCommunityScript(payload models.CommunityScriptPayload) (models.CommunityScript, int, error) {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, payload.Community_id, "admin"); err != nil {
+		log.Error().Err(err)
+		return models.CommunityScript{}, http.StatusForbidden, err
+	}
+
+	script := payload.CommunityScript
+	if _, ok := h.A.FlowAdapter.CustomScriptsMap[script.Key]; ok {
+		msg := fmt.Sprintf("Script key %s is reserved.", script.Key)
+		return models.CommunityScript{}, http.StatusBadRequest, errors.New(msg)
+	}
+	existing := models.CommunityScript{Community_id: script.Community_id, Key: script.Key}
+	if err := existing.GetCommunityScriptByKey(h.A.DB); err == nil {
+		msg := fmt.Sprintf("Script with key %s already exists.", script.Key)
+		return models.CommunityScript{}, http.StatusConflict, errors.New(msg)
+	} else if err.Error() != pgx.ErrNoRows.Error() {
+		return models.CommunityScript{}, http.StatusInternalServerError, err
+	}
+
+	if httpStatus, err := h.dryRunCommunityScript(&script, payload); err != nil {
+		return models.CommunityScript{}, httpStatus, err
+	}
+
+	if err := script.CreateCommunityScript(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error creating community script.")
+		return models.CommunityScript{}, http.StatusInternalServerError, err
+	}
+
+	return script, http.StatusCreated, nil
+}
+
+func (h *Helpers) updateCommunityScript(payload models.CommunityScriptPayload) (models.CommunityScript, int, error) {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, payload.Community_id, "admin"); err != nil {
+		log.Error().Err(err)
+		return models.CommunityScript{}, http.StatusForbidden, err
+	}
+
+	script, httpStatus, err := h.fetchCommunityScript(payload.Community_id, payload.Key)
+	if err != nil {
+		return models.CommunityScript{}, httpStatus, err
+	}
+
+	if payload.Src != script.Src {
+		if httpStatus, err := h.ensureCommunityScriptUnused(script); err != nil {
+			return models.CommunityScript{}, httpStatus, err
+		}
+	}
+
+	script.Name = payload.Name
+	script.Description = payload.Description
+	script.Src = payload.Src
+	if httpStatus, err := h.dryRunCommunityScript(&script, payload); err != nil {
+		return models.CommunityScript{}, httpStatus, err
+	}
+
+	if err := script.UpdateCommunityScript(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error updating community script.")
+		return models.CommunityScript{}, http.StatusInternalServerError, err
+	}
+
+	return script, http.StatusOK, nil
+}
+
+func (h *Helpers) deleteCommunityScript(payload models.CommunityScriptPayload) (int, error) {
+	if err := h.validateSignerWithRole(payload.TimestampSignaturePayload, nil, payload.Community_id, "admin"); err != nil {
+		log.Error().Err(err)
+		return http.StatusForbidden, err
+	}
+
+	script, httpStatus, err := h.fetchCommunityScript(payload.Community_id, payload.Key)
+	if err != nil {
+		return httpStatus, err
+	}
+
+	if httpStatus, err := h.ensureCommunityScriptUnused(script); err != nil {
+		return httpStatus, err
+	}
+
+	if err := script.DeleteCommunityScript(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error deleting community script.")
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// A script's source can't change while votes are weighed with it, so
+// it must be removed from the community's strategies first, and no
+// custom-script proposal of the community may still be live.
+func (h *Helpers) ensureCommunityScriptUnused(script models.CommunityScript) (int, error) {
+	c, httpStatus, err := h.fetchCommunity(script.Community_id)
+	if err != nil {
+		return httpStatus, err
+	}
+	if script.IsUsedBy(&c) {
+		msg := fmt.Sprintf("Script %s is used by a community strategy.", script.Key)
+		return http.StatusConflict, errors.New(msg)
+	}
+
+	live, err := models.CountLiveCustomScriptProposals(h.A.DB, script.Community_id)
+	if err != nil {
+		log.Error().Err(err).Msg("Error counting live custom script proposals.")
+		return http.StatusInternalServerError, err
+	}
+	if live > 0 {
+		msg := fmt.Sprintf("Script %s can't change while custom-script proposals are live.", script.Key)
+		return http.StatusConflict, errors.New(msg)
+	}
+
+	return http.StatusOK, nil
+}
+
+// Validates the script and runs it for the signing admin against the
+// latest block of the emulator, so scripts that fail to parse, fail to run, time out or
+// don't return NFT IDs are rejected before communities can vote with
+// them.
+func (h *Helpers) dryRunCommunityScript(script *models.CommunityScript, payload models.CommunityScriptPayload) (int, error) {
+	if err := script.Validate(); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if _, err := h.A.DryRunAdapter.GetCustomScriptNFTIds(payload.Signing_addr, &payload.Contract, script.Src); err != nil {
+		msg := fmt.Sprintf("Script dry run failed: %s", err.Error())
+		return http.StatusBadRequest, errors.New(msg)
+	}
+
+	script.Creator_addr = payload.Signing_addr
+	return http.StatusOK, nil
+}
+
+func (h *Helpers) fetchCommunityScript(communityId int, key string) (models.CommunityScript, int, error) {
+	script := models.CommunityScript{Community_id: communityId, Key: key}
+	if err := script.GetCommunityScriptByKey(h.A.DB); err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			msg := fmt.Sprintf("Script with key %s not found.", key)
+			return models.CommunityScript{}, http.StatusNotFound, errors.New(msg)
+		}
+		return models.CommunityScript{}, http.StatusInternalServerError, err
+	}
+
+	return script, http.StatusOK, nil
+}
+
+func (h *Helpers) fetchWebhook(id, communityId int) (models.Webhook, int, error) {
+	w := models.Webhook{ID: id}
+	if err := w.GetWebhookById(h.A.DB); err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			msg := fmt.Sprintf("Webhook with ID %d not found.", id)
+			return models.Webhook{}, http.StatusNotFound, errors.New(msg)
+		}
+		return models.Webhook{}, http.StatusInternalServerError, err
+	}
+
+	if w.Community_id != communityId {
+		msg := fmt.Sprintf("Webhook with ID %d not found.", id)
+		return models.Webhook{}, http.StatusNotFound, errors.New(msg)
+	}
+
+	return w, http.StatusOK, nil
+}
+
 func (h *Helpers) validateUser(addr, timestamp string, compositeSignatures *[]shared.CompositeSignature) error {
 	if err := h.validateTimestamp(timestamp, 60); err != nil {
 		return err
@@ -1067,9 +2295,24 @@ func (h *Helpers) validateUserWithRoleViaVoucher(addr string, voucher *shared.Vo
 }
 
 func (h *Helpers) processSnapshotStatus(s *models.Strategy, p *models.Proposal) error {
+	status := p.Snapshot_status
+	if err := h.fetchSnapshotStatus(s, p); err != nil {
+		return err
+	}
+
+	// proposals being created are stored with their status
+	if p.ID == 0 || p.Snapshot_status == status {
+		return nil
+	}
+
+	return p.UpdateSnapshotStatus(h.A.DB)
+}
+
+// Sets the status of a processing snapshot without storing it.
+func (h *Helpers) fetchSnapshotStatus(s *models.Strategy, p *models.Proposal) error {
 	var processing = "processing"
 
-	if s.Contract.Name != nil && p.Snapshot_status == &processing {
+	if s.Contract.Name != nil && p.Snapshot_status != nil && *p.Snapshot_status == processing {
 		snapshotResponse, err := h.A.SnapshotClient.
 			GetSnapshotStatusAtBlockHeight(
 				s.Contract,
@@ -1080,13 +2323,89 @@ func (h *Helpers) processSnapshotStatus(s *models.Strategy, p *models.Proposal)
 		}
 
 		p.Snapshot_status = &snapshotResponse.Data.Status
+	}
+	return nil
+}
+
+func (h *Helpers) refreshSnapshotStatus(p *models.Proposal) error {
+	community, _, err := h.fetchCommunity(p.Community_id)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := models.MatchStrategyByProposal(*community.Strategies, *p.Strategy)
+	if err != nil {
+		return err
+	}
+
+	return h.processSnapshotStatus(&strategy, p)
+}
+
+var transitionEvents = map[string]string{
+	"active":    models.ProposalOpenedEvent,
+	"closed":    models.ProposalClosedEvent,
+	"cancelled": models.ProposalCancelledEvent,
+}
+
+// Runs the work for each status the proposal moved through since it was
+// last processed, in order, recording each transition.
+func (h *Helpers) processTransition(p models.Proposal) error {
+	if p.Computed_status == nil {
+		return nil
+	}
 
-		if err := p.UpdateSnapshotStatus(h.A.DB); err != nil {
+	for _, status := range missedStatuses(p) {
+		status := status
+		p.Computed_status = &status
+		recorded, err := h.processStatus(&p)
+		if err != nil || !recorded {
 			return err
 		}
 	}
+
 	return nil
+}
+
+// Returns the statuses to process for the proposal. A published
+// proposal that closed before it was seen open is opened first, so
+// webhooks still receive its opening.
+func missedStatuses(p models.Proposal) []string {
+	status := *p.Computed_status
+	if status != "closed" || p.Status == nil || *p.Status != "published" {
+		return []string{status}
+	}
+	if p.Processed_status != nil &&
+		(*p.Processed_status == "active" || *p.Processed_status == "closed") {
+		return []string{status}
+	}
+	return []string{"active", status}
+}
+
+// Runs the work for the proposal's computed status, then records the
+// transition. Returns false if another worker already recorded it.
+func (h *Helpers) processStatus(p *models.Proposal) (bool, error) {
+	switch *p.Computed_status {
+	case "active":
+		if err := h.refreshSnapshotStatus(p); err != nil {
+			return false, err
+		}
+	case "closed":
+		if _, err := h.getProposalResults(*p); err != nil {
+			return false, err
+		}
+	}
 
+	recorded, err := p.RecordTransition(h.A.DB)
+	if err != nil || !recorded {
+		return false, err
+	}
+	log.Info().Msgf("Proposal %d transitioned to %s.", p.ID, *p.Computed_status)
+
+	if event, ok := transitionEvents[*p.Computed_status]; ok {
+		h.dispatchWebhookEvent(p.Community_id, event, *p)
+	}
+
+	return true, nil
 }
 
 func (h *Helpers) processTokenThreshold(address string, c shared.Contract, contractType string) (bool, error) {
@@ -1107,12 +2426,12 @@ func (h *Helpers) processTokenThreshold(address string, c shared.Contract, contr
 }
 
 func (h *Helpers) initStrategy(name string) Strategy {
-	s := strategyMap[name]
+	s := strategies.Lookup(name)
 	if s == nil {
 		return nil
 	}
 
-	s.InitStrategy(h.A.FlowAdapter, h.A.DB, h.A.SnapshotClient)
+	s.InitStrategy(h.A.FlowAdapter, h.A.DB, h.A.SnapshotClient, h.A.Chains)
 
 	return s
 }
@@ -1124,13 +2443,48 @@ func (h *Helpers) pinJSONToIpfs(data interface{}) (*string, error) {
 		return &dummyHash, nil
 	}
 
-	pin, err := h.A.IpfsClient.PinJson(data)
+	pin, err := h.A.Storage.PinJson(data)
 	if err != nil {
 		return nil, err
 	}
 	return &pin.IpfsHash, nil
 }
 
+// Returns an error if a strategy is not registered or its contract is
+// missing a field the strategy requires.
+func validateStrategies(s []models.Strategy) error {
+	for _, s := range s {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns an error if a strategy's contract is on a chain the server
+// can't read from, or a custom-script strategy references a script that
+// is neither bundled with the server nor registered by the community.
+func (h *Helpers) validateStrategyContracts(communityId int, s []models.Strategy) error {
+	for _, s := range s {
+		if _, err := h.A.Chains.ForContract(&s.Contract); err != nil {
+			return err
+		}
+
+		if s.Name == nil || *s.Name != "custom-script" || s.Contract.Script == nil {
+			continue
+		}
+		if _, ok := h.A.FlowAdapter.CustomScriptsMap[*s.Contract.Script]; ok {
+			continue
+		}
+
+		script := models.CommunityScript{Community_id: communityId, Key: *s.Contract.Script}
+		if err := script.GetCommunityScriptByKey(h.A.DB); err != nil {
+			return fmt.Errorf("custom script not found: %s", *s.Contract.Script)
+		}
+	}
+	return nil
+}
+
 func validateContractThreshold(s []models.Strategy) error {
 	for _, s := range s {
 		if s.Threshold != nil {
@@ -1141,3 +2495,90 @@ func validateContractThreshold(s []models.Strategy) error {
 	}
 	return nil
 }
+
+func (h *Helpers) createDelegation(d models.Delegation) (models.Delegation, int, error) {
+	if _, httpStatus, err := h.fetchCommunity(d.Community_id); err != nil {
+		return models.Delegation{}, httpStatus, err
+	}
+
+	for _, addr := range []*string{&d.Delegator, &d.Delegate} {
+		normalized, err := normalizeVoterAddr(*addr)
+		if err != nil {
+			return models.Delegation{}, http.StatusBadRequest, err
+		}
+		*addr = normalized
+	}
+
+	if d.Delegator == d.Delegate {
+		return models.Delegation{}, http.StatusBadRequest, errors.New("An address cannot delegate to itself.")
+	}
+
+	if d.Strategy != nil {
+		if strategies.Lookup(*d.Strategy) == nil {
+			return models.Delegation{}, http.StatusBadRequest, errors.New("Strategy not found.")
+		}
+	}
+
+	if err := h.validateBlocklist(d.Delegator, d.Community_id); err != nil {
+		msg := fmt.Sprintf("Address %v is on blocklist for community id %v.", d.Delegator, d.Community_id)
+		return models.Delegation{}, http.StatusForbidden, errors.New(msg)
+	}
+
+	if err := d.ValidateMessage(); err != nil {
+		return models.Delegation{}, http.StatusBadRequest, err
+	}
+
+	if err := h.validateUserSignature(d.Delegator, d.Message, d.Composite_signatures); err != nil {
+		return models.Delegation{}, http.StatusForbidden, err
+	}
+
+	if err := d.CreateDelegation(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error creating delegation.")
+		return models.Delegation{}, http.StatusInternalServerError, err
+	}
+
+	proposals, err := models.GetLiveProposalsVotedOnBy(h.A.DB, d.Community_id, d.Delegate)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error getting proposals voted on by %s.", d.Delegate)
+	}
+	for _, p := range proposals {
+		h.cacheDelegatedBalances(*p, d.Delegate)
+	}
+
+	return d, http.StatusCreated, nil
+}
+
+func (h *Helpers) revokeDelegation(payload models.RevokeDelegationPayload) (models.Delegation, int, error) {
+	d := models.Delegation{ID: payload.ID}
+	if err := d.GetDelegationById(h.A.DB); err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			msg := fmt.Sprintf("Delegation with ID %d not found.", payload.ID)
+			return models.Delegation{}, http.StatusNotFound, errors.New(msg)
+		}
+		return models.Delegation{}, http.StatusInternalServerError, err
+	}
+
+	if d.Community_id != payload.Community_id || d.Revoked_at != nil {
+		msg := fmt.Sprintf("Delegation with ID %d not found.", payload.ID)
+		return models.Delegation{}, http.StatusNotFound, errors.New(msg)
+	}
+
+	if d.Delegator != payload.Delegator {
+		return models.Delegation{}, http.StatusForbidden, errors.New("Only the delegator can revoke a delegation.")
+	}
+
+	if err := payload.ValidateMessage(); err != nil {
+		return models.Delegation{}, http.StatusBadRequest, err
+	}
+
+	if err := h.validateUserSignature(payload.Delegator, payload.Message, payload.Composite_signatures); err != nil {
+		return models.Delegation{}, http.StatusForbidden, err
+	}
+
+	if err := d.RevokeDelegation(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error revoking delegation.")
+		return models.Delegation{}, http.StatusInternalServerError, err
+	}
+
+	return d, http.StatusOK, nil
+}
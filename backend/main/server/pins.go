@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	pinLease       = 2 * time.Minute
+	pinBatchSize   = 50
+	pinMaxAttempts = 10
+	pinBaseBackoff = 30 * time.Second
+)
+
+// Delay before the next attempt, doubling with each failed attempt.
+func pinBackoff(attempts int) time.Duration {
+	return pinBaseBackoff * time.Duration(math.Pow(2, float64(attempts-1)))
+}
+
+// Pins data to IPFS. If pinning fails, a job holding the data is
+// returned instead of a cid, so the record can be saved with a pending
+// pin status and queued with queuePinJob.
+func (h *Helpers) pinOrQueue(data interface{}) (*string, *models.PinJob, error) {
+	cid, err := h.pinJSONToIpfs(data)
+	if err == nil {
+		return cid, nil, nil
+	}
+	log.Error().Err(err).Msg("IPFS error, queueing pin: " + err.Error())
+
+	// keep the data as it is now, as the record is changed once saved
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	return nil, &models.PinJob{
+		Payload:         payload,
+		Status:          models.PinPending,
+		Next_attempt_at: &now,
+	}, nil
+}
+
+// Queues the job for the saved record. The scheduler sets the record's
+// cid once the job is pinned.
+func (h *Helpers) queuePinJob(job *models.PinJob, communityId int, recordType string, recordId int) {
+	job.Community_id = communityId
+	job.Record_type = recordType
+	job.Record_id = recordId
+
+	if err := job.CreatePinJob(h.A.DB); err != nil {
+		log.Error().Err(err).Msgf("Error queueing pin for %s %d.", recordType, recordId)
+	}
+}
+
+// Retries pending pins that are due.
+func (h *Helpers) retryPendingPins() {
+	jobs, err := models.ClaimDuePinJobs(h.A.DB, pinBatchSize, pinLease)
+	if err != nil {
+		log.Error().Err(err).Msg("Error claiming pin jobs.")
+		return
+	}
+
+	for _, j := range jobs {
+		h.attemptPin(*j)
+	}
+}
+
+func (h *Helpers) attemptPin(j models.PinJob) {
+	j.Attempts++
+
+	cid, err := h.pinJSONToIpfs(j.Payload)
+	if err == nil {
+		if err := j.CompletePinJob(h.A.DB, *cid); err != nil {
+			log.Error().Err(err).Msgf("Error completing pin job %d.", j.ID)
+		}
+		return
+	}
+
+	errMsg := err.Error()
+	j.Error = &errMsg
+
+	if j.Attempts >= pinMaxAttempts {
+		j.Status = models.PinFailed
+		j.Next_attempt_at = nil
+	} else {
+		next := time.Now().UTC().Add(pinBackoff(j.Attempts))
+		j.Next_attempt_at = &next
+	}
+	log.Error().Err(err).Msgf("Pin job %d for %s %d failed, attempt %d.", j.ID, j.Record_type, j.Record_id, j.Attempts)
+
+	if err := j.UpdatePinJob(h.A.DB); err != nil {
+		log.Error().Err(err).Msgf("Error updating pin job %d.", j.ID)
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	resultsTxBatchSize   = 10
+	resultsTxMaxAttempts = 5
+	resultsTxBaseBackoff = time.Minute
+	// longer than the ~600 blocks after which an unexecuted transaction
+	// expires, so a transaction still unsealed by then never will be
+	resultsTxExpiry = 15 * time.Minute
+)
+
+// Submits the final results of finalized proposals to the VotingCommunity
+// contract and tracks each transaction until it seals. Storing results is
+// idempotent on-chain, so failed submissions are safely retried.
+func (h *Helpers) submitPendingResults() {
+	if !h.A.FlowAdapter.CanSubmitResults() {
+		return
+	}
+
+	proposals, err := models.GetProposalsPendingResultsTx(h.A.DB, resultsTxMaxAttempts, resultsTxBatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting proposals pending on-chain results.")
+		return
+	}
+
+	for _, p := range proposals {
+		if err := h.submitResults(p); err != nil {
+			log.Error().Err(err).Msgf("Error submitting on-chain results for proposal %d.", p.ID)
+		}
+	}
+}
+
+func (h *Helpers) submitResults(p *models.Proposal) error {
+	// a transaction already sent is waited on rather than resent
+	if p.Results_tx_status != nil && *p.Results_tx_status == models.ResultsTxSubmitted && p.Results_tx_id != nil {
+		return h.waitForResultsTx(p)
+	}
+
+	community, _, err := h.fetchCommunity(p.Community_id)
+	if err != nil {
+		return err
+	}
+
+	results := models.ProposalResults{Proposal_id: p.ID}
+	if err := results.GetLatestProposalResultsById(h.A.DB); err != nil {
+		return err
+	}
+
+	p.Results_tx_attempts++
+	txId, err := h.A.FlowAdapter.SubmitResults(
+		*community.Onchain_id,
+		uint64(p.ID),
+		formatOnchainResults(p, results),
+	)
+	if err != nil {
+		return p.FailResultsTx(h.A.DB, err, resultsTxBackoff(p.Results_tx_attempts))
+	}
+
+	id := txId.String()
+	status := models.ResultsTxSubmitted
+	submittedAt := time.Now().UTC()
+	p.Results_tx_id = &id
+	p.Results_tx_submitted_at = &submittedAt
+	p.Results_tx_status = &status
+	p.Results_tx_error = nil
+	p.Results_tx_retry_at = nil
+	if err := p.UpdateResultsTx(h.A.DB); err != nil {
+		return err
+	}
+
+	return h.waitForResultsTx(p)
+}
+
+func (h *Helpers) waitForResultsTx(p *models.Proposal) error {
+	result, err := h.A.FlowAdapter.WaitForTransaction(*p.Results_tx_id)
+	if err != nil {
+		// a transaction that executed with an error or expired is
+		// resubmitted, as is one that has not sealed long after it was
+		// sent, otherwise it is waited on again on the next run
+		if result != nil || resultsTxTimedOut(p) {
+			return p.FailResultsTx(h.A.DB, err, resultsTxBackoff(p.Results_tx_attempts))
+		}
+		return err
+	}
+
+	status := models.ResultsTxSealed
+	p.Results_tx_status = &status
+	p.Results_tx_error = nil
+	if err := p.UpdateResultsTx(h.A.DB); err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Results for proposal %d sealed in transaction %s.", p.ID, *p.Results_tx_id)
+	return nil
+}
+
+// Results are stored on-chain as "<choice>:<weight>", in the
+// order of the proposal's choices.
+func formatOnchainResults(p *models.Proposal, r models.ProposalResults) []string {
+	results := make([]string, 0, len(p.Choices))
+	for _, c := range p.Choices {
+		weight := strconv.FormatFloat(r.Results_float[c.Choice_text], 'f', -1, 64)
+		results = append(results, c.Choice_text+":"+weight)
+	}
+	return results
+}
+
+func resultsTxTimedOut(p *models.Proposal) bool {
+	return p.Results_tx_submitted_at == nil ||
+		time.Since(*p.Results_tx_submitted_at) > resultsTxExpiry
+}
+
+func resultsTxBackoff(attempts int) time.Duration {
+	return resultsTxBaseBackoff * time.Duration(math.Pow(2, float64(attempts-1)))
+}
@@ -18,11 +18,18 @@ func (a *App) initializeRoutes() {
 	// Proposals
 	a.Router.HandleFunc("/proposals/{id:[0-9]+}", a.getProposal).Methods("GET")
 	a.Router.HandleFunc("/proposals/{id:[0-9]+}", a.updateProposal).Methods("PUT", "OPTIONS")
+	a.Router.HandleFunc("/proposals/{id:[0-9]+}/draft", a.updateDraftProposal).Methods("PUT", "OPTIONS")
+	a.Router.HandleFunc("/proposals/{id:[0-9]+}", a.editProposal).Methods("PATCH", "OPTIONS")
+	a.Router.HandleFunc("/proposals/{id:[0-9]+}/revisions", a.getProposalRevisions).Methods("GET")
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/proposals", a.getProposalsForCommunity).Methods("GET")
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/proposals/{id:[0-9]+}", a.getProposal).Methods("GET")
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/proposals", a.createProposal).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/proposals/export", a.exportProposalsForCommunity).
+		Methods("GET")
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/proposals/{id:[0-9]+}", a.updateProposal).
 		Methods("PUT", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/proposals/{id:[0-9]+}/draft", a.updateDraftProposal).
+		Methods("PUT", "OPTIONS")
 	// Lists
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/lists", a.getListsForCommunity).Methods("GET")
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/lists", a.createListForCommunity).Methods("POST", "OPTIONS")
@@ -33,10 +40,17 @@ func (a *App) initializeRoutes() {
 	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/votes", a.getVotesForProposal).Methods("GET")
 	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/votes/{addr:0x[a-zA-Z0-9]+}", a.getVoteForAddress).Methods("GET")
 	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/votes", a.createVoteForProposal).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/votes/export", a.exportVotesForProposal).Methods("GET")
 	a.Router.HandleFunc("/votes/{addr:0x[a-zA-Z0-9]+}", a.getVotesForAddress).Methods("GET")
+	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/votes/{addr:0x[a-fA-F0-9]{16}|0x[a-fA-F0-9]{40}}", a.updateVoteForProposal).
+		Methods("PUT", "OPTIONS")
+	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/votes/{addr:0x[a-fA-F0-9]{16}|0x[a-fA-F0-9]{40}}/history", a.getVoteHistory).
+		Methods("GET")
 	//Strategies
-	// a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/votes/{addr:0x[a-zA-Z0-9]{16}}", a.updateVoteForProposal).Methods("PUT", "OPTIONS")
 	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/results", a.getResultsForProposal)
+	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/transitions", a.getProposalTransitions).Methods("GET")
+	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/key", a.getProposalKey).Methods("GET")
+	a.Router.HandleFunc("/proposals/{proposalId:[0-9]+}/audit", a.getProposalAudit).Methods("GET")
 	// Types
 	a.Router.HandleFunc("/voting-strategies", a.getVotingStrategies).Methods("GET")
 	a.Router.HandleFunc("/community-categories", a.getCommunityCategories).Methods("GET")
@@ -49,6 +63,36 @@ func (a *App) initializeRoutes() {
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/users/{addr:0x[a-zA-Z0-9]{16}}/{userType:[a-zA-Z]+}", a.removeUserRole).
 		Methods("DELETE", "OPTIONS")
 	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/leaderboard", a.getCommunityLeaderboard).Methods("GET")
+
+	// Webhooks
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/webhooks", a.getWebhooksForCommunity).Methods("GET")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/webhooks", a.createWebhook).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/webhooks/{id:[0-9]+}", a.updateWebhook).Methods("PUT", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/webhooks/{id:[0-9]+}", a.deleteWebhook).Methods("DELETE", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/webhooks/{id:[0-9]+}/deliveries", a.getWebhookDeliveries).
+		Methods("GET")
+	// Pin Jobs
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/pin-jobs", a.getStuckPins).Methods("GET")
+	// Custom Scripts
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/scripts", a.getCommunityScripts).Methods("GET")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/scripts", a.createCommunityScript).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/scripts/{key:[a-z0-9-]+}", a.updateCommunityScript).
+		Methods("PUT", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/scripts/{key:[a-z0-9-]+}", a.deleteCommunityScript).
+		Methods("DELETE", "OPTIONS")
+	// Delegations
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/delegations", a.createDelegation).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/communities/{communityId:[0-9]+}/delegations/{id:[0-9]+}", a.revokeDelegation).
+		Methods("DELETE", "OPTIONS")
+	a.Router.HandleFunc("/users/{addr:0x[a-fA-F0-9]{16}|0x[a-fA-F0-9]{40}}/delegations/in", a.getDelegationsToAddress).
+		Methods("GET")
+	a.Router.HandleFunc("/users/{addr:0x[a-fA-F0-9]{16}|0x[a-fA-F0-9]{40}}/delegations/out", a.getDelegationsFromAddress).
+		Methods("GET")
+	// Sessions
+	a.Router.HandleFunc("/auth/nonce", a.createAuthNonce).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/auth/login", a.login).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/auth/logout", a.logout).Methods("POST", "OPTIONS")
+	a.Router.HandleFunc("/auth/sessions", a.revokeSessions).Methods("DELETE", "OPTIONS")
 	// Utilities
 	a.Router.HandleFunc("/accounts/admin", a.getAdminList).Methods("GET")
 	a.Router.HandleFunc("/accounts/blocklist", a.getCommunityBlocklist).Methods("GET")
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultSchedulerInterval = 30 * time.Second
+	retryBaseBackoff         = 30 * time.Second
+	retryMaxBackoff          = time.Hour
+	draftPublishMaxAttempts  = 10
+)
+
+// Scheduler runs proposal lifecycle work in the background. Each tick it
+// finds proposals whose computed status has changed, runs the work for
+// the new status and records the transition. Jobs run concurrently, and
+// a job still running from a previous tick is skipped.
+type Scheduler struct {
+	A        *App
+	Interval time.Duration
+	jobs     []*schedulerJob
+}
+
+type schedulerJob struct {
+	run     func()
+	running sync.Mutex
+}
+
+func NewScheduler(a *App) *Scheduler {
+	interval := defaultSchedulerInterval
+	if env := os.Getenv("SCHEDULER_INTERVAL"); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			log.Error().Err(err).Msgf("Invalid SCHEDULER_INTERVAL %s, using %s.", env, interval)
+		} else {
+			interval = d
+		}
+	}
+
+	sc := &Scheduler{A: a, Interval: interval}
+	for _, job := range []func(){
+		sc.ProcessTransitions,
+		sc.refreshSnapshots,
+		helpers.retryWebhookDeliveries,
+		helpers.retryPendingPins,
+		sc.SubmitPendingResults,
+		sc.PublishScheduledDrafts,
+		helpers.pruneSessions,
+	} {
+		sc.jobs = append(sc.jobs, &schedulerJob{run: job})
+	}
+	return sc
+}
+
+func (sc *Scheduler) Start() {
+	log.Info().Msgf("Starting scheduler, interval: %s", sc.Interval)
+	go func() {
+		ticker := time.NewTicker(sc.Interval)
+		defer ticker.Stop()
+
+		sc.tick()
+		for range ticker.C {
+			sc.tick()
+		}
+	}()
+}
+
+func (sc *Scheduler) tick() {
+	for _, job := range sc.jobs {
+		if !job.running.TryLock() {
+			continue
+		}
+		go func(job *schedulerJob) {
+			defer job.running.Unlock()
+			job.run()
+		}(job)
+	}
+}
+
+// Processes the proposals pending a transition. A proposal that fails
+// is retried with a backoff, so it does not hold up later proposals.
+func (sc *Scheduler) ProcessTransitions() {
+	proposals, err := models.GetProposalsPendingTransition(sc.A.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("Scheduler error getting proposals pending transition.")
+		return
+	}
+
+	for _, p := range proposals {
+		if err := helpers.processTransition(*p); err != nil {
+			log.Error().Err(err).Msgf("Scheduler error processing proposal %d.", p.ID)
+
+			if err := p.FailTransition(sc.A.DB, retryBackoff(p.Transition_attempts+1)); err != nil {
+				log.Error().Err(err).Msgf("Scheduler error recording failed transition of proposal %d.", p.ID)
+			}
+		}
+	}
+}
+
+// Delay before the next attempt, doubling with each failed attempt up
+// to an hour.
+func retryBackoff(attempts int) time.Duration {
+	backoff := retryBaseBackoff * time.Duration(math.Pow(2, float64(attempts-1)))
+	if backoff <= 0 || backoff > retryMaxBackoff {
+		return retryMaxBackoff
+	}
+	return backoff
+}
+
+// Submits the final results of finalized proposals on-chain, if a
+// signer is configured.
+func (sc *Scheduler) SubmitPendingResults() {
+	helpers.submitPendingResults()
+}
+
+// Publishes drafts whose scheduled publish time has passed.
+func (sc *Scheduler) PublishScheduledDrafts() {
+	helpers.publishScheduledDrafts()
+}
+
+func (sc *Scheduler) refreshSnapshots() {
+	proposals, err := models.GetProposalsWithProcessingSnapshot(sc.A.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("Scheduler error getting proposals with processing snapshots.")
+		return
+	}
+
+	for _, p := range proposals {
+		if err := helpers.refreshSnapshotStatus(p); err != nil {
+			log.Error().Err(err).Msgf("Scheduler error refreshing snapshot for proposal %d.", p.ID)
+		}
+	}
+}
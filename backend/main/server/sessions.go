@@ -0,0 +1,282 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	authNonceTTL = 5 * time.Minute
+	// nonces and session tokens, in bytes
+	tokenLength = 32
+)
+
+type AuthNonceResponse struct {
+	models.AuthNonce
+	App_identifier string `json:"appIdentifier"`
+}
+
+// Generates a random, hex encoded nonce or session token.
+func generateToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(auth[7:])
+}
+
+// Issues a single use nonce for an account to sign in with. The app
+// identifier is returned with it, for FCL's account proof resolver.
+func (h *Helpers) createAuthNonce() (AuthNonceResponse, int, error) {
+	nonce, err := generateToken()
+	if err != nil {
+		log.Error().Err(err).Msg("Error generating nonce.")
+		return AuthNonceResponse{}, http.StatusInternalServerError, err
+	}
+
+	n := models.AuthNonce{
+		Nonce:      nonce,
+		Expires_at: time.Now().UTC().Add(authNonceTTL),
+	}
+	if err := n.CreateAuthNonce(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Database error creating nonce.")
+		return AuthNonceResponse{}, http.StatusInternalServerError, err
+	}
+
+	return AuthNonceResponse{AuthNonce: n, App_identifier: h.A.Config.AppIdentifier}, http.StatusCreated, nil
+}
+
+// Verifies an account proof of a nonce issued by createAuthNonce and
+// signs the account in to a new session.
+func (h *Helpers) login(payload models.LoginPayload) (models.Session, int, error) {
+	validate := validator.New()
+	if vErr := validate.Struct(payload); vErr != nil {
+		errMsg := "Invalid login payload."
+		log.Error().Err(vErr).Msg(errMsg)
+		return models.Session{}, http.StatusBadRequest, errors.New(errMsg)
+	}
+
+	// sessions sign as their address, which roles are matched against
+	// exactly
+	addr, err := shared.NormalizeFlowAddress(payload.Addr)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid login address.")
+		return models.Session{}, http.StatusBadRequest, err
+	}
+	payload.Addr = addr
+
+	if err := models.ConsumeAuthNonce(h.A.DB, payload.Nonce); err != nil {
+		if errors.Is(err, models.ErrInvalidNonce) {
+			return models.Session{}, http.StatusUnauthorized, err
+		}
+		log.Error().Err(err).Msg("Database error consuming nonce.")
+		return models.Session{}, http.StatusInternalServerError, err
+	}
+
+	if err := h.validateAccountProof(payload); err != nil {
+		log.Error().Err(err).Msgf("Invalid account proof for %s.", payload.Addr)
+		return models.Session{}, http.StatusUnauthorized, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		log.Error().Err(err).Msg("Error generating session token.")
+		return models.Session{}, http.StatusInternalServerError, err
+	}
+
+	sn := models.Session{
+		Addr:       payload.Addr,
+		Token:      token,
+		Expires_at: time.Now().UTC().Add(h.A.Config.SessionTTL),
+	}
+	if err := sn.CreateSession(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Database error creating session.")
+		return models.Session{}, http.StatusInternalServerError, err
+	}
+
+	return sn, http.StatusCreated, nil
+}
+
+func (h *Helpers) validateAccountProof(payload models.LoginPayload) error {
+	if !h.A.Config.Features["validateSigs"] {
+		return nil
+	}
+
+	return h.A.FlowAdapter.ValidateAccountProof(
+		payload.Addr,
+		payload.Nonce,
+		h.A.Config.AppIdentifier,
+		payload.Signatures,
+	)
+}
+
+// Returns the session of a request made with a bearer token, or nil
+// for requests made without one.
+func (h *Helpers) requestSession(r *http.Request) (*models.Session, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, nil
+	}
+	return models.GetActiveSession(h.A.DB, token)
+}
+
+// Binds the session of a request made with a bearer token to the
+// payload, so its signer is authenticated by the session. The signing
+// address defaults to the session's address.
+func (h *Helpers) bindSession(r *http.Request, payload *shared.TimestampSignaturePayload) error {
+	sn, err := h.requestSession(r)
+	if err != nil {
+		if !errors.Is(err, models.ErrInvalidSession) {
+			log.Error().Err(err).Msg("Database error reading session.")
+		}
+		return models.ErrInvalidSession
+	}
+	if sn == nil {
+		return nil
+	}
+
+	payload.Session_addr = sn.Addr
+	if payload.Signing_addr == "" {
+		payload.Signing_addr = sn.Addr
+	}
+	return nil
+}
+
+// Reads the signer of a request without a body, such as a GET, from
+// its session, or from a signed timestamp sent in the X-Signing-Addr,
+// X-Timestamp and X-Composite-Signatures headers.
+func (h *Helpers) requestSigner(r *http.Request) (shared.TimestampSignaturePayload, error) {
+	payload := shared.TimestampSignaturePayload{
+		Signing_addr: r.Header.Get("X-Signing-Addr"),
+		Timestamp:    r.Header.Get("X-Timestamp"),
+	}
+	if sigs := r.Header.Get("X-Composite-Signatures"); sigs != "" {
+		if err := json.Unmarshal([]byte(sigs), &payload.Composite_signatures); err != nil {
+			return payload, errors.New("Invalid composite signatures.")
+		}
+	}
+
+	if err := h.bindSession(r, &payload); err != nil {
+		return payload, err
+	}
+	if payload.Session_addr == "" && payload.Composite_signatures == nil {
+		return payload, errors.New("A session or signature is required.")
+	}
+
+	return payload, nil
+}
+
+// Authenticates the signer of a request without a body and checks that
+// they have the role in the community.
+func (h *Helpers) authorizeRequest(r *http.Request, communityId int, role string) (int, error) {
+	payload, err := h.requestSigner(r)
+	if err != nil {
+		return http.StatusUnauthorized, err
+	}
+	if err := h.validateSignerWithRole(payload, nil, communityId, role); err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
+
+// Signs the account out of the request's session, or out of all its
+// sessions.
+func (h *Helpers) logout(r *http.Request, all bool) (int, error) {
+	sn, err := h.requestSession(r)
+	if err != nil || sn == nil {
+		return http.StatusUnauthorized, models.ErrInvalidSession
+	}
+
+	if all {
+		_, err = models.RevokeSessionsForAddr(h.A.DB, sn.Addr)
+	} else {
+		err = sn.RevokeSession(h.A.DB)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Database error revoking sessions for %s.", sn.Addr)
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// Authenticates the signer of a payload. Payloads bound to a session are
+// authenticated by it, and must be signed by the session's address.
+// Others are authenticated by their voucher or signed timestamp.
+func (h *Helpers) validateSigner(payload shared.TimestampSignaturePayload, voucher *shared.Voucher) error {
+	if payload.Session_addr != "" {
+		return validateSessionSigner(payload)
+	}
+	if voucher != nil {
+		return h.validateUserViaVoucher(payload.Signing_addr, voucher)
+	}
+	return h.validateUser(payload.Signing_addr, payload.Timestamp, payload.Composite_signatures)
+}
+
+// Authenticates the signer of a payload as validateSigner does, and
+// checks that they have the role in the community.
+func (h *Helpers) validateSignerWithRole(
+	payload shared.TimestampSignaturePayload,
+	voucher *shared.Voucher,
+	communityId int,
+	role string,
+) error {
+	if payload.Session_addr == "" {
+		if voucher != nil {
+			return h.validateUserWithRoleViaVoucher(payload.Signing_addr, voucher, communityId, role)
+		}
+		return h.validateUserWithRole(
+			payload.Signing_addr,
+			payload.Timestamp,
+			payload.Composite_signatures,
+			communityId,
+			role,
+		)
+	}
+
+	if err := validateSessionSigner(payload); err != nil {
+		return err
+	}
+	if err := models.EnsureRoleForCommunity(h.A.DB, payload.Signing_addr, communityId, role); err != nil {
+		errMsg := fmt.Sprintf("Account %s is not an %s for community %d.", payload.Signing_addr, role, communityId)
+		log.Error().Err(err).Msg(errMsg)
+		return err
+	}
+
+	return nil
+}
+
+// A session only authenticates the address it was signed in to.
+func validateSessionSigner(payload shared.TimestampSignaturePayload) error {
+	signingAddr := strings.TrimPrefix(payload.Signing_addr, "0x")
+	if !strings.EqualFold(signingAddr, strings.TrimPrefix(payload.Session_addr, "0x")) {
+		return fmt.Errorf("session is for %s, not signing address %s", payload.Session_addr, payload.Signing_addr)
+	}
+	return nil
+}
+
+// Deletes expired nonces and sessions.
+func (h *Helpers) pruneSessions() {
+	if err := models.DeleteExpiredSessions(h.A.DB); err != nil {
+		log.Error().Err(err).Msg("Error deleting expired sessions.")
+	}
+}
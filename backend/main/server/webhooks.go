@@ -0,0 +1,186 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	webhookTimeout      = 10 * time.Second
+	webhookLease        = 2 * time.Minute
+	webhookBatchSize    = 50
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = 30 * time.Second
+	webhookSecretLength = 32
+)
+
+// Webhook URLs are given by community admins, so deliveries are only
+// made to public addresses, checked when dialing so that hostnames
+// resolving to internal addresses are refused, and redirects are not
+// followed.
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: webhookTimeout,
+			Control: shared.PublicDialControl,
+		}).DialContext,
+		TLSHandshakeTimeout: webhookTimeout,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Signs a webhook body as HMAC-SHA256 of "<timestamp>.<body>"
+// keyed with the webhook's secret.
+func signWebhookBody(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Delay before the next attempt, doubling with each failed attempt.
+func webhookBackoff(attempts int) time.Duration {
+	return webhookBaseBackoff * time.Duration(math.Pow(2, float64(attempts-1)))
+}
+
+// Queues a delivery of the event to every active webhook of the
+// community subscribed to it, and attempts each delivery right away.
+// Failed deliveries are retried by the scheduler.
+func (h *Helpers) dispatchWebhookEvent(communityId int, event string, data interface{}) {
+	webhooks, err := models.GetActiveWebhooksForEvent(h.A.DB, communityId, event)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error getting webhooks for community %d.", communityId)
+		return
+	}
+
+	payload := models.WebhookEvent{
+		Event:        event,
+		Community_id: communityId,
+		Data:         data,
+		Created_at:   time.Now().UTC(),
+	}
+
+	for _, w := range webhooks {
+		leaseUntil := time.Now().UTC().Add(webhookLease)
+		d := models.WebhookDelivery{
+			Webhook_id:      w.ID,
+			Event:           event,
+			Payload:         payload,
+			Status:          models.DeliveryPending,
+			Next_attempt_at: &leaseUntil,
+		}
+		if err := d.CreateWebhookDelivery(h.A.DB); err != nil {
+			log.Error().Err(err).Msgf("Error queueing webhook delivery for webhook %d.", w.ID)
+			continue
+		}
+
+		go h.attemptWebhookDelivery(*w, d)
+	}
+}
+
+// Retries pending deliveries that are due.
+func (h *Helpers) retryWebhookDeliveries() {
+	deliveries, err := models.ClaimDueWebhookDeliveries(h.A.DB, webhookBatchSize, webhookLease)
+	if err != nil {
+		log.Error().Err(err).Msg("Error claiming webhook deliveries.")
+		return
+	}
+
+	for _, d := range deliveries {
+		w := models.Webhook{ID: d.Webhook_id}
+		if err := w.GetWebhookById(h.A.DB); err != nil {
+			log.Error().Err(err).Msgf("Error getting webhook %d.", d.Webhook_id)
+			continue
+		}
+		h.attemptWebhookDelivery(w, *d)
+	}
+}
+
+func (h *Helpers) attemptWebhookDelivery(w models.Webhook, d models.WebhookDelivery) {
+	d.Attempts++
+
+	statusCode, err := postWebhook(w, d)
+	if statusCode != 0 {
+		d.Response_status = &statusCode
+	}
+
+	if err == nil {
+		now := time.Now().UTC()
+		d.Status = models.DeliveryDelivered
+		d.Delivered_at = &now
+		d.Next_attempt_at = nil
+		d.Error = nil
+	} else {
+		errMsg := err.Error()
+		d.Error = &errMsg
+
+		if d.Attempts >= webhookMaxAttempts || !w.Is_active {
+			d.Status = models.DeliveryFailed
+			d.Next_attempt_at = nil
+		} else {
+			next := time.Now().UTC().Add(webhookBackoff(d.Attempts))
+			d.Next_attempt_at = &next
+		}
+		log.Error().Err(err).Msgf("Webhook delivery %d failed, attempt %d.", d.ID, d.Attempts)
+	}
+
+	if err := d.UpdateWebhookDelivery(h.A.DB); err != nil {
+		log.Error().Err(err).Msgf("Error updating webhook delivery %d.", d.ID)
+	}
+}
+
+func postWebhook(w models.Webhook, d models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(d.Payload)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UTC().Unix(), 10)
+
+	req, err := http.NewRequest("POST", w.Url, bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-CAST-Event", d.Event)
+	req.Header.Set("X-CAST-Delivery", strconv.Itoa(d.ID))
+	req.Header.Set("X-CAST-Timestamp", timestamp)
+	req.Header.Set("X-CAST-Signature", signWebhookBody(w.Secret, timestamp, body))
+
+	res, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*   Sessions    */
+/*****************/
+
+func TestEncodeAccountProof(t *testing.T) {
+	// FCL's encodeAccountProof test vector
+	message, err := shared.EncodeAccountProof(
+		"0xABC123DEF456",
+		"3037366134636339643564623330316636626239323161663465346131393662",
+		"AWESOME-APP-ID",
+	)
+	assert.Nil(t, err)
+	assert.Equal(t,
+		"f8398e415745534f4d452d4150502d4944880000abc123def456a03037366134636339643564623330316636626239323161663465346131393662",
+		message,
+	)
+
+	_, err = shared.EncodeAccountProof("0xABC123DEF456", "abcd", "AWESOME-APP-ID")
+	assert.NotNil(t, err)
+}
+
+func TestSessions(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("lists")
+	clearTable("auth_nonces")
+	clearTable("sessions")
+
+	account, _ := otu.O.State.Accounts().ByName("emulator-user1")
+	address := fmt.Sprintf("0x%s", account.Address().String())
+
+	t.Run("Should sign in with an account proof", func(t *testing.T) {
+		payload := otu.GenerateLoginPayload("user1", address, otu.CreateAuthNonce())
+		response := otu.LoginAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var session models.Session
+		json.Unmarshal(response.Body.Bytes(), &session)
+		assert.Equal(t, address, session.Addr)
+		assert.NotEmpty(t, session.Token)
+	})
+
+	t.Run("Should sign in as the lowercase address", func(t *testing.T) {
+		mixedCase := "0x" + strings.ToUpper(account.Address().String())
+		payload := otu.GenerateLoginPayload("user1", mixedCase, otu.CreateAuthNonce())
+		response := otu.LoginAPI(payload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var session models.Session
+		json.Unmarshal(response.Body.Bytes(), &session)
+		assert.Equal(t, address, session.Addr)
+	})
+
+	t.Run("Should not sign in with a malformed address", func(t *testing.T) {
+		payload := otu.GenerateLoginPayload("user1", address+"00", otu.CreateAuthNonce())
+		response := otu.LoginAPI(payload)
+		CheckResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Should not sign in twice with a nonce", func(t *testing.T) {
+		payload := otu.GenerateLoginPayload("user1", address, otu.CreateAuthNonce())
+		otu.LoginAPI(payload)
+
+		response := otu.LoginAPI(payload)
+		CheckResponseCode(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("Should not sign in with another account's proof", func(t *testing.T) {
+		payload := otu.GenerateLoginPayload("user2", address, otu.CreateAuthNonce())
+		response := otu.LoginAPI(payload)
+		CheckResponseCode(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("A session should authenticate admin writes without a signature", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		token := otu.Login("user1")
+
+		payload := models.ListPayload{List: *otu.GenerateBlockListStruct(communityId)}
+		response := otu.CreateListWithSessionAPI(&payload, token)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+	})
+
+	t.Run("A session should only authenticate its own address", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user2")[0]
+		token := otu.Login("user1")
+
+		other, _ := otu.O.State.Accounts().ByName("emulator-user2")
+		payload := models.ListPayload{List: *otu.GenerateBlockListStruct(communityId)}
+		payload.Signing_addr = fmt.Sprintf("0x%s", other.Address().String())
+
+		response := otu.CreateListWithSessionAPI(&payload, token)
+		CheckResponseCode(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("A signed out session should be rejected", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		token := otu.Login("user1")
+
+		response := otu.LogoutAPI(token)
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		payload := models.ListPayload{List: *otu.GenerateBlockListStruct(communityId)}
+		response = otu.CreateListWithSessionAPI(&payload, token)
+		CheckResponseCode(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("Revoking sessions should sign the account out of all of them", func(t *testing.T) {
+		first := otu.Login("user1")
+		second := otu.Login("user1")
+
+		response := otu.RevokeSessionsAPI(first)
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		response = otu.LogoutAPI(second)
+		CheckResponseCode(t, http.StatusUnauthorized, response.Code)
+	})
+}
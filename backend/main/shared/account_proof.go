@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// The domain tag wallets sign FCL account proofs with.
+const AccountProofDomainTag = "FCL-ACCOUNT-PROOF-V0.0"
+
+// FCL requires account proof nonces of at least 32 bytes.
+const AccountProofNonceLength = 32
+
+// Encodes an account proof as the hex encoded message wallets sign, as
+// FCL's encodeAccountProof does without the domain tag.
+func EncodeAccountProof(address, nonce, appIdentifier string) (string, error) {
+	nonceBytes, err := hex.DecodeString(nonce)
+	if err != nil || len(nonceBytes) < AccountProofNonceLength {
+		return "", errors.New("account proof nonce must be at least 32 hex encoded bytes")
+	}
+
+	return rlpEncode([]interface{}{
+		appIdentifier,
+		addressBuffer(sansPrefix(address)),
+		nonceBytes,
+	}), nil
+}
+
+// Verifies that the account signed the nonce for the app, with keys
+// of full weight.
+func (fa *FlowAdapter) ValidateAccountProof(address, nonce, appIdentifier string, sigs *[]CompositeSignature) error {
+	message, err := EncodeAccountProof(address, nonce, appIdentifier)
+	if err != nil {
+		return err
+	}
+
+	return fa.ValidateSignature(address, message, sigs, "ACCOUNT_PROOF")
+}
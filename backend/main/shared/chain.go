@@ -0,0 +1,166 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/rs/zerolog/log"
+)
+
+// Chains a strategy's contract can be on. Contracts without a chain are
+// on Flow.
+const (
+	FlowChainKey = "flow"
+	EVMChainKey  = "evm"
+)
+
+// Reads the balances, NFTs and signatures voting strategies use from a
+// blockchain. A blockHeight of 0 reads at the latest block.
+type ChainAdapter interface {
+	// The addr's balance of the contract's fungible token, in tokens.
+	GetBalanceAtBlock(addr string, c *Contract, blockHeight uint64) (float64, error)
+	// The IDs of the contract's NFTs owned by addr.
+	GetNFTIdsAtBlock(addr string, c *Contract, blockHeight uint64) ([]interface{}, error)
+	// Returns an error unless sigs are addr's signature of the hex
+	// encoded message.
+	ValidateSignature(addr, message string, sigs *[]CompositeSignature) error
+	GetCurrentBlockHeight() (int, error)
+}
+
+// The chains the server can read from. EVM chains are configured by
+// chain ID with EVM_RPC_URLS, e.g. "1=https://rpc.example,31337=http://127.0.0.1:8545".
+type Chains struct {
+	Flow ChainAdapter
+	EVM  map[string]ChainAdapter
+}
+
+func NewChains(fa *FlowAdapter, evmRpcUrls string) *Chains {
+	chains := &Chains{
+		Flow: &FlowChain{Fa: fa},
+		EVM:  map[string]ChainAdapter{},
+	}
+
+	for _, entry := range strings.Split(evmRpcUrls, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			log.Error().Msgf("Invalid EVM_RPC_URLS entry %s, expected <chainId>=<url>.", entry)
+			continue
+		}
+		chains.EVM[parts[0]] = NewEVMAdapter(parts[1])
+		log.Info().Msgf("EVM chain %s: %s", parts[0], parts[1])
+	}
+
+	return chains
+}
+
+// Returns the adapter for the chain the contract is on.
+func (ch *Chains) ForContract(c *Contract) (ChainAdapter, error) {
+	switch c.ChainKey() {
+	case FlowChainKey:
+		return ch.Flow, nil
+	case EVMChainKey:
+		if c.Chain_id == nil {
+			return nil, errors.New("evm contracts require a chainId")
+		}
+		adapter, ok := ch.EVM[*c.Chain_id]
+		if !ok {
+			return nil, fmt.Errorf("evm chain %s is not configured", *c.Chain_id)
+		}
+		return adapter, nil
+	default:
+		return nil, fmt.Errorf("unsupported chain: %s", c.ChainKey())
+	}
+}
+
+func (c *Contract) ChainKey() string {
+	if c.Chain == nil || *c.Chain == "" {
+		return FlowChainKey
+	}
+	return *c.Chain
+}
+
+func (c *Contract) IsEVM() bool {
+	return c.ChainKey() == EVMChainKey
+}
+
+// Reads from Flow through the FlowAdapter.
+type FlowChain struct {
+	Fa *FlowAdapter
+}
+
+func (fc *FlowChain) GetBalanceAtBlock(addr string, c *Contract, blockHeight uint64) (float64, error) {
+	if c.Name == nil || c.Addr == nil || c.Public_path == nil {
+		return 0, errors.New("flow token contracts require name, addr and publicPath")
+	}
+
+	script, err := ioutil.ReadFile("./main/cadence/scripts/get_balance.cdc")
+	if err != nil {
+		log.Error().Err(err).Msgf("Error reading cadence script file.")
+		return 0, err
+	}
+	script = fc.Fa.ReplaceContractPlaceholders(string(script[:]), c, true)
+
+	value, err := fc.executeScript(script, []cadence.Value{
+		cadence.Path{Domain: "public", Identifier: *c.Public_path},
+		cadence.NewAddress(flow.HexToAddress(addr)),
+	}, blockHeight)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing Funigble-Token Script.")
+		return 0, err
+	}
+
+	balance, err := strconv.ParseFloat(CadenceValueToInterface(value).(string), 64)
+	if err != nil {
+		log.Error().Err(err).Msg("Error converting cadence value to float.")
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (fc *FlowChain) GetNFTIdsAtBlock(addr string, c *Contract, blockHeight uint64) ([]interface{}, error) {
+	if c.Name == nil || c.Addr == nil || c.Public_path == nil {
+		return nil, errors.New("flow NFT contracts require name, addr and publicPath")
+	}
+
+	script, err := ioutil.ReadFile("./main/cadence/scripts/get_nfts_ids.cdc")
+	if err != nil {
+		log.Error().Err(err).Msgf("Error reading cadence script file.")
+		return nil, err
+	}
+	script = fc.Fa.ReplaceContractPlaceholders(string(script[:]), c, false)
+
+	value, err := fc.executeScript(script, []cadence.Value{
+		cadence.NewAddress(flow.HexToAddress(addr)),
+	}, blockHeight)
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing script.")
+		return nil, err
+	}
+
+	nftIds, _ := CadenceValueToInterface(value).([]interface{})
+	return nftIds, nil
+}
+
+func (fc *FlowChain) ValidateSignature(addr, message string, sigs *[]CompositeSignature) error {
+	return fc.Fa.ValidateSignature(addr, message, sigs, "USER")
+}
+
+func (fc *FlowChain) GetCurrentBlockHeight() (int, error) {
+	return fc.Fa.GetCurrentBlockHeight()
+}
+
+func (fc *FlowChain) executeScript(script []byte, args []cadence.Value, blockHeight uint64) (cadence.Value, error) {
+	if blockHeight > 0 {
+		return fc.Fa.Client.ExecuteScriptAtBlockHeight(fc.Fa.Context, blockHeight, script, args)
+	}
+	return fc.Fa.Client.ExecuteScriptAtLatestBlock(fc.Fa.Context, script, args)
+}
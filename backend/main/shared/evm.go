@@ -0,0 +1,219 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+// Reads ERC-20 balances and ERC-721 NFTs from an EVM chain with
+// eth_call over JSON-RPC, and verifies EIP-191 personal_sign signatures.
+type EVMAdapter struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// The most NFTs read for an address, as ERC-721 token IDs are read one
+// eth_call at a time.
+const maxEVMNFTs = 1000
+
+var (
+	// capped at their length so appending arguments copies them
+	erc20Decimals           = crypto.Keccak256([]byte("decimals()"))[:4:4]
+	ercBalanceOf            = crypto.Keccak256([]byte("balanceOf(address)"))[:4:4]
+	erc721TokenOfOwnerByIdx = crypto.Keccak256([]byte("tokenOfOwnerByIndex(address,uint256)"))[:4:4]
+)
+
+// The length of a 0x-prefixed EVM address.
+const EVMAddressLength = 42
+
+var evmAddress = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// Returns the lowercase form of an EVM address, so addresses signed
+// with and without a checksum are stored alike, or an error if it is
+// not 0x followed by 40 hex characters.
+func NormalizeEVMAddress(addr string) (string, error) {
+	if !evmAddress.MatchString(addr) {
+		return "", fmt.Errorf("invalid evm address: %s", addr)
+	}
+	return strings.ToLower(addr), nil
+}
+
+type rpcRequest struct {
+	Jsonrpc string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewEVMAdapter(url string) *EVMAdapter {
+	return &EVMAdapter{
+		URL: url,
+		HTTPClient: &http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+func (e *EVMAdapter) GetBalanceAtBlock(addr string, c *Contract, blockHeight uint64) (float64, error) {
+	if c.Addr == nil {
+		return 0, errors.New("evm token contracts require an addr")
+	}
+
+	balance, err := e.callUint(*c.Addr, append(ercBalanceOf, encodeAddress(addr)...), blockHeight)
+	if err != nil {
+		log.Error().Err(err).Msg("Error calling ERC-20 balanceOf.")
+		return 0, err
+	}
+	decimals, err := e.callUint(*c.Addr, erc20Decimals, blockHeight)
+	if err != nil {
+		log.Error().Err(err).Msg("Error calling ERC-20 decimals.")
+		return 0, err
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), decimals, nil)
+	tokens, _ := new(big.Float).Quo(new(big.Float).SetInt(balance), new(big.Float).SetInt(scale)).Float64()
+	return tokens, nil
+}
+
+func (e *EVMAdapter) GetNFTIdsAtBlock(addr string, c *Contract, blockHeight uint64) ([]interface{}, error) {
+	if c.Addr == nil {
+		return nil, errors.New("evm NFT contracts require an addr")
+	}
+
+	count, err := e.callUint(*c.Addr, append(ercBalanceOf, encodeAddress(addr)...), blockHeight)
+	if err != nil {
+		log.Error().Err(err).Msg("Error calling ERC-721 balanceOf.")
+		return nil, err
+	}
+	if count.Cmp(big.NewInt(maxEVMNFTs)) > 0 {
+		return nil, fmt.Errorf("address holds more than %d NFTs", maxEVMNFTs)
+	}
+
+	nftIds := []interface{}{}
+	for i := int64(0); i < count.Int64(); i++ {
+		data := append(append(erc721TokenOfOwnerByIdx, encodeAddress(addr)...), encodeUint(big.NewInt(i))...)
+		id, err := e.callUint(*c.Addr, data, blockHeight)
+		if err != nil {
+			log.Error().Err(err).Msg("Error calling ERC-721 tokenOfOwnerByIndex.")
+			return nil, err
+		}
+		nftIds = append(nftIds, id.String())
+	}
+
+	return nftIds, nil
+}
+
+func (e *EVMAdapter) ValidateSignature(addr, message string, sigs *[]CompositeSignature) error {
+	if !common.IsHexAddress(addr) {
+		return errors.New("invalid evm address")
+	}
+	if sigs == nil || len(*sigs) == 0 {
+		return errors.New("invalid signature")
+	}
+
+	msg, err := hex.DecodeString(message)
+	if err != nil {
+		msg = []byte(message)
+	}
+
+	sig, err := hexutil.Decode((*sigs)[0].Signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return errors.New("invalid signature")
+	}
+	// wallets sign with a recovery id of 27 or 28
+	sig = append([]byte{}, sig...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(accounts.TextHash(msg), sig)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+	if crypto.PubkeyToAddress(*pub) != common.HexToAddress(addr) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+func (e *EVMAdapter) GetCurrentBlockHeight() (int, error) {
+	var height hexutil.Uint64
+	if err := e.call("eth_blockNumber", []interface{}{}, &height); err != nil {
+		return 0, err
+	}
+	return int(height), nil
+}
+
+// Calls a contract function that returns a single uint256.
+func (e *EVMAdapter) callUint(to string, data []byte, blockHeight uint64) (*big.Int, error) {
+	block := "latest"
+	if blockHeight > 0 {
+		block = hexutil.EncodeUint64(blockHeight)
+	}
+
+	var result hexutil.Bytes
+	call := map[string]string{"to": to, "data": hexutil.Encode(data)}
+	if err := e.call("eth_call", []interface{}{call, block}, &result); err != nil {
+		return nil, err
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("unexpected eth_call result from %s", to)
+	}
+
+	return new(big.Int).SetBytes(result[:32]), nil
+}
+
+func (e *EVMAdapter) call(method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(rpcRequest{Jsonrpc: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	res, err := e.HTTPClient.Post(e.URL, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		log.Debug().Err(err).Msgf("EVM %s request error", method)
+		return err
+	}
+	defer res.Body.Close()
+
+	var r rpcResponse
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return fmt.Errorf("invalid %s response: %w", method, err)
+	}
+	if r.Error != nil {
+		return fmt.Errorf("%s error %d: %s", method, r.Error.Code, r.Error.Message)
+	}
+
+	return json.Unmarshal(r.Result, result)
+}
+
+func encodeAddress(addr string) []byte {
+	return common.LeftPadBytes(common.HexToAddress(strings.TrimSpace(addr)).Bytes(), 32)
+}
+
+func encodeUint(i *big.Int) []byte {
+	return common.LeftPadBytes(i.Bytes(), 32)
+}
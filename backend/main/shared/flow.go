@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"regexp"
@@ -27,6 +28,12 @@ type FlowAdapter struct {
 	CustomScriptsMap map[string]CustomScript
 	URL     string
 	Env     string
+	TxSigner *TxSigner
+	// how long a custom-script strategy's script may run
+	ScriptTimeout time.Duration
+
+	// the lowest block height the access node serves, once found
+	lowestHeight *blockHeightCache
 }
 
 type FlowContract struct {
@@ -47,6 +54,11 @@ type Contract struct {
 	MaxWeight      *float64 `json:"maxWeight,omitempty,string"`
 	Float_event_id *uint64  `json:"floatEventId,omitempty,string"`
 	Script         *string  `json:"script,omitempty"`
+	Quorum         *float64 `json:"quorum,omitempty,string"`
+	QuorumType     *string  `json:"quorumType,omitempty"`
+	PassThreshold  *float64 `json:"passThreshold,omitempty,string"`
+	Chain          *string  `json:"chain,omitempty"`
+	Chain_id       *string  `json:"chainId,omitempty"`
 }
 
 var (
@@ -59,11 +71,39 @@ var (
 	placeholderTopshotAddr          = regexp.MustCompile(`"[^"\s]*TOPSHOT_ADDRESS"`)
 )
 
+const defaultScriptTimeout = 10 * time.Second
+
+// Returned when a transaction expired before it was executed and so
+// will never seal.
+var ErrTransactionExpired = errors.New("transaction expired")
+
+var flowHexAddress = regexp.MustCompile(`^0x[0-9a-fA-F]{16}$`)
+
+// Returns the lowercase form of a Flow address, as accounts are stored
+// and looked up by, or an error if it is not 0x followed by 16 hex
+// characters.
+func NormalizeFlowAddress(addr string) (string, error) {
+	if !flowHexAddress.MatchString(addr) {
+		return "", fmt.Errorf("invalid flow address: %s", addr)
+	}
+	return strings.ToLower(addr), nil
+}
+
 func NewFlowClient(flowEnv string, customScriptsMap map[string]CustomScript) *FlowAdapter {
 	adapter := FlowAdapter{}
 	adapter.Context = context.Background()
 	adapter.Env = flowEnv
 	adapter.CustomScriptsMap = customScriptsMap
+	adapter.lowestHeight = &blockHeightCache{}
+	adapter.ScriptTimeout = defaultScriptTimeout
+	if env := os.Getenv("CUSTOM_SCRIPT_TIMEOUT"); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			log.Error().Err(err).Msgf("Invalid CUSTOM_SCRIPT_TIMEOUT %s, using %s.", env, adapter.ScriptTimeout)
+		} else {
+			adapter.ScriptTimeout = d
+		}
+	}
 	path := "./flow.json"
 
 	content, err := ioutil.ReadFile(path)
@@ -125,9 +165,12 @@ func (fa *FlowAdapter) ValidateSignature(address, message string, sigs *[]Compos
 	}
 
 	var domainSeparationTag string
-	if messageType == "TRANSACTION" {
+	switch messageType {
+	case "TRANSACTION":
 		domainSeparationTag = "FLOW-V0.0-transaction"
-	} else {
+	case "ACCOUNT_PROOF":
+		domainSeparationTag = AccountProofDomainTag
+	default:
 		domainSeparationTag = "FLOW-V0.0-user"
 	}
 
@@ -288,6 +331,36 @@ func (fa *FlowAdapter) GetFlowBalance(address string) (float64, error) {
 	return balance, nil
 }
 
+func (fa *FlowAdapter) GetTotalSupply(c *Contract, blockHeight uint64) (float64, error) {
+	script, err := ioutil.ReadFile("./main/cadence/scripts/get_total_supply.cdc")
+	if err != nil {
+		log.Error().Err(err).Msgf("Error reading cadence script file.")
+		return 0, err
+	}
+
+	script = fa.ReplaceContractPlaceholders(string(script[:]), c, true)
+
+	var cadenceValue cadence.Value
+	if blockHeight > 0 {
+		cadenceValue, err = fa.Client.ExecuteScriptAtBlockHeight(fa.Context, blockHeight, script, nil)
+	} else {
+		cadenceValue, err = fa.Client.ExecuteScriptAtLatestBlock(fa.Context, script, nil)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing Total Supply Script.")
+		return 0, err
+	}
+
+	value := CadenceValueToInterface(cadenceValue)
+	totalSupply, err := strconv.ParseFloat(value.(string), 64)
+	if err != nil {
+		log.Error().Err(err).Msg("Error converting cadence value to float.")
+		return 0, err
+	}
+
+	return totalSupply, nil
+}
+
 func (fa *FlowAdapter) GetNFTIds(voterAddr string, c *Contract, path string) ([]interface{}, error) {
 	flowAddress := flow.HexToAddress(voterAddr)
 	cadenceAddress := cadence.NewAddress(flowAddress)
@@ -318,6 +391,46 @@ func (fa *FlowAdapter) GetNFTIds(voterAddr string, c *Contract, path string) ([]
 	return nftIds, nil
 }
 
+// Runs the script of a custom-script strategy for the voter and returns
+// the IDs of the NFTs it counts. Scripts can be supplied by communities,
+// so they are cancelled after ScriptTimeout and must return an array.
+func (fa *FlowAdapter) GetCustomScriptNFTIds(voterAddr string, c *Contract, src string) ([]interface{}, error) {
+	if c.Name == nil || c.Addr == nil || c.Public_path == nil {
+		return nil, errors.New("custom script contract requires name, addr and publicPath")
+	}
+
+	flowAddress := flow.HexToAddress(voterAddr)
+	cadenceAddress := cadence.NewAddress(flowAddress)
+
+	script := fa.ReplaceContractPlaceholders(src, c, false)
+
+	ctx, cancel := context.WithTimeout(fa.Context, fa.ScriptTimeout)
+	defer cancel()
+
+	cadenceValue, err := fa.Client.ExecuteScriptAtLatestBlock(
+		ctx,
+		script,
+		[]cadence.Value{
+			cadenceAddress,
+		},
+	)
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("custom script timed out after %s", fa.ScriptTimeout)
+		log.Error().Err(err).Msg("Error executing custom script.")
+		return nil, err
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Error executing custom script.")
+		return nil, err
+	}
+
+	nftIds, ok := CadenceValueToInterface(cadenceValue).([]interface{})
+	if !ok {
+		return nil, errors.New("custom script must return an array of NFT IDs")
+	}
+	return nftIds, nil
+}
+
 func (fa *FlowAdapter) GetFloatNFTIds(voterAddr string, c *Contract) ([]interface{}, error) {
 	flowAddress := flow.HexToAddress(voterAddr)
 	cadenceAddress := cadence.NewAddress(flowAddress)
@@ -424,10 +537,10 @@ func (fa *FlowAdapter) ReplaceContractPlaceholders(code string, c *Contract, isF
 		topshotAddr          string
 	)
 
-	nonFungibleTokenAddr = fa.Config.Contracts["NonFungibleToken"].Aliases[os.Getenv("FLOW_ENV")]
-	fungibleTokenAddr = fa.Config.Contracts["FungibleToken"].Aliases[os.Getenv("FLOW_ENV")]
-	metadataViewsAddr = fa.Config.Contracts["MetadataViews"].Aliases[os.Getenv("FLOW_ENV")]
-	topshotAddr = fa.Config.Contracts["TopShot"].Aliases[os.Getenv("FLOW_ENV")]
+	nonFungibleTokenAddr = fa.Config.Contracts["NonFungibleToken"].Aliases[fa.Env]
+	fungibleTokenAddr = fa.Config.Contracts["FungibleToken"].Aliases[fa.Env]
+	metadataViewsAddr = fa.Config.Contracts["MetadataViews"].Aliases[fa.Env]
+	topshotAddr = fa.Config.Contracts["TopShot"].Aliases[fa.Env]
 
 	if isFungible {
 		code = placeholderFungibleTokenAddr.ReplaceAllString(code, fungibleTokenAddr)
@@ -455,6 +568,9 @@ func WaitForSeal(
 	}
 
 	for result.Status != flow.TransactionStatusSealed {
+		if result.Status == flow.TransactionStatusExpired {
+			return result, nil, ErrTransactionExpired
+		}
 		time.Sleep(time.Second)
 		result, err = c.GetTransactionResult(ctx, id)
 		if err != nil {
@@ -0,0 +1,161 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Returned, wrapped, for blocks the access node cannot be queried at.
+// Access nodes serve blocks from their spork's root block up to the
+// latest sealed block.
+var ErrBlockNotQueryable = errors.New("block is outside the access node's queryable range")
+
+// Returned, wrapped, for blocks or times after the latest sealed block.
+// These become queryable once the chain reaches them. It wraps
+// ErrBlockNotQueryable.
+var ErrBlockNotSealed = fmt.Errorf("%w: block is not sealed yet", ErrBlockNotQueryable)
+
+func (fa *FlowAdapter) GetLatestSealedBlockHeader() (*flow.BlockHeader, error) {
+	return fa.Client.GetLatestBlockHeader(fa.Context, true)
+}
+
+// Returns an error wrapping ErrBlockNotQueryable unless the block is
+// sealed and served by the access node.
+func (fa *FlowAdapter) ValidateQueryableBlockHeight(height uint64) error {
+	latest, err := fa.GetLatestSealedBlockHeader()
+	if err != nil {
+		return err
+	}
+	if height > latest.Height {
+		return fmt.Errorf("%w: block %d is after the latest sealed block %d", ErrBlockNotSealed, height, latest.Height)
+	}
+
+	_, err = fa.Client.GetBlockHeaderByHeight(fa.Context, height)
+	if isBlockNotFound(err) {
+		return fmt.Errorf("%w: block %d is not served by the access node", ErrBlockNotQueryable, height)
+	}
+	return err
+}
+
+// Returns the lowest height the access node serves, below the latest
+// sealed height. It only changes with a spork, which moves the network
+// to new access nodes, so it is searched for once per adapter.
+func (fa *FlowAdapter) GetLowestQueryableBlockHeight(latest uint64) (uint64, error) {
+	cache := fa.lowestHeight
+	if cache == nil {
+		return fa.searchLowestQueryableBlockHeight(latest)
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.height == nil {
+		height, err := fa.searchLowestQueryableBlockHeight(latest)
+		if err != nil {
+			return 0, err
+		}
+		cache.height = &height
+	}
+	return *cache.height, nil
+}
+
+type blockHeightCache struct {
+	sync.Mutex
+	height *uint64
+}
+
+// Blocks are served from the spork's root block on, so the first block
+// found is searched for.
+func (fa *FlowAdapter) searchLowestQueryableBlockHeight(latest uint64) (uint64, error) {
+	low, high := uint64(0), latest
+	for low < high {
+		mid := low + (high-low)/2
+		_, err := fa.Client.GetBlockHeaderByHeight(fa.Context, mid)
+		switch {
+		case err == nil:
+			high = mid
+		case isBlockNotFound(err):
+			low = mid + 1
+		default:
+			return 0, err
+		}
+	}
+	return low, nil
+}
+
+// Resolves t to the last sealed block at or before it.
+func (fa *FlowAdapter) GetBlockHeightAtTime(t time.Time) (uint64, error) {
+	latest, err := fa.GetLatestSealedBlockHeader()
+	if err != nil {
+		return 0, err
+	}
+	if t.After(latest.Timestamp) {
+		return 0, fmt.Errorf(
+			"%w: %s is after the latest sealed block %d",
+			ErrBlockNotSealed,
+			t.UTC().Format(time.RFC3339),
+			latest.Height,
+		)
+	}
+
+	lowest, err := fa.GetLowestQueryableBlockHeight(latest.Height)
+	if err != nil {
+		return 0, err
+	}
+
+	return SearchBlockHeightAtTime(lowest, latest.Height, t, fa.getBlockTime)
+}
+
+// Binary searches the blocks from low to high, whose timestamps
+// blockTime returns, for the last block at or before t.
+func SearchBlockHeightAtTime(
+	low, high uint64,
+	t time.Time,
+	blockTime func(height uint64) (time.Time, error),
+) (uint64, error) {
+	first, err := blockTime(low)
+	if err != nil {
+		return 0, err
+	}
+	if first.After(t) {
+		return 0, fmt.Errorf(
+			"%w: %s is before the first queryable block %d",
+			ErrBlockNotQueryable,
+			t.UTC().Format(time.RFC3339),
+			low,
+		)
+	}
+
+	// block low is always at or before t
+	for low < high {
+		mid := low + (high-low+1)/2
+		ts, err := blockTime(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ts.After(t) {
+			high = mid - 1
+		} else {
+			low = mid
+		}
+	}
+	return low, nil
+}
+
+func (fa *FlowAdapter) getBlockTime(height uint64) (time.Time, error) {
+	header, err := fa.Client.GetBlockHeaderByHeight(fa.Context, height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return header.Timestamp, nil
+}
+
+func isBlockNotFound(err error) bool {
+	code := status.Code(err)
+	return code == codes.NotFound || code == codes.OutOfRange
+}
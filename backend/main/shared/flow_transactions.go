@@ -0,0 +1,175 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	storeResultsTxPath   = "./cadence/V3/transactions/StoreResults.cdc"
+	defaultTxGasLimit    = 9999
+	defaultSealTimeout   = 2 * time.Minute
+	votingCommunityAlias = "VotingCommunity"
+)
+
+// The VotingCommunity transactions import the contract by its source
+// path, which is replaced with its address on the current network.
+var votingCommunityImport = regexp.MustCompile(`(import\s+VotingCommunity\s+from\s+)"[^"]*"`)
+
+// The account that signs transactions sent by the backend. It acts as
+// proposer, payer and sole authorizer.
+type TxSigner struct {
+	Address  flow.Address
+	KeyIndex int
+	Signer   crypto.Signer
+
+	// transactions from one key are sent one at a time
+	// so sequence numbers are not reused
+	mu sync.Mutex
+}
+
+// Loads the signing account for result transactions from the
+// FLOW_TX_SIGNER_ADDR, FLOW_TX_SIGNER_KEY and FLOW_TX_SIGNER_KEY_INDEX
+// env vars. Result submission stays disabled if they are not set.
+func (fa *FlowAdapter) InitTxSigner() error {
+	addr := os.Getenv("FLOW_TX_SIGNER_ADDR")
+	key := os.Getenv("FLOW_TX_SIGNER_KEY")
+	if addr == "" || key == "" {
+		log.Info().Msg("FLOW_TX_SIGNER_ADDR/FLOW_TX_SIGNER_KEY not set, on-chain results disabled.")
+		return nil
+	}
+
+	keyIndex := 0
+	if env := os.Getenv("FLOW_TX_SIGNER_KEY_INDEX"); env != "" {
+		i, err := strconv.Atoi(env)
+		if err != nil {
+			return err
+		}
+		keyIndex = i
+	}
+
+	privateKey, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, strings.TrimPrefix(key, "0x"))
+	if err != nil {
+		return err
+	}
+
+	signer, err := crypto.NewInMemorySigner(privateKey, crypto.SHA3_256)
+	if err != nil {
+		return err
+	}
+
+	fa.TxSigner = &TxSigner{
+		Address:  flow.HexToAddress(addr),
+		KeyIndex: keyIndex,
+		Signer:   signer,
+	}
+	return nil
+}
+
+// Returns true if the adapter has a signer and knows where the
+// VotingCommunity contract is deployed on the current network.
+func (fa *FlowAdapter) CanSubmitResults() bool {
+	return fa.TxSigner != nil && fa.votingCommunityAddr() != ""
+}
+
+func (fa *FlowAdapter) votingCommunityAddr() string {
+	return fa.Config.Contracts[votingCommunityAlias].Aliases[fa.Env]
+}
+
+// Signs and sends a StoreResults transaction to the VotingCommunity
+// contract, returning the transaction ID without waiting for it to seal.
+func (fa *FlowAdapter) SubmitResults(communityId, proposalId uint64, results []string) (flow.Identifier, error) {
+	if !fa.CanSubmitResults() {
+		return flow.EmptyID, errors.New("on-chain results are not configured")
+	}
+
+	script, err := ioutil.ReadFile(storeResultsTxPath)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error reading cadence transaction file.")
+		return flow.EmptyID, err
+	}
+	code := votingCommunityImport.ReplaceAllString(string(script), "${1}"+fa.votingCommunityAddr())
+
+	cadenceResults := make([]cadence.Value, len(results))
+	for i, r := range results {
+		cadenceResults[i] = cadence.String(r)
+	}
+
+	s := fa.TxSigner
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, err := fa.Client.GetAccountAtLatestBlock(fa.Context, s.Address)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+	if s.KeyIndex >= len(account.Keys) {
+		return flow.EmptyID, errors.New("signer key index not found on account")
+	}
+
+	block, err := fa.Client.GetLatestBlockHeader(fa.Context, true)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(code)).
+		SetGasLimit(defaultTxGasLimit).
+		SetReferenceBlockID(block.ID).
+		SetProposalKey(s.Address, s.KeyIndex, account.Keys[s.KeyIndex].SequenceNumber).
+		SetPayer(s.Address).
+		AddAuthorizer(s.Address)
+
+	for _, arg := range []cadence.Value{
+		cadence.NewUInt64(communityId),
+		cadence.NewUInt64(proposalId),
+		cadence.NewArray(cadenceResults),
+	} {
+		if err := tx.AddArgument(arg); err != nil {
+			return flow.EmptyID, err
+		}
+	}
+
+	if err := tx.SignEnvelope(s.Address, s.KeyIndex, s.Signer); err != nil {
+		return flow.EmptyID, err
+	}
+
+	if err := fa.Client.SendTransaction(fa.Context, *tx); err != nil {
+		return flow.EmptyID, err
+	}
+
+	return tx.ID(), nil
+}
+
+// Waits for a transaction to seal, returning an error if the transaction
+// failed, expired or did not seal in time. The result is returned with
+// the error of a transaction that failed or expired, which will not seal.
+func (fa *FlowAdapter) WaitForTransaction(txId string) (*flow.TransactionResult, error) {
+	ctx, cancel := context.WithTimeout(fa.Context, defaultSealTimeout)
+	defer cancel()
+
+	result, _, err := WaitForSeal(ctx, fa.Client, flow.HexToID(txId))
+	if err == ErrTransactionExpired {
+		return result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return result, result.Error
+	}
+
+	return result, nil
+}
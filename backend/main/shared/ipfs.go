@@ -15,6 +15,7 @@ const (
 	baseUrl = "https://api.pinata.cloud"
 )
 
+// Pins content to IPFS through Pinata.
 type IpfsClient struct {
 	BaseURL    string
 	apiKey     string
@@ -100,7 +101,7 @@ func (c *IpfsClient) PinJson(data interface{}) (*Pin, error) {
 	return &res, nil
 }
 
-func (c *IpfsClient) PinFile(file multipart.File, fileName string) (*Pin, error) {
+func (c *IpfsClient) PinFile(file io.Reader, fileName string) (*Pin, error) {
 	url := c.BaseURL + "/pinning/pinFileToIPFS"
 
 	body := &bytes.Buffer{}
@@ -0,0 +1,90 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Kubo's API defaults to port 5001, which CAST's API listens on, so the
+// node is expected on 5002.
+const defaultKuboApiUrl = "http://127.0.0.1:5002"
+
+// Pins content to a self-hosted IPFS node through the Kubo HTTP API.
+// Content is added as CIDv1 with raw leaves, so content that fits in a
+// single block gets the same CID as the local store.
+type KuboClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+type kuboAddResponse struct {
+	Name string `json:"Name"`
+	Hash string `json:"Hash"`
+	Size string `json:"Size"`
+}
+
+func NewKuboClient(baseUrl string) *KuboClient {
+	if baseUrl == "" {
+		baseUrl = defaultKuboApiUrl
+	}
+	return &KuboClient{
+		BaseURL: baseUrl,
+		HTTPClient: &http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+func (c *KuboClient) PinJson(data interface{}) (*Pin, error) {
+	json_data, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.PinFile(bytes.NewReader(json_data), "data.json")
+}
+
+func (c *KuboClient) PinFile(file io.Reader, fileName string) (*Pin, error) {
+	url := c.BaseURL + "/api/v0/add?cid-version=1&raw-leaves=true&pin=true"
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, err
+	}
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", url, body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return nil, fmt.Errorf("kubo add failed, status code: %d: %s", res.StatusCode, msg)
+	}
+
+	var added kuboAddResponse
+	if err := json.NewDecoder(res.Body).Decode(&added); err != nil {
+		return nil, err
+	}
+	size, _ := strconv.Atoi(added.Size)
+
+	return &Pin{
+		IpfsHash:  added.Hash,
+		PinSize:   size,
+		Timestamp: time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Shared address space for carrier-grade NAT, RFC 6598.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Returns whether the IP is publicly routable, and not a loopback,
+// private, link-local or otherwise reserved address.
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// Returns an error if the host of a URL is an IP that is not public or
+// names the local machine. Other hostnames are checked when they are
+// dialed, with PublicDialControl.
+func ValidatePublicHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("host is not public: %s", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return fmt.Errorf("host is not public: %s", host)
+	}
+	return nil
+}
+
+// A net.Dialer Control that refuses connections to IPs that are not
+// public. The check runs on the resolved address, so hostnames that
+// resolve to internal addresses are refused too.
+func PublicDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address: %s", address)
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/rs/zerolog/log"
+)
+
+// Takes snapshots in process, in place of the snapshot service, by
+// executing the balance scripts at the snapshot's block height through
+// the FlowAdapter. Balances read are cached in the balances table by
+// address, block height and token.
+type SnapshotEngine struct {
+	Fa *FlowAdapter
+	DB *Database
+}
+
+// The core contracts get_total_balance.cdc imports, by Flow network.
+// The emulator deploys them to its service account.
+var flowCoreContractAddrs = map[string]map[string]string{
+	"FlowStorageFees": {
+		"emulator": "0xf8d6e0586b0a20c7",
+		"testnet":  "0x8c5303eaa26202d6",
+		"mainnet":  "0xe467b9dd11fa00df",
+	},
+	"FlowIDTableStaking": {
+		"emulator": "0xf8d6e0586b0a20c7",
+		"testnet":  "0x9eca2b38b18b5dfe",
+		"mainnet":  "0x8624b52f9ddcd04a",
+	},
+	"LockedTokens": {
+		"emulator": "0xf8d6e0586b0a20c7",
+		"testnet":  "0x95e019a17d0e23d7",
+		"mainnet":  "0x8d0e87b65159ae63",
+	},
+	"FlowStakingCollection": {
+		"emulator": "0xf8d6e0586b0a20c7",
+		"testnet":  "0x95e019a17d0e23d7",
+		"mainnet":  "0x8d0e87b65159ae63",
+	},
+}
+
+var cadenceImport = regexp.MustCompile(`import (\w+) from 0x[0-9a-fA-F]+`)
+
+const scriptSuccess = "SUCCESS"
+
+func NewSnapshotEngine(fa *FlowAdapter, db *Database) *SnapshotEngine {
+	return &SnapshotEngine{Fa: fa, DB: db}
+}
+
+// Identifies a token's balances, as its Flow type identifier prefix,
+// e.g. A.0ae53cb6e3f42a79.FlowToken, or for EVM tokens as the chain id
+// and lowercase contract address, e.g. evm.1.0x5fbdb2315678afecb367f032d93f642f64180aa3.
+func FungibleTokenId(c *Contract) string {
+	if c.IsEVM() && c.Chain_id != nil && c.Addr != nil {
+		return fmt.Sprintf("evm.%s.%s", *c.Chain_id, strings.ToLower(*c.Addr))
+	}
+	if c.Addr == nil {
+		return *c.Name
+	}
+	return fmt.Sprintf("A.%s.%s", strings.TrimPrefix(*c.Addr, "0x"), *c.Name)
+}
+
+// Balances are read at the snapshot's block height when they are
+// requested, so a snapshot is the latest sealed block and is ready
+// right away.
+func (e *SnapshotEngine) TakeSnapshot(contract Contract) (*SnapshotResponse, error) {
+	height, err := e.Fa.GetCurrentBlockHeight()
+	if err != nil {
+		log.Error().Err(err).Msg("SnapshotEngine TakeSnapshot error")
+		return nil, err
+	}
+
+	return &SnapshotResponse{
+		Data: SnapshotData{
+			Status:      "success",
+			BlockHeight: uint64(height),
+		},
+	}, nil
+}
+
+func (e *SnapshotEngine) GetSnapshotStatusAtBlockHeight(
+	contract Contract,
+	blockHeight uint64,
+) (*SnapshotResponse, error) {
+	return &SnapshotResponse{
+		Data: SnapshotData{
+			Status:      "success",
+			BlockHeight: blockHeight,
+		},
+	}, nil
+}
+
+func (e *SnapshotEngine) GetAddressBalanceAtBlockHeight(
+	address string,
+	blockheight uint64,
+	balanceResponse *FTBalanceResponse,
+	contract *Contract,
+) error {
+	if contract == nil || contract.Name == nil {
+		return errors.New("snapshots require a token contract name")
+	}
+	tokenId := FungibleTokenId(contract)
+
+	// balances at the latest block change, so only those at a
+	// snapshot's block height are cached
+	if blockheight > 0 {
+		cached, err := e.getCachedBalance(address, blockheight, tokenId, balanceResponse)
+		if err != nil {
+			log.Error().Err(err).Msg("SnapshotEngine error reading cached balance")
+			return err
+		}
+		if cached {
+			return nil
+		}
+	}
+
+	var err error
+	if *contract.Name == "FlowToken" {
+		err = e.readFlowBalance(address, blockheight, balanceResponse)
+	} else {
+		err = e.readTokenBalance(address, blockheight, balanceResponse, contract)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("SnapshotEngine error reading %s balance of %s.", tokenId, address)
+		return err
+	}
+
+	balanceResponse.Addr = address
+	balanceResponse.BlockHeight = blockheight
+	balanceResponse.FungibleTokenID = tokenId
+
+	if blockheight > 0 {
+		if err := e.cacheBalance(balanceResponse); err != nil {
+			log.Error().Err(err).Msg("SnapshotEngine error caching balance")
+		}
+	}
+
+	return nil
+}
+
+func (e *SnapshotEngine) GetLatestSnapshot(contract Contract) (*Snapshot, error) {
+	return e.GetLatestFlowSnapshot()
+}
+
+func (e *SnapshotEngine) GetLatestFlowSnapshot() (*Snapshot, error) {
+	height, err := e.Fa.GetCurrentBlockHeight()
+	if err != nil {
+		log.Error().Err(err).Msg("SnapshotEngine GetLatestFlowSnapshot error")
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Snapshot{
+		ID:           strconv.Itoa(height),
+		Block_height: uint64(height),
+		Started:      now,
+		Finished:     now,
+	}, nil
+}
+
+// The engine reads any token from its contract, so tokens need not be
+// registered.
+func (e *SnapshotEngine) AddFungibleToken(addr, name, path string) error {
+	return nil
+}
+
+// Reads the FLOW an address holds across its account, its locked
+// account and its stakes and delegations.
+func (e *SnapshotEngine) readFlowBalance(address string, blockheight uint64, b *FTBalanceResponse) error {
+	script, err := ioutil.ReadFile("./main/cadence/scripts/get_total_balance.cdc")
+	if err != nil {
+		log.Error().Err(err).Msgf("Error reading cadence script file.")
+		return err
+	}
+	script = e.replaceCoreContractImports(script)
+
+	value, err := e.executeScript(script, []cadence.Value{
+		cadence.NewArray([]cadence.Value{cadence.NewAddress(flow.HexToAddress(address))}),
+	}, blockheight)
+	if err != nil {
+		return err
+	}
+
+	accounts, ok := value.(cadence.Dictionary)
+	if !ok || len(accounts.Pairs) == 0 {
+		return errors.New("get_total_balance.cdc returned no account info")
+	}
+	info, ok := accounts.Pairs[0].Value.(cadence.Struct)
+	if !ok {
+		return errors.New("get_total_balance.cdc returned invalid account info")
+	}
+
+	fields := map[string]cadence.Value{}
+	for i, field := range info.StructType.Fields {
+		fields[field.Identifier] = info.Fields[i]
+	}
+
+	b.PrimaryAccountBalance = ufix64Value(fields["primaryAcctBalance"])
+	b.SecondaryAccountBalance = ufix64Value(fields["secondaryAcctBalance"])
+	b.StakingBalance = ufix64Value(fields["stakedBalance"])
+	b.Balance = b.PrimaryAccountBalance
+	b.SecondaryAddress = ""
+	if secondary, ok := fields["secondaryAddress"].(cadence.Optional); ok && secondary.Value != nil {
+		b.SecondaryAddress = secondary.Value.String()
+	}
+	b.Stakes = []string{}
+	if stakes, ok := fields["stakes"].(cadence.String); ok {
+		for _, stake := range strings.Split(string(stakes), ", ") {
+			if stake != "" {
+				b.Stakes = append(b.Stakes, stake)
+			}
+		}
+	}
+	b.ScriptResult = scriptSuccess
+
+	return nil
+}
+
+// Reads the balance of the contract's token vault at its public path.
+func (e *SnapshotEngine) readTokenBalance(
+	address string,
+	blockheight uint64,
+	b *FTBalanceResponse,
+	c *Contract,
+) error {
+	if c.Addr == nil || c.Public_path == nil {
+		return errors.New("flow token contracts require name, addr and publicPath")
+	}
+
+	script, err := ioutil.ReadFile("./main/cadence/scripts/get_balance.cdc")
+	if err != nil {
+		log.Error().Err(err).Msgf("Error reading cadence script file.")
+		return err
+	}
+	script = e.Fa.ReplaceContractPlaceholders(string(script[:]), c, true)
+
+	value, err := e.executeScript(script, []cadence.Value{
+		cadence.Path{Domain: "public", Identifier: *c.Public_path},
+		cadence.NewAddress(flow.HexToAddress(address)),
+	}, blockheight)
+	if err != nil {
+		return err
+	}
+
+	b.PrimaryAccountBalance = ufix64Value(value)
+	b.Balance = b.PrimaryAccountBalance
+	b.SecondaryAddress = ""
+	b.SecondaryAccountBalance = 0
+	b.StakingBalance = 0
+	b.Stakes = []string{}
+	b.ScriptResult = scriptSuccess
+
+	return nil
+}
+
+func (e *SnapshotEngine) getCachedBalance(
+	address string,
+	blockheight uint64,
+	tokenId string,
+	b *FTBalanceResponse,
+) (bool, error) {
+	rows, err := e.DB.Conn.Query(e.DB.Context,
+		`
+		SELECT COALESCE(primary_account_balance, 0), COALESCE(secondary_address, ''),
+			COALESCE(secondary_account_balance, 0), COALESCE(staking_balance, 0),
+			COALESCE(script_result, ''), COALESCE(stakes, '{}'), created_at
+		FROM balances
+		WHERE addr = $1 AND block_height = $2 AND fungible_token_id = $3
+		LIMIT 1
+		`, address, blockheight, tokenId)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+
+	err = rows.Scan(
+		&b.PrimaryAccountBalance,
+		&b.SecondaryAddress,
+		&b.SecondaryAccountBalance,
+		&b.StakingBalance,
+		&b.ScriptResult,
+		&b.Stakes,
+		&b.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	b.Balance = b.PrimaryAccountBalance
+	b.Addr = address
+	b.BlockHeight = blockheight
+	b.FungibleTokenID = tokenId
+
+	return true, nil
+}
+
+func (e *SnapshotEngine) cacheBalance(b *FTBalanceResponse) error {
+	_, err := e.DB.Conn.Exec(e.DB.Context,
+		`
+		INSERT INTO balances (id, addr, primary_account_balance, secondary_address,
+			secondary_account_balance, staking_balance, script_result, stakes, block_height,
+			fungible_token_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT DO NOTHING
+		`, uuid.New(), b.Addr, b.PrimaryAccountBalance, b.SecondaryAddress, b.SecondaryAccountBalance,
+		b.StakingBalance, b.ScriptResult, b.Stakes, b.BlockHeight, b.FungibleTokenID)
+	return err
+}
+
+// Points the script's imports of core contracts at the network the
+// FlowAdapter is connected to.
+func (e *SnapshotEngine) replaceCoreContractImports(script []byte) []byte {
+	return cadenceImport.ReplaceAllFunc(script, func(line []byte) []byte {
+		name := string(cadenceImport.FindSubmatch(line)[1])
+
+		addr := flowCoreContractAddrs[name][e.Fa.Env]
+		if addr == "" {
+			addr = e.Fa.Config.Contracts[name].Aliases[e.Fa.Env]
+		}
+		if addr == "" {
+			return line
+		}
+		return []byte(fmt.Sprintf("import %s from %s", name, addr))
+	})
+}
+
+func (e *SnapshotEngine) executeScript(script []byte, args []cadence.Value, blockHeight uint64) (cadence.Value, error) {
+	return (&FlowChain{Fa: e.Fa}).executeScript(script, args, blockHeight)
+}
+
+// UFix64 values are stored as is, in units of 10^-8 tokens.
+func ufix64Value(v cadence.Value) uint64 {
+	if o, ok := v.(cadence.Optional); ok {
+		v = o.Value
+	}
+	if u, ok := v.(cadence.UFix64); ok {
+		return uint64(u)
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Snapshot backends SNAPSHOT_BACKEND can select.
+const (
+	ServiceSnapshotter = "service"
+	FlowSnapshotter    = "flow"
+)
+
+// Takes snapshots of fungible token balances and reads an address's
+// balance at a snapshot's block height.
+type Snapshotter interface {
+	TakeSnapshot(contract Contract) (*SnapshotResponse, error)
+	GetSnapshotStatusAtBlockHeight(contract Contract, blockHeight uint64) (*SnapshotResponse, error)
+	GetAddressBalanceAtBlockHeight(
+		address string,
+		blockheight uint64,
+		balanceResponse *FTBalanceResponse,
+		contract *Contract,
+	) error
+	GetLatestSnapshot(contract Contract) (*Snapshot, error)
+	GetLatestFlowSnapshot() (*Snapshot, error)
+	AddFungibleToken(addr, name, path string) error
+}
+
+// Returns the snapshotter set by SNAPSHOT_BACKEND: the snapshot service
+// at SNAPSHOT_BASE_URL by default, or the in-process engine reading
+// balances from Flow.
+func NewSnapshotter(fa *FlowAdapter, db *Database) (Snapshotter, error) {
+	backend := os.Getenv("SNAPSHOT_BACKEND")
+	if backend == "" {
+		backend = ServiceSnapshotter
+	}
+	log.Info().Msgf("SNAPSHOT_BACKEND: %s", backend)
+
+	switch backend {
+	case ServiceSnapshotter:
+		log.Info().Msgf("SNAPSHOT_BASE_URL: %s", os.Getenv("SNAPSHOT_BASE_URL"))
+		return NewSnapshotClient(os.Getenv("SNAPSHOT_BASE_URL"), *fa), nil
+	case FlowSnapshotter:
+		return NewSnapshotEngine(fa, db), nil
+	default:
+		return nil, fmt.Errorf("unsupported snapshot backend: %s", backend)
+	}
+}
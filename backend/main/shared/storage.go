@@ -0,0 +1,42 @@
+package shared
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Storage backends proposals, votes, results and uploads can be pinned to.
+const (
+	PinataStorage = "pinata"
+	KuboStorage   = "kubo"
+	LocalStorage  = "local"
+)
+
+// Stores content by its IPFS CID.
+type Storage interface {
+	PinJson(data interface{}) (*Pin, error)
+	PinFile(file io.Reader, fileName string) (*Pin, error)
+}
+
+// Returns the storage backend set by STORAGE_BACKEND, Pinata by default.
+func NewStorage() (Storage, error) {
+	backend := os.Getenv("STORAGE_BACKEND")
+	if backend == "" {
+		backend = PinataStorage
+	}
+	log.Info().Msgf("STORAGE_BACKEND: %s", backend)
+
+	switch backend {
+	case PinataStorage:
+		return NewIpfsClient(os.Getenv("IPFS_KEY"), os.Getenv("IPFS_SECRET")), nil
+	case KuboStorage:
+		return NewKuboClient(os.Getenv("KUBO_API_URL")), nil
+	case LocalStorage:
+		return NewLocalStore(os.Getenv("LOCAL_STORAGE_DIR"))
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", backend)
+	}
+}
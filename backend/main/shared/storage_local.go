@@ -0,0 +1,87 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+const defaultLocalStorageDir = "./storage"
+
+// Stores content in a local directory, named by CIDv1 of the raw codec
+// and a sha2-256 multihash of the content. Anyone holding the content
+// can verify its CID by hashing it, without an IPFS node.
+type LocalStore struct {
+	Dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		dir = defaultLocalStorageDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{Dir: dir}, nil
+}
+
+// Returns the CIDv1 of the content.
+func ComputeCid(content []byte) (string, error) {
+	hash, err := multihash.Sum(content, multihash.SHA2_256, -1)
+	if err != nil {
+		return "", err
+	}
+	return cid.NewCidV1(cid.Raw, hash).String(), nil
+}
+
+func (ls *LocalStore) PinJson(data interface{}) (*Pin, error) {
+	json_data, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return ls.PinFile(bytes.NewReader(json_data), "data.json")
+}
+
+func (ls *LocalStore) PinFile(file io.Reader, fileName string) (*Pin, error) {
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := ComputeCid(content)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(ls.Dir, hash)
+	_, err = os.Stat(path)
+	isDuplicate := err == nil
+	if !isDuplicate {
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Pin{
+		IpfsHash:    hash,
+		PinSize:     len(content),
+		Timestamp:   time.Now().UTC(),
+		IsDuplicate: isDuplicate,
+	}, nil
+}
+
+// Returns the content stored with the CID.
+func (ls *LocalStore) Get(hash string) ([]byte, error) {
+	if _, err := cid.Decode(hash); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(filepath.Join(ls.Dir, hash))
+}
@@ -11,6 +11,10 @@ import (
 
 type Config struct {
 	Features map[string]bool `default:"useCorsMiddleware:false,validateTimestamps:true,validateAllowlist:true,validateBlocklist:true,validateSigs:true"`
+	// The app identifier accounts sign in with FCL account proofs for,
+	// and how long the sessions they sign in to last.
+	AppIdentifier string        `envconfig:"APP_IDENTIFIER" default:"CAST"`
+	SessionTTL    time.Duration `envconfig:"SESSION_TTL" default:"1h"`
 }
 
 type Database struct {
@@ -22,6 +26,7 @@ type Database struct {
 
 type StrategyStruct struct {
 	FlowAdapter *FlowAdapter
+	Chains      *Chains
 	DB          *Database
 }
 
@@ -56,6 +61,9 @@ type TimestampSignaturePayload struct {
 	Composite_signatures *[]CompositeSignature `json:"compositeSignatures"`
 	Signing_addr         string                `json:"signingAddr"`
 	Timestamp            string                `json:"timestamp"`
+	// The address of the session the request was made with, which
+	// authenticates the signer in place of the signature.
+	Session_addr string `json:"-"`
 }
 
 // used in models/proposal.go
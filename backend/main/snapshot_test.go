@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*   Snapshots   */
+/*****************/
+
+func TestSnapshotEngine(t *testing.T) {
+	clearTable("balances")
+
+	engine := shared.NewSnapshotEngine(otu.Adapter, A.DB)
+	account, _ := otu.O.State.Accounts().ByName("emulator-user1")
+	addr := fmt.Sprintf("0x%s", account.Address().String())
+
+	name := "FlowToken"
+	tokenAddr := "0x0ae53cb6e3f42a79"
+	publicPath := "flowTokenBalance"
+	flowToken := shared.Contract{Name: &name, Addr: &tokenAddr, Public_path: &publicPath}
+
+	snapshot, err := engine.TakeSnapshot(flowToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "success", snapshot.Data.Status)
+	assert.Greater(t, snapshot.Data.BlockHeight, uint64(0))
+
+	t.Run("Should read FLOW balances at the snapshot block height", func(t *testing.T) {
+		var b shared.FTBalanceResponse
+		err := engine.GetAddressBalanceAtBlockHeight(addr, snapshot.Data.BlockHeight, &b, &flowToken)
+		assert.Nil(t, err)
+		assert.Greater(t, b.PrimaryAccountBalance, uint64(0))
+		assert.Equal(t, "A.0ae53cb6e3f42a79.FlowToken", b.FungibleTokenID)
+	})
+
+	t.Run("Should cache balances by token and block height", func(t *testing.T) {
+		var first, second shared.FTBalanceResponse
+		engine.GetAddressBalanceAtBlockHeight(addr, snapshot.Data.BlockHeight, &first, &flowToken)
+		engine.GetAddressBalanceAtBlockHeight(addr, snapshot.Data.BlockHeight, &second, &flowToken)
+		assert.Equal(t, first.PrimaryAccountBalance, second.PrimaryAccountBalance)
+
+		var count int
+		A.DB.Conn.QueryRow(A.DB.Context,
+			`SELECT COUNT(*) FROM balances WHERE addr = $1 AND fungible_token_id = $2`,
+			addr, first.FungibleTokenID).Scan(&count)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Should require a public path for other tokens", func(t *testing.T) {
+		other := "ExampleToken"
+		var b shared.FTBalanceResponse
+		err := engine.GetAddressBalanceAtBlockHeight(
+			addr,
+			snapshot.Data.BlockHeight,
+			&b,
+			&shared.Contract{Name: &other, Addr: &tokenAddr},
+		)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSearchBlockHeightAtTime(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	// blocks from 100 to 200, one a second
+	blockTime := func(height uint64) (time.Time, error) {
+		return start.Add(time.Duration(height-100) * time.Second), nil
+	}
+
+	t.Run("Should resolve a time to the last block at or before it", func(t *testing.T) {
+		height, err := shared.SearchBlockHeightAtTime(100, 200, start.Add(42*time.Second), blockTime)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(142), height)
+
+		height, err = shared.SearchBlockHeightAtTime(100, 200, start.Add(42500*time.Millisecond), blockTime)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(142), height)
+	})
+
+	t.Run("Should resolve the first and last blocks", func(t *testing.T) {
+		height, err := shared.SearchBlockHeightAtTime(100, 200, start, blockTime)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(100), height)
+
+		height, err = shared.SearchBlockHeightAtTime(100, 200, start.Add(time.Hour), blockTime)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(200), height)
+	})
+
+	t.Run("Should reject a time before the first block", func(t *testing.T) {
+		_, err := shared.SearchBlockHeightAtTime(100, 200, start.Add(-time.Second), blockTime)
+		assert.True(t, errors.Is(err, shared.ErrBlockNotQueryable))
+	})
+}
+
+func TestSnapshotBlocks(t *testing.T) {
+	latest, err := otu.Adapter.GetLatestSealedBlockHeader()
+	assert.Nil(t, err)
+
+	t.Run("Should resolve a block's time to the block", func(t *testing.T) {
+		height, err := otu.Adapter.GetBlockHeightAtTime(latest.Timestamp)
+		assert.Nil(t, err)
+		assert.Equal(t, latest.Height, height)
+	})
+
+	t.Run("Should reject times after the latest sealed block", func(t *testing.T) {
+		_, err := otu.Adapter.GetBlockHeightAtTime(latest.Timestamp.Add(time.Hour))
+		assert.True(t, errors.Is(err, shared.ErrBlockNotQueryable))
+	})
+
+	t.Run("Should validate block heights against the sealed range", func(t *testing.T) {
+		assert.Nil(t, otu.Adapter.ValidateQueryableBlockHeight(latest.Height))
+
+		err := otu.Adapter.ValidateQueryableBlockHeight(latest.Height + 1000)
+		assert.True(t, errors.Is(err, shared.ErrBlockNotQueryable))
+	})
+}
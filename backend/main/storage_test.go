@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*    Storage    */
+/*****************/
+
+func TestLocalStore(t *testing.T) {
+	store, err := shared.NewLocalStore(t.TempDir())
+	assert.Nil(t, err)
+
+	t.Run("Should pin files with their CIDv1", func(t *testing.T) {
+		pin, err := store.PinFile(strings.NewReader("hello world"), "hello.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e", pin.IpfsHash)
+		assert.False(t, pin.IsDuplicate)
+
+		content, err := store.Get(pin.IpfsHash)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello world", string(content))
+
+		pin, err = store.PinFile(strings.NewReader("hello world"), "hello.txt")
+		assert.Nil(t, err)
+		assert.True(t, pin.IsDuplicate)
+	})
+
+	t.Run("Should pin JSON by the CID of its encoding", func(t *testing.T) {
+		pin, err := store.PinJson(map[string]string{"name": "proposal"})
+		assert.Nil(t, err)
+
+		cid, _ := shared.ComputeCid([]byte(`{"name":"proposal"}`))
+		assert.Equal(t, cid, pin.IpfsHash)
+	})
+}
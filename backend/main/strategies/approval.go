@@ -0,0 +1,30 @@
+package strategies
+
+import (
+	"github.com/DapperCollectives/CAST/backend/main/models"
+)
+
+// TallyApproval counts the voter's full strategy weight towards
+// every choice they approved.
+func TallyApproval(
+	t Tallier,
+	votes []*models.VoteWithBalance,
+	p *models.Proposal,
+) (models.ProposalResults, error) {
+
+	var ballots []*models.VoteWithBalance
+	for _, vote := range votes {
+		if vote.Choices == nil {
+			ballots = append(ballots, vote)
+			continue
+		}
+
+		for _, choice := range *vote.Choices {
+			ballot := *vote
+			ballot.Choice = choice
+			ballots = append(ballots, &ballot)
+		}
+	}
+
+	return t.TallyVotes(ballots, models.NewProposalResults(p.ID, p.Choices), p)
+}
@@ -9,10 +9,14 @@ import (
 
 type BalanceOfNfts struct {
 	s.StrategyStruct
-	SC s.SnapshotClient
+	SC s.Snapshotter
 	DB *s.Database
 }
 
+func init() {
+	Register(&BalanceOfNfts{})
+}
+
 func (b *BalanceOfNfts) FetchBalance(
 	balance *models.Balance,
 	p *models.Proposal,
@@ -47,12 +51,18 @@ func (b *BalanceOfNfts) queryNFTs(
 	strategy models.Strategy,
 	balance *models.Balance,
 ) error {
-	scriptPath := "./main/cadence/scripts/get_nfts_ids.cdc"
-	nftIds, err := b.FlowAdapter.GetNFTIds(
-		balance.Addr,
-		&strategy.Contract,
-		scriptPath,
-	)
+	var nftIds []interface{}
+	var err error
+	if strategy.Contract.IsEVM() {
+		nftIds, err = fetchNFTIdsFromChain(b.Chains, &strategy, balance)
+	} else {
+		scriptPath := "./main/cadence/scripts/get_nfts_ids.cdc"
+		nftIds, err = b.FlowAdapter.GetNFTIds(
+			balance.Addr,
+			&strategy.Contract,
+			scriptPath,
+		)
+	}
 	if err != nil {
 		return err
 	}
@@ -127,16 +137,25 @@ func (s *BalanceOfNfts) GetVotes(
 	return votes, nil
 }
 
-func (s *BalanceOfNfts) RequiresSnapshot() bool {
-	return false
+func (s *BalanceOfNfts) Describe() models.StrategyDescriptor {
+	return models.StrategyDescriptor{
+		Key:             "balance-of-nfts",
+		Name:            "NFT-Weighted",
+		Description:     "A weight of 1 is added for each NFT at a user’s wallet address that matches the contract of the proposal.",
+		Contract_fields: []string{"name", "addr", "publicPath"},
+		Tracks_nfts:     true,
+		Chains:          []string{shared.FlowChainKey, shared.EVMChainKey},
+	}
 }
 
 func (s *BalanceOfNfts) InitStrategy(
 	f *shared.FlowAdapter,
 	db *shared.Database,
-	sc *s.SnapshotClient,
+	sc s.Snapshotter,
+	chains *s.Chains,
 ) {
 	s.FlowAdapter = f
+	s.Chains = chains
 	s.DB = db
-	s.SC = *sc
+	s.SC = sc
 }
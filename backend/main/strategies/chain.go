@@ -0,0 +1,51 @@
+package strategies
+
+import (
+	"errors"
+	"math"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+	"github.com/rs/zerolog/log"
+)
+
+// Fetches the voter's balance of the token of a strategy whose contract
+// is not read through the snapshot service, at the proposal's block.
+// Balances are stored in the 10^-8 units the snapshot service uses.
+func fetchBalanceFromChain(chains *s.Chains, strategy *models.Strategy, b *models.Balance) error {
+	chain, err := chains.ForContract(&strategy.Contract)
+	if err != nil {
+		return err
+	}
+
+	balance, err := chain.GetBalanceAtBlock(b.Addr, &strategy.Contract, b.BlockHeight)
+	if err != nil {
+		log.Error().Err(err).Msg("Error fetching balance from chain.")
+		return err
+	}
+
+	units := math.Round(balance * math.Pow(10, 8))
+	if units >= math.MaxUint64 {
+		return errors.New("balance is too large")
+	}
+	b.PrimaryAccountBalance = uint64(units)
+	b.SecondaryAccountBalance = 0
+	b.StakingBalance = 0
+	b.FungibleTokenID = s.FungibleTokenId(&strategy.Contract)
+
+	return nil
+}
+
+func fetchNFTIdsFromChain(chains *s.Chains, strategy *models.Strategy, b *models.Balance) ([]interface{}, error) {
+	chain, err := chains.ForContract(&strategy.Contract)
+	if err != nil {
+		return nil, err
+	}
+
+	nftIds, err := chain.GetNFTIdsAtBlock(b.Addr, &strategy.Contract, b.BlockHeight)
+	if err != nil {
+		log.Error().Err(err).Msg("Error fetching NFTs from chain.")
+		return nil, err
+	}
+	return nftIds, nil
+}
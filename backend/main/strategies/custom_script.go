@@ -2,6 +2,7 @@ package strategies
 
 import (
 	"fmt"
+	"io/ioutil"
 
 	"github.com/DapperCollectives/CAST/backend/main/models"
 	"github.com/DapperCollectives/CAST/backend/main/shared"
@@ -11,10 +12,14 @@ import (
 
 type CustomScript struct {
 	s.StrategyStruct
-	SC s.SnapshotClient
+	SC s.Snapshotter
 	DB *s.Database
 }
 
+func init() {
+	Register(&CustomScript{})
+}
+
 func (cs *CustomScript) FetchBalance(
 	balance *models.Balance,
 	p *models.Proposal,
@@ -38,10 +43,17 @@ func (cs *CustomScript) FetchBalance(
 	}
 
 	if strategy.Contract.Script == nil {
-		log.Error().Msg("No custom script name field was found for contract.")
+		err := fmt.Errorf("no custom script name field was found for contract")
+		log.Error().Err(err).Msg("Unable to find custom script for contract.")
+		return nil, err
+	}
+
+	src, err := cs.getScriptSrc(c.ID, *strategy.Contract.Script)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := cs.queryNFTs(*vb, strategy, balance); err != nil {
+	if err := cs.queryNFTs(*vb, strategy, balance, src); err != nil {
 		return nil, err
 	}
 
@@ -52,13 +64,12 @@ func (cs *CustomScript) queryNFTs(
 	vb models.VoteWithBalance,
 	strategy models.Strategy,
 	balance *models.Balance,
+	src string,
 ) error {
-	scriptName := cs.FlowAdapter.CustomScriptsMap[*strategy.Contract.Script].Src
-	scriptPath := fmt.Sprintf("./main/cadence/scripts/custom/%s", scriptName) 
-	nftIds, err := cs.FlowAdapter.GetNFTIds(
+	nftIds, err := cs.FlowAdapter.GetCustomScriptNFTIds(
 		balance.Addr,
 		&strategy.Contract,
-		scriptPath,
+		src,
 	)
 	if err != nil {
 		return err
@@ -86,6 +97,27 @@ func (cs *CustomScript) queryNFTs(
 	return err
 }
 
+// Returns the source of the script with the given key, looking in the
+// scripts bundled with the server before the community's own scripts.
+func (cs *CustomScript) getScriptSrc(communityId int, key string) (string, error) {
+	if script, ok := cs.FlowAdapter.CustomScriptsMap[key]; ok {
+		scriptPath := fmt.Sprintf("./main/cadence/scripts/custom/%s", script.Src)
+		src, err := ioutil.ReadFile(scriptPath)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error reading cadence script file.")
+			return "", err
+		}
+		return string(src), nil
+	}
+
+	script := models.CommunityScript{Community_id: communityId, Key: key}
+	if err := script.GetCommunityScriptByKey(cs.DB); err != nil {
+		log.Error().Err(err).Msgf("Unable to find custom script %s.", key)
+		return "", fmt.Errorf("custom script not found: %s", key)
+	}
+	return script.Src, nil
+}
+
 func (cs *CustomScript) TallyVotes(
 	votes []*models.VoteWithBalance,
 	r *models.ProposalResults,
@@ -137,16 +169,23 @@ func (cs *CustomScript) GetVotes(
 	return votes, nil
 }
 
-func (cs *CustomScript) RequiresSnapshot() bool {
-	return false
+func (cs *CustomScript) Describe() models.StrategyDescriptor {
+	return models.StrategyDescriptor{
+		Key:             "custom-script",
+		Name:            "Custom Script",
+		Description:     "Vote weight is calculated via a custom script.",
+		Contract_fields: []string{"name", "addr", "publicPath", "script"},
+	}
 }
 
 func (cs *CustomScript) InitStrategy(
 	f *shared.FlowAdapter,
 	db *shared.Database,
-	sc *s.SnapshotClient,
+	sc s.Snapshotter,
+	chains *s.Chains,
 ) {
 	cs.FlowAdapter = f
+	cs.Chains = chains
 	cs.DB = db
-	cs.SC = *sc
+	cs.SC = sc
 }
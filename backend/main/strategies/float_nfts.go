@@ -11,10 +11,14 @@ import (
 
 type FloatNFTs struct {
 	s.StrategyStruct
-	SC s.SnapshotClient
+	SC s.Snapshotter
 	DB *s.Database
 }
 
+func init() {
+	Register(&FloatNFTs{})
+}
+
 func (s *FloatNFTs) FetchBalance(
 	balance *models.Balance,
 	p *models.Proposal,
@@ -136,16 +140,24 @@ func (s *FloatNFTs) GetVotes(
 	return votes, nil
 }
 
-func (s *FloatNFTs) RequiresSnapshot() bool {
-	return false
+func (s *FloatNFTs) Describe() models.StrategyDescriptor {
+	return models.StrategyDescriptor{
+		Key:             "float-nfts",
+		Name:            "Float NFTs",
+		Description:     "Vote weight is calculated via proof attendance using the Float Event ID",
+		Contract_fields: []string{"floatEventId"},
+		Tracks_nfts:     true,
+	}
 }
 
 func (s *FloatNFTs) InitStrategy(
 	f *shared.FlowAdapter,
 	db *shared.Database,
-	sc *s.SnapshotClient,
+	sc s.Snapshotter,
+	chains *s.Chains,
 ) {
 	s.FlowAdapter = f
+	s.Chains = chains
 	s.DB = db
-	s.SC = *sc
+	s.SC = sc
 }
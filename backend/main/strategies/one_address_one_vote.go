@@ -12,10 +12,14 @@ import (
 
 type OneAddressOneVote struct {
 	s.StrategyStruct
-	SC s.SnapshotClient
+	SC s.Snapshotter
 	DB *s.Database
 }
 
+func init() {
+	Register(&OneAddressOneVote{})
+}
+
 func (s *OneAddressOneVote) FetchBalance(
 	b *models.Balance,
 	p *models.Proposal,
@@ -43,7 +47,7 @@ func (s *OneAddressOneVote) TallyVotes(
 ) (models.ProposalResults, error) {
 
 	for _, vote := range votes {
-		r.Results[vote.Choice]++
+		r.Results[vote.Choice] += 1 + len(vote.Delegations)
 	}
 
 	return *r, nil
@@ -59,7 +63,7 @@ func (s *OneAddressOneVote) GetVoteWeightForBalance(
 	if vote.Addr == "" {
 		return 0.00, ERROR
 	}
-	weight = 1.00
+	weight = 1.00 + float64(len(vote.Delegations))
 
 	return weight, nil
 }
@@ -80,16 +84,25 @@ func (s *OneAddressOneVote) GetVotes(
 	return votes, nil
 }
 
-func (s *OneAddressOneVote) RequiresSnapshot() bool {
-	return false
+func (s *OneAddressOneVote) Describe() models.StrategyDescriptor {
+	return models.StrategyDescriptor{
+		Key:             "one-address-one-vote",
+		Name:            "One Address One Vote",
+		Description:     "One address is simply only allowed one vote, assets do not come into play.",
+		Contract_fields: []string{},
+		Delegable:       true,
+		Hidden:          true,
+	}
 }
 
 func (s *OneAddressOneVote) InitStrategy(
 	f *shared.FlowAdapter,
 	db *shared.Database,
-	sc *s.SnapshotClient,
+	sc s.Snapshotter,
+	chains *s.Chains,
 ) {
 	s.FlowAdapter = f
+	s.Chains = chains
 	s.DB = db
-	s.SC = *sc
+	s.SC = sc
 }
@@ -0,0 +1,109 @@
+package strategies
+
+import (
+	"math"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	shared "github.com/DapperCollectives/CAST/backend/main/shared"
+)
+
+// QuadraticTokenWeighted weights each vote as the square root of the
+// voter's token balance at the proposal snapshot, dampening the influence
+// of large holders. Balances are fetched as TokenWeightedDefault fetches
+// them.
+type QuadraticTokenWeighted struct {
+	TokenWeightedDefault
+}
+
+func init() {
+	Register(&QuadraticTokenWeighted{})
+}
+
+func (s *QuadraticTokenWeighted) TallyVotes(
+	votes []*models.VoteWithBalance,
+	r *models.ProposalResults,
+	p *models.Proposal,
+) (models.ProposalResults, error) {
+
+	for _, vote := range votes {
+		weight, err := s.GetVoteWeightForBalance(vote, p)
+		if err != nil {
+			return models.ProposalResults{}, err
+		}
+
+		for choice, amount := range vote.Apportion(weight) {
+			r.Results[choice] += int(amount * math.Pow(10, 8))
+			r.Results_float[choice] += amount
+		}
+	}
+
+	return *r, nil
+}
+
+func (s *QuadraticTokenWeighted) GetVoteWeightForBalance(
+	vote *models.VoteWithBalance,
+	proposal *models.Proposal,
+) (float64, error) {
+	// each delegated balance is weighted on its own, so splitting tokens
+	// across addresses and delegating them gains nothing
+	var weight float64
+	if vote.PrimaryAccountBalance != nil {
+		weight = quadraticWeight(*vote.PrimaryAccountBalance, proposal.Max_weight)
+	}
+	for _, d := range vote.Delegations {
+		if d.PrimaryAccountBalance != nil {
+			weight += quadraticWeight(*d.PrimaryAccountBalance, proposal.Max_weight)
+		}
+	}
+
+	return weight, nil
+}
+
+func (s *QuadraticTokenWeighted) GetVotes(
+	votes []*models.VoteWithBalance,
+	proposal *models.Proposal,
+) ([]*models.VoteWithBalance, error) {
+
+	for _, vote := range votes {
+		weight, err := s.GetVoteWeightForBalance(vote, proposal)
+		if err != nil {
+			return nil, err
+		}
+		vote.Weight = &weight
+
+		if vote.PrimaryAccountBalance != nil {
+			rawBalance := float64(*vote.PrimaryAccountBalance) * math.Pow(10, -8)
+			vote.RawBalance = &rawBalance
+		}
+
+		if proposal.IsWeighted() {
+			choiceWeights := vote.Apportion(weight)
+			vote.ChoiceWeights = &choiceWeights
+		}
+	}
+
+	return votes, nil
+}
+
+func (s *QuadraticTokenWeighted) Describe() models.StrategyDescriptor {
+	return models.StrategyDescriptor{
+		Key:               "quadratic-token-weighted",
+		Name:              "Quadratic Token Weighted",
+		Description:       "Vote weight is the square root of the voter's token balance at the proposal snapshot.",
+		Contract_fields:   []string{"name", "addr"},
+		Requires_snapshot: true,
+		Delegable:         true,
+		Split_voting:      true,
+		Chains:            []string{shared.FlowChainKey, shared.EVMChainKey},
+	}
+}
+
+// The max weight is a token amount, so it caps the balance before the
+// square root is taken.
+func quadraticWeight(balance uint64, maxWeight *float64) float64 {
+	tokens := float64(balance) * math.Pow(10, -8)
+	if maxWeight != nil && tokens > *maxWeight {
+		tokens = *maxWeight
+	}
+	return math.Sqrt(tokens)
+}
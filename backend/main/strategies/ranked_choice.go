@@ -0,0 +1,159 @@
+package strategies
+
+import (
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+)
+
+type Tallier interface {
+	TallyVotes(votes []*models.VoteWithBalance, r *models.ProposalResults, p *models.Proposal) (models.ProposalResults, error)
+}
+
+// TallyRankedChoice runs an instant-runoff election over ranked ballots.
+// Each round every ballot counts towards its highest ranked choice still
+// in the running, weighted by the proposal's strategy. If no choice holds
+// a majority, the lowest choice is eliminated and the next round is run.
+func TallyRankedChoice(
+	t Tallier,
+	votes []*models.VoteWithBalance,
+	p *models.Proposal,
+) (models.ProposalResults, error) {
+
+	running := make([]s.Choice, len(p.Choices))
+	copy(running, p.Choices)
+
+	var rounds []*models.RankedChoiceRound
+	var r models.ProposalResults
+
+	for round := 1; ; round++ {
+		roundVotes, exhausted := ballotsForRound(votes, running)
+
+		var err error
+		r, err = t.TallyVotes(roundVotes, models.NewProposalResults(p.ID, running), p)
+		if err != nil {
+			return models.ProposalResults{}, err
+		}
+
+		current := &models.RankedChoiceRound{
+			Round:         round,
+			Results:       r.Results,
+			Results_float: r.Results_float,
+			Exhausted:     exhausted,
+		}
+		rounds = append(rounds, current)
+
+		if len(running) <= 1 || hasMajority(r.Results) {
+			break
+		}
+
+		eliminated := lowestChoice(running, r.Results)
+		if len(eliminated) == len(running) {
+			// every remaining choice is tied
+			break
+		}
+		current.Eliminated = eliminated
+		running = removeChoices(running, eliminated)
+	}
+
+	// report eliminated choices with a zero tally
+	final := models.NewProposalResults(p.ID, p.Choices)
+	for choice, total := range r.Results {
+		final.Results[choice] = total
+	}
+	for choice, total := range r.Results_float {
+		final.Results_float[choice] = total
+	}
+	final.Rounds = rounds
+
+	return *final, nil
+}
+
+// Returns a copy of each ballot cast for its highest ranked choice still
+// running, along with the number of ballots with no running choices left.
+func ballotsForRound(
+	votes []*models.VoteWithBalance,
+	running []s.Choice,
+) ([]*models.VoteWithBalance, int) {
+
+	isRunning := make(map[string]bool)
+	for _, c := range running {
+		isRunning[c.Choice_text] = true
+	}
+
+	var ballots []*models.VoteWithBalance
+	exhausted := 0
+
+	for _, vote := range votes {
+		preferences := []string{vote.Choice}
+		if vote.Choices != nil {
+			preferences = *vote.Choices
+		}
+
+		counted := false
+		for _, choice := range preferences {
+			if isRunning[choice] {
+				ballot := *vote
+				ballot.Choice = choice
+				ballots = append(ballots, &ballot)
+				counted = true
+				break
+			}
+		}
+
+		if !counted {
+			exhausted++
+		}
+	}
+
+	return ballots, exhausted
+}
+
+func hasMajority(results map[string]int) bool {
+	total := 0
+	top := 0
+	for _, v := range results {
+		total += v
+		if v > top {
+			top = v
+		}
+	}
+
+	return total > 0 && top*2 > total
+}
+
+// Returns every choice tied for the lowest tally.
+func lowestChoice(running []s.Choice, results map[string]int) []string {
+	var lowest []string
+	min := -1
+
+	for _, c := range running {
+		total := results[c.Choice_text]
+		switch {
+		case min == -1 || total < min:
+			min = total
+			lowest = []string{c.Choice_text}
+		case total == min:
+			lowest = append(lowest, c.Choice_text)
+		}
+	}
+
+	return lowest
+}
+
+func removeChoices(choices []s.Choice, remove []string) []s.Choice {
+	var remaining []s.Choice
+	for _, c := range choices {
+		keep := true
+		for _, r := range remove {
+			if c.Choice_text == r {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			remaining = append(remaining, c)
+		}
+	}
+
+	return remaining
+}
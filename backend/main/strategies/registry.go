@@ -0,0 +1,48 @@
+package strategies
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+)
+
+type Strategy interface {
+	TallyVotes(votes []*models.VoteWithBalance, p *models.ProposalResults, proposal *models.Proposal) (models.ProposalResults, error)
+	GetVotes(votes []*models.VoteWithBalance, proposal *models.Proposal) ([]*models.VoteWithBalance, error)
+	GetVoteWeightForBalance(vote *models.VoteWithBalance, proposal *models.Proposal) (float64, error)
+	InitStrategy(f *shared.FlowAdapter, db *shared.Database, sc shared.Snapshotter, chains *shared.Chains)
+	FetchBalance(b *models.Balance, p *models.Proposal) (*models.Balance, error)
+	Describe() models.StrategyDescriptor
+}
+
+var registry = map[string]Strategy{}
+
+// Registers a strategy under the key it describes itself with. Strategies
+// register themselves from init, so adding a strategy only requires
+// adding its file to this package.
+func Register(s Strategy) {
+	d := s.Describe()
+	if _, ok := registry[d.Key]; ok {
+		panic(fmt.Sprintf("strategy already registered: %s", d.Key))
+	}
+
+	registry[d.Key] = s
+	models.RegisterStrategyDescriptor(d)
+}
+
+// Returns the registered strategy, or nil if there is none with the key.
+func Lookup(key string) Strategy {
+	return registry[key]
+}
+
+// Returns the keys of all registered strategies, in order.
+func Keys() []string {
+	keys := make([]string, 0, len(registry))
+	for k := range registry {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -12,10 +12,14 @@ import (
 
 type StakedTokenWeightedDefault struct {
 	s.StrategyStruct
-	SC s.SnapshotClient
+	SC s.Snapshotter
 	DB *s.Database
 }
 
+func init() {
+	Register(&StakedTokenWeightedDefault{})
+}
+
 func (s *StakedTokenWeightedDefault) FetchBalance(
 	b *models.Balance,
 	p *models.Proposal,
@@ -80,6 +84,7 @@ func (s *StakedTokenWeightedDefault) FetchBalanceFromSnapshot(
 		b.SecondaryAccountBalance = 0
 		b.StakingBalance = 0
 	}
+	b.FungibleTokenID = shared.FungibleTokenId(&strategy.Contract)
 
 	return nil
 }
@@ -91,17 +96,20 @@ func (s *StakedTokenWeightedDefault) TallyVotes(
 	var zero uint64 = 0
 
 	for _, vote := range votes {
-		if *vote.StakingBalance != zero {
+		balance := vote.StakingBalanceWithDelegations()
+		if balance != zero {
 			var allowedBalance float64
 
 			if p.Max_weight != nil {
-				allowedBalance = p.EnforceMaxWeight(float64(*vote.StakingBalance))
+				allowedBalance = p.EnforceMaxWeight(float64(balance))
 			} else {
-				allowedBalance = float64(*vote.StakingBalance)
+				allowedBalance = float64(balance)
 			}
 
-			r.Results[vote.Choice] += int(allowedBalance)
-			r.Results_float[vote.Choice] += allowedBalance * math.Pow(10, -8)
+			for choice, amount := range vote.Apportion(allowedBalance) {
+				r.Results[choice] += int(amount)
+				r.Results_float[choice] += amount * math.Pow(10, -8)
+			}
 		}
 	}
 
@@ -115,11 +123,7 @@ func (s *StakedTokenWeightedDefault) GetVoteWeightForBalance(
 	var weight float64
 	var ERROR error = fmt.Errorf("no weight found, address: %s, strategy: %s", vote.Addr, *proposal.Strategy)
 
-	if vote.StakingBalance == nil {
-		return 0.00, nil
-	}
-
-	weight = float64(*vote.StakingBalance) * math.Pow(10, -8)
+	weight = float64(vote.StakingBalanceWithDelegations()) * math.Pow(10, -8)
 
 	switch {
 	case proposal.Max_weight != nil && weight > *proposal.Max_weight:
@@ -147,21 +151,37 @@ func (s *StakedTokenWeightedDefault) GetVotes(
 			return nil, err
 		}
 		vote.Weight = &weight
+
+		if proposal.IsWeighted() {
+			choiceWeights := vote.Apportion(weight)
+			vote.ChoiceWeights = &choiceWeights
+		}
 	}
 
 	return votes, nil
 }
 
-func (s *StakedTokenWeightedDefault) RequiresSnapshot() bool {
-	return true
+func (s *StakedTokenWeightedDefault) Describe() models.StrategyDescriptor {
+	return models.StrategyDescriptor{
+		Key:               "staked-token-weighted-default",
+		Name:              "Staked FLOW-Weighted",
+		Description:       "A weight of 1 is added for each FLOW token that a wallet address has staked or delegated to the Flow network.",
+		Contract_fields:   []string{"name", "addr"},
+		Requires_snapshot: true,
+		Delegable:         true,
+		Split_voting:      true,
+		Token_weighted:    true,
+	}
 }
 
 func (s *StakedTokenWeightedDefault) InitStrategy(
 	f *shared.FlowAdapter,
 	db *shared.Database,
-	sc *s.SnapshotClient,
+	sc s.Snapshotter,
+	chains *s.Chains,
 ) {
 	s.FlowAdapter = f
+	s.Chains = chains
 	s.DB = db
-	s.SC = *sc
+	s.SC = sc
 }
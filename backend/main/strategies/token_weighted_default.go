@@ -12,10 +12,14 @@ import (
 
 type TokenWeightedDefault struct {
 	s.StrategyStruct
-	SC s.SnapshotClient
+	SC s.Snapshotter
 	DB *s.Database
 }
 
+func init() {
+	Register(&TokenWeightedDefault{})
+}
+
 func (s *TokenWeightedDefault) FetchBalance(
 	b *models.Balance,
 	p *models.Proposal,
@@ -32,8 +36,13 @@ func (s *TokenWeightedDefault) FetchBalance(
 		return nil, err
 	}
 
-	if err := s.FetchBalanceFromSnapshot(&strategy, b); err != nil {
-		log.Error().Err(err).Msg("Error calling snapshot client")
+	if strategy.Contract.IsEVM() {
+		err = fetchBalanceFromChain(s.Chains, &strategy, b)
+	} else {
+		err = s.FetchBalanceFromSnapshot(&strategy, b)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Error fetching balance")
 		return nil, err
 	}
 
@@ -81,6 +90,7 @@ func (s *TokenWeightedDefault) FetchBalanceFromSnapshot(
 		b.SecondaryAccountBalance = 0
 		b.StakingBalance = 0
 	}
+	b.FungibleTokenID = shared.FungibleTokenId(&strategy.Contract)
 
 	return nil
 }
@@ -92,17 +102,23 @@ func (s *TokenWeightedDefault) TallyVotes(
 ) (models.ProposalResults, error) {
 
 	for _, vote := range votes {
-		if vote.PrimaryAccountBalance != nil {
-			var allowedBalance float64
+		// balances delegated to the voter count even if the
+		// voter's own balance is missing
+		balance := float64(vote.PrimaryBalanceWithDelegations())
+		if balance == 0 {
+			continue
+		}
 
-			if p.Max_weight != nil {
-				allowedBalance = p.EnforceMaxWeight(float64(*vote.PrimaryAccountBalance))
-			} else {
-				allowedBalance = float64(*vote.PrimaryAccountBalance)
-			}
+		var allowedBalance float64
+		if p.Max_weight != nil {
+			allowedBalance = p.EnforceMaxWeight(balance)
+		} else {
+			allowedBalance = balance
+		}
 
-			r.Results[vote.Choice] += int(allowedBalance)
-			r.Results_float[vote.Choice] += allowedBalance * math.Pow(10, -8)
+		for choice, amount := range vote.Apportion(allowedBalance) {
+			r.Results[choice] += int(amount)
+			r.Results_float[choice] += amount * math.Pow(10, -8)
 		}
 	}
 
@@ -116,11 +132,7 @@ func (s *TokenWeightedDefault) GetVoteWeightForBalance(
 	var weight float64
 	var ERROR error = fmt.Errorf("No weight found, address: %s, strategy: %s.", vote.Addr, *proposal.Strategy)
 
-	if vote.PrimaryAccountBalance == nil {
-		return 0.00, nil
-	}
-
-	weight = float64(*vote.PrimaryAccountBalance) * math.Pow(10, -8)
+	weight = float64(vote.PrimaryBalanceWithDelegations()) * math.Pow(10, -8)
 
 	switch {
 	case proposal.Max_weight != nil && weight > *proposal.Max_weight:
@@ -148,20 +160,37 @@ func (s *TokenWeightedDefault) GetVotes(
 			return nil, err
 		}
 		vote.Weight = &weight
+
+		if proposal.IsWeighted() {
+			choiceWeights := vote.Apportion(weight)
+			vote.ChoiceWeights = &choiceWeights
+		}
 	}
 	return votes, nil
 }
 
-func (s *TokenWeightedDefault) RequiresSnapshot() bool {
-	return true
+func (s *TokenWeightedDefault) Describe() models.StrategyDescriptor {
+	return models.StrategyDescriptor{
+		Key:               "token-weighted-default",
+		Name:              "Token-Weighted",
+		Description:       "A weight of 1 is added for each fungible token at a user’s wallet address that matches the contract of the proposal.",
+		Contract_fields:   []string{"name", "addr"},
+		Requires_snapshot: true,
+		Delegable:         true,
+		Split_voting:      true,
+		Token_weighted:    true,
+		Chains:            []string{shared.FlowChainKey, shared.EVMChainKey},
+	}
 }
 
 func (s *TokenWeightedDefault) InitStrategy(
 	f *shared.FlowAdapter,
 	db *shared.Database,
-	sc *s.SnapshotClient,
+	sc s.Snapshotter,
+	chains *s.Chains,
 ) {
 	s.FlowAdapter = f
+	s.Chains = chains
 	s.DB = db
-	s.SC = *sc
+	s.SC = sc
 }
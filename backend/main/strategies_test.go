@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
 	"net/http"
 	"testing"
@@ -13,22 +14,6 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-type Strategy interface {
-	TallyVotes(votes []*models.VoteWithBalance, p *models.ProposalResults, proposal *models.Proposal) (models.ProposalResults, error)
-	GetVotes(votes []*models.VoteWithBalance, proposal *models.Proposal) ([]*models.VoteWithBalance, error)
-	GetVoteWeightForBalance(vote *models.VoteWithBalance, proposal *models.Proposal) (float64, error)
-	InitStrategy(f *shared.FlowAdapter, db *shared.Database, sc *shared.SnapshotClient)
-	FetchBalance(b *models.Balance, p *models.Proposal) (*models.Balance, error)
-	RequiresSnapshot() bool
-}
-
-var strategyMap = map[string]Strategy{
-	"token-weighted-default":        &strategies.TokenWeightedDefault{},
-	"staked-token-weighted-default": &strategies.StakedTokenWeightedDefault{},
-	"one-address-one-vote":          &strategies.OneAddressOneVote{},
-	"balance-of-nfts":               &strategies.BalanceOfNfts{},
-}
-
 /* Token Weighted Default */
 func TestTokenWeightedDefaultStrategy(t *testing.T) {
 	clearTable("communities")
@@ -53,7 +38,7 @@ func TestTokenWeightedDefaultStrategy(t *testing.T) {
 		// Tally results
 		strategyName := "token-weighted-default"
 
-		s := strategyMap[strategyName]
+		s := strategies.Lookup(strategyName)
 		proposalWithChoices := models.NewProposalResults(proposalId, choices)
 		_results, err := s.TallyVotes(votes, proposalWithChoices, proposals[0])
 		if err != nil {
@@ -116,6 +101,25 @@ func TestTokenWeightedDefaultStrategy(t *testing.T) {
 			assert.Equal(t, _expectedWeight, *v.Weight)
 		}
 	})
+
+	t.Run("Balances of other tokens should not be counted", func(t *testing.T) {
+		_vote := (votes)[0]
+		otu.AddBalanceOfToken(_vote.Addr, 999999999999, "A.0000000000000001.OtherToken")
+
+		response := otu.GetVoteForProposalByAddressAPI(proposalId, _vote.Addr)
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		var vote models.VoteWithBalance
+		json.Unmarshal(response.Body.Bytes(), &vote)
+
+		_expectedWeight := float64(*_vote.PrimaryAccountBalance) * math.Pow(10, -8)
+		assert.Equal(t, _expectedWeight, *vote.Weight)
+
+		response = otu.GetVotesForProposalAPI(proposalId)
+		var body utils.PaginatedResponseWithVotes
+		json.Unmarshal(response.Body.Bytes(), &body)
+		assert.Equal(t, len(votes), body.TotalRecords)
+	})
 }
 
 /* Balance of NFT */
@@ -147,7 +151,7 @@ func TestBalanceOfNFTsStrategy(t *testing.T) {
 	t.Run("Test Tallying Results For NFT Balance Strategy", func(t *testing.T) {
 		strategyName := "balance-of-nfts"
 
-		s := strategyMap[strategyName]
+		s := strategies.Lookup(strategyName)
 		proposalWithChoices := models.NewProposalResults(proposalId, choices)
 		_results, err := s.TallyVotes(votes, proposalWithChoices, proposals[0])
 		if err != nil {
@@ -234,7 +238,12 @@ func TestStakedTokenWeightedDefaultStrategy(t *testing.T) {
 	clearTable("balances")
 
 	communityId := otu.AddCommunities(1)[0]
-	proposalIds, proposals := otu.AddProposalsForStrategy(communityId, "staked-token-weighted-default", 2)
+	staked := "staked-token-weighted-default"
+	otu.UpdateCommunityStrategies(communityId, []models.Strategy{{
+		Name:     &staked,
+		Contract: (*utils.DefaultCommunity.Strategies)[0].Contract,
+	}})
+	proposalIds, proposals := otu.AddProposalsForStrategy(communityId, staked, 2)
 	proposalIdTwo := proposalIds[1]
 	proposalId := proposalIds[0]
 	choices := proposals[0].Choices
@@ -245,7 +254,7 @@ func TestStakedTokenWeightedDefaultStrategy(t *testing.T) {
 		// Tally results
 		strategyName := "staked-token-weighted-default"
 
-		s := strategyMap[strategyName]
+		s := strategies.Lookup(strategyName)
 		proposalWithChoices := models.NewProposalResults(proposalId, choices)
 		_results, err := s.TallyVotes(votes, proposalWithChoices, proposals[0])
 		if err != nil {
@@ -384,3 +393,415 @@ func TestStakedTokenWeightedDefaultStrategy(t *testing.T) {
 // 		}
 // 	})
 // }
+
+/* Ranked Choice */
+func TestRankedChoiceTally(t *testing.T) {
+	rankedChoice := models.RankedChoice
+	proposal := &models.Proposal{
+		ID: 1,
+		Choices: []shared.Choice{
+			{Choice_text: "a"},
+			{Choice_text: "b"},
+			{Choice_text: "c"},
+		},
+		Voting_type: &rankedChoice,
+	}
+
+	ballots := [][]string{
+		{"a", "b"},
+		{"a", "b"},
+		{"b", "a"},
+		{"b", "a"},
+		{"c", "b"},
+	}
+
+	var votes []*models.VoteWithBalance
+	for i, ballot := range ballots {
+		choices := ballot
+		votes = append(votes, &models.VoteWithBalance{
+			Vote: models.Vote{
+				Proposal_id: proposal.ID,
+				Addr:        fmt.Sprintf("0x%016d", i),
+				Choice:      choices[0],
+				Choices:     &choices,
+			},
+		})
+	}
+
+	t.Run("Eliminates the lowest choice until one has a majority", func(t *testing.T) {
+		results, err := strategies.TallyRankedChoice(&strategies.OneAddressOneVote{}, votes, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.Equal(t, 2, len(results.Rounds))
+		assert.Equal(t, []string{"c"}, results.Rounds[0].Eliminated)
+		assert.Equal(t, 2, results.Results["a"])
+		assert.Equal(t, 3, results.Results["b"])
+		assert.Equal(t, 0, results.Results["c"])
+	})
+}
+
+/* Approval */
+func TestApprovalTally(t *testing.T) {
+	approval := models.Approval
+	proposal := &models.Proposal{
+		ID: 1,
+		Choices: []shared.Choice{
+			{Choice_text: "a"},
+			{Choice_text: "b"},
+			{Choice_text: "c"},
+		},
+		Voting_type: &approval,
+	}
+
+	ballots := [][]string{
+		{"a", "b"},
+		{"a"},
+		{"b", "c"},
+	}
+
+	var votes []*models.VoteWithBalance
+	for i, ballot := range ballots {
+		choices := ballot
+		votes = append(votes, &models.VoteWithBalance{
+			Vote: models.Vote{
+				Proposal_id: proposal.ID,
+				Addr:        fmt.Sprintf("0x%016d", i),
+				Choice:      choices[0],
+				Choices:     &choices,
+			},
+		})
+	}
+
+	t.Run("Counts the full weight towards every approved choice", func(t *testing.T) {
+		results, err := strategies.TallyApproval(&strategies.OneAddressOneVote{}, votes, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.Equal(t, 2, results.Results["a"])
+		assert.Equal(t, 2, results.Results["b"])
+		assert.Equal(t, 1, results.Results["c"])
+	})
+}
+
+/* Weighted */
+func TestWeightedTally(t *testing.T) {
+	weighted := models.Weighted
+	proposal := &models.Proposal{
+		ID: 1,
+		Choices: []shared.Choice{
+			{Choice_text: "a"},
+			{Choice_text: "b"},
+		},
+		Voting_type: &weighted,
+	}
+
+	balance := uint64(100 * math.Pow(10, 8))
+	allocations := []map[string]float64{
+		{"a": 75, "b": 25},
+		{"b": 100},
+	}
+
+	var votes []*models.VoteWithBalance
+	for i, allocation := range allocations {
+		a := allocation
+		vote := &models.VoteWithBalance{
+			Vote: models.Vote{
+				Proposal_id: proposal.ID,
+				Addr:        fmt.Sprintf("0x%016d", i),
+				Allocations: &a,
+			},
+			PrimaryAccountBalance: &balance,
+		}
+		vote.SetPrimaryChoice(*proposal)
+		votes = append(votes, vote)
+	}
+
+	t.Run("Splits each voter's balance across their allocations", func(t *testing.T) {
+		strategy := &strategies.TokenWeightedDefault{}
+		r := models.NewProposalResults(proposal.ID, proposal.Choices)
+		results, err := strategy.TallyVotes(votes, r, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.InDelta(t, 75.0, results.Results_float["a"], 0.0001)
+		assert.InDelta(t, 125.0, results.Results_float["b"], 0.0001)
+		assert.Equal(t, "a", votes[0].Choice)
+	})
+}
+
+/* Quadratic Token Weighted */
+func TestQuadraticTokenWeightedStrategy(t *testing.T) {
+	strategyName := "quadratic-token-weighted"
+	proposal := &models.Proposal{
+		ID:       1,
+		Strategy: &strategyName,
+		Choices: []shared.Choice{
+			{Choice_text: "a"},
+			{Choice_text: "b"},
+		},
+	}
+
+	whale := uint64(10000 * math.Pow(10, 8))
+	holder := uint64(100 * math.Pow(10, 8))
+	balances := []*uint64{&whale, &holder, &holder}
+	choices := []string{"a", "b", "b"}
+
+	var votes []*models.VoteWithBalance
+	for i, balance := range balances {
+		votes = append(votes, &models.VoteWithBalance{
+			Vote: models.Vote{
+				Proposal_id: proposal.ID,
+				Addr:        fmt.Sprintf("0x%016d", i),
+				Choice:      choices[i],
+			},
+			PrimaryAccountBalance: balance,
+		})
+	}
+
+	s := &strategies.QuadraticTokenWeighted{}
+
+	t.Run("Weights each vote as the square root of its balance", func(t *testing.T) {
+		r := models.NewProposalResults(proposal.ID, proposal.Choices)
+		results, err := s.TallyVotes(votes, r, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.InDelta(t, 100.0, results.Results_float["a"], 0.0001)
+		assert.InDelta(t, 20.0, results.Results_float["b"], 0.0001)
+	})
+
+	t.Run("Exposes the raw balance and effective weight", func(t *testing.T) {
+		votesWithWeights, err := s.GetVotes(votes, proposal)
+		if err != nil {
+			t.Errorf("Error getting votes: %v", err)
+		}
+
+		assert.InDelta(t, 10000.0, *votesWithWeights[0].RawBalance, 0.0001)
+		assert.InDelta(t, 100.0, *votesWithWeights[0].Weight, 0.0001)
+	})
+
+	t.Run("Caps the balance at the max weight before taking its square root", func(t *testing.T) {
+		maxWeight := 400.0
+		capped := *proposal
+		capped.Max_weight = &maxWeight
+
+		weight, err := s.GetVoteWeightForBalance(votes[0], &capped)
+		assert.Nil(t, err)
+		assert.InDelta(t, 20.0, weight, 0.0001)
+
+		weight, err = s.GetVoteWeightForBalance(votes[1], &capped)
+		assert.Nil(t, err)
+		assert.InDelta(t, 10.0, weight, 0.0001)
+	})
+}
+
+/* Delegation */
+func TestDelegatedTally(t *testing.T) {
+	strategyName := "token-weighted-default"
+	proposal := &models.Proposal{
+		ID:       1,
+		Strategy: &strategyName,
+		Choices: []shared.Choice{
+			{Choice_text: "a"},
+			{Choice_text: "b"},
+		},
+	}
+
+	balance := uint64(100 * math.Pow(10, 8))
+	delegated := uint64(50 * math.Pow(10, 8))
+
+	votes := []*models.VoteWithBalance{
+		{
+			Vote: models.Vote{
+				Proposal_id: proposal.ID,
+				Addr:        "0x0000000000000001",
+				Choice:      "a",
+			},
+			PrimaryAccountBalance: &balance,
+			StakingBalance:        &balance,
+			Delegations: []*models.DelegatedBalance{
+				{Addr: "0x0000000000000003", PrimaryAccountBalance: &delegated, StakingBalance: &delegated},
+				{Addr: "0x0000000000000004", PrimaryAccountBalance: &delegated, StakingBalance: &delegated},
+			},
+		},
+		{
+			Vote: models.Vote{
+				Proposal_id: proposal.ID,
+				Addr:        "0x0000000000000002",
+				Choice:      "b",
+			},
+			PrimaryAccountBalance: &balance,
+			StakingBalance:        &balance,
+		},
+	}
+
+	t.Run("Adds delegated balances to the delegate's vote", func(t *testing.T) {
+		s := &strategies.TokenWeightedDefault{}
+		r := models.NewProposalResults(proposal.ID, proposal.Choices)
+		results, err := s.TallyVotes(votes, r, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.InDelta(t, 200.0, results.Results_float["a"], 0.0001)
+		assert.InDelta(t, 100.0, results.Results_float["b"], 0.0001)
+
+		weight, _ := s.GetVoteWeightForBalance(votes[0], proposal)
+		assert.InDelta(t, 200.0, weight, 0.0001)
+	})
+
+	t.Run("Adds delegated staking balances", func(t *testing.T) {
+		s := &strategies.StakedTokenWeightedDefault{}
+		r := models.NewProposalResults(proposal.ID, proposal.Choices)
+		results, err := s.TallyVotes(votes, r, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.InDelta(t, 200.0, results.Results_float["a"], 0.0001)
+	})
+
+	t.Run("Counts each delegator once for one address one vote", func(t *testing.T) {
+		s := &strategies.OneAddressOneVote{}
+		r := models.NewProposalResults(proposal.ID, proposal.Choices)
+		results, err := s.TallyVotes(votes, r, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.Equal(t, 3, results.Results["a"])
+		assert.Equal(t, 1, results.Results["b"])
+	})
+
+	t.Run("Weights delegated balances separately for quadratic voting", func(t *testing.T) {
+		s := &strategies.QuadraticTokenWeighted{}
+		weight, err := s.GetVoteWeightForBalance(votes[0], proposal)
+		if err != nil {
+			t.Errorf("Error getting vote weight: %v", err)
+		}
+
+		assert.InDelta(t, 10.0+2*math.Sqrt(50), weight, 0.0001)
+	})
+
+	t.Run("Counts delegated balances when the delegate has no balance", func(t *testing.T) {
+		noBalance := []*models.VoteWithBalance{
+			{
+				Vote: models.Vote{
+					Proposal_id: proposal.ID,
+					Addr:        "0x0000000000000005",
+					Choice:      "b",
+				},
+				Delegations: []*models.DelegatedBalance{
+					{Addr: "0x0000000000000006", PrimaryAccountBalance: &delegated, StakingBalance: &delegated},
+				},
+			},
+		}
+
+		s := &strategies.TokenWeightedDefault{}
+		r := models.NewProposalResults(proposal.ID, proposal.Choices)
+		results, err := s.TallyVotes(noBalance, r, proposal)
+		if err != nil {
+			t.Errorf("Error tallying votes: %v", err)
+		}
+
+		assert.InDelta(t, 50.0, results.Results_float["b"], 0.0001)
+
+		weight, _ := s.GetVoteWeightForBalance(noBalance[0], proposal)
+		assert.InDelta(t, 50.0, weight, 0.0001)
+
+		staked := &strategies.StakedTokenWeightedDefault{}
+		weight, _ = staked.GetVoteWeightForBalance(noBalance[0], proposal)
+		assert.InDelta(t, 50.0, weight, 0.0001)
+
+		quadratic := &strategies.QuadraticTokenWeighted{}
+		weight, _ = quadratic.GetVoteWeightForBalance(noBalance[0], proposal)
+		assert.InDelta(t, math.Sqrt(50), weight, 0.0001)
+	})
+}
+
+func TestMultiStrategyTally(t *testing.T) {
+	tokenStrategy := "token-weighted-default"
+	nftStrategy := "balance-of-nfts"
+
+	newProposal := func(combination string) *models.Proposal {
+		return &models.Proposal{
+			ID:                   1,
+			Strategy:             &tokenStrategy,
+			Strategies:           &[]models.ProposalStrategy{{Name: tokenStrategy}, {Name: nftStrategy}},
+			Strategy_combination: &combination,
+			Choices: []shared.Choice{
+				{Choice_text: "a"},
+				{Choice_text: "b"},
+			},
+		}
+	}
+
+	tally := func(a, b float64) *models.ProposalResults {
+		r := models.NewProposalResults(1, []shared.Choice{{Choice_text: "a"}, {Choice_text: "b"}})
+		r.Results_float["a"] = a
+		r.Results_float["b"] = b
+		r.ComputeOutcome(&models.Proposal{Choices: newProposal("").Choices}, a+b)
+		return r
+	}
+
+	t.Run("Sums the normalized share of each strategy", func(t *testing.T) {
+		p := newProposal(models.CombineSumNormalized)
+		tallies := map[string]*models.ProposalResults{
+			tokenStrategy: tally(300, 100),
+			nftStrategy:   tally(1, 3),
+		}
+
+		results := models.CombineStrategyResults(p, tallies)
+
+		assert.InDelta(t, 50.0, results.Results_float["a"], 0.0001)
+		assert.InDelta(t, 50.0, results.Results_float["b"], 0.0001)
+		assert.Equal(t, models.Failed, *results.Outcome)
+		assert.Equal(t, 2, len(results.Strategy_results))
+	})
+
+	t.Run("Passes sub-tallies only if every strategy agrees", func(t *testing.T) {
+		p := newProposal(models.CombineSubTallies)
+		results := models.CombineStrategyResults(p, map[string]*models.ProposalResults{
+			tokenStrategy: tally(300, 100),
+			nftStrategy:   tally(3, 1),
+		})
+		assert.Equal(t, models.Passed, *results.Outcome)
+		assert.Equal(t, "a", *results.Winning_choice)
+
+		results = models.CombineStrategyResults(p, map[string]*models.ProposalResults{
+			tokenStrategy: tally(300, 100),
+			nftStrategy:   tally(1, 3),
+		})
+		assert.Equal(t, models.Failed, *results.Outcome)
+		assert.Nil(t, results.Winning_choice)
+	})
+
+	t.Run("Rejects combining two token strategies", func(t *testing.T) {
+		p := newProposal(models.CombineSumNormalized)
+		p.Strategies = &[]models.ProposalStrategy{
+			{Name: tokenStrategy},
+			{Name: "staked-token-weighted-default"},
+		}
+		assert.Error(t, p.ValidateStrategies())
+	})
+}
+
+func TestStrategyDescriptors(t *testing.T) {
+	t.Run("Only NFT strategies should track NFTs", func(t *testing.T) {
+		assert.True(t, models.IsNFTStrategy("balance-of-nfts"))
+		assert.True(t, models.IsNFTStrategy("float-nfts"))
+		assert.False(t, models.IsNFTStrategy("custom-script"))
+		assert.False(t, models.IsNFTStrategy("token-weighted-default"))
+	})
+
+	t.Run("A custom script strategy requires the contract it reads", func(t *testing.T) {
+		d, ok := models.GetStrategyDescriptor("custom-script")
+		assert.True(t, ok)
+		assert.Equal(t, []string{"name", "addr", "publicPath", "script"}, d.Contract_fields)
+	})
+}
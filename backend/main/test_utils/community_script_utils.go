@@ -0,0 +1,85 @@
+package test_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	s "github.com/DapperCollectives/CAST/backend/main/shared"
+)
+
+/////////////////////
+// Community Scripts
+/////////////////////
+
+var DefaultCommunityScriptKey = "holds-example-nft"
+var DefaultCommunityScriptSrc = `import NonFungibleToken from "NON_FUNGIBLE_TOKEN_ADDRESS"
+
+pub fun main(address: Address): [UInt64] {
+    return []
+}
+`
+
+func (otu *OverflowTestUtils) GetCommunityScriptsAPI(communityId int) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/communities/"+strconv.Itoa(communityId)+"/scripts", nil)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) CreateCommunityScriptAPI(payload *models.CommunityScriptPayload) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", "/communities/"+strconv.Itoa(payload.Community_id)+"/scripts", bytes.NewBuffer(json))
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) UpdateCommunityScriptAPI(payload *models.CommunityScriptPayload) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(
+		"PUT",
+		"/communities/"+strconv.Itoa(payload.Community_id)+"/scripts/"+payload.Key,
+		bytes.NewBuffer(json),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) DeleteCommunityScriptAPI(payload *models.CommunityScriptPayload) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(
+		"DELETE",
+		"/communities/"+strconv.Itoa(payload.Community_id)+"/scripts/"+payload.Key,
+		bytes.NewBuffer(json),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) GenerateCommunityScriptPayload(signer string, communityId int) *models.CommunityScriptPayload {
+	var timestamp = fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond))
+	compositeSigs := otu.GenerateCompositeSignatures(signer, timestamp)
+
+	payload := models.CommunityScriptPayload{
+		CommunityScript: models.CommunityScript{
+			Community_id: communityId,
+			Key:          DefaultCommunityScriptKey,
+			Name:         "Holds Example NFT",
+			Src:          DefaultCommunityScriptSrc,
+		},
+		Contract: s.Contract{
+			Name:        &exampleNFTName,
+			Addr:        &exampleNFTAddr,
+			Public_path: &exampleNFTPublicPath,
+		},
+	}
+	payload.Composite_signatures = compositeSigs
+	payload.Timestamp = timestamp
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+	payload.Signing_addr = fmt.Sprintf("0x%s", account.Address().String())
+
+	return &payload
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
 )
 
 var DefaultAuthor = models.CommunityUser{
@@ -28,10 +29,12 @@ func (otu *OverflowTestUtils) GenerateCommunityUserPayload(signer string, user *
 	hexTimestamp := hex.EncodeToString([]byte(fmt.Sprint(timestamp)))
 
 	var signedPayload = models.CommunityUserPayload{
-		CommunityUser:        *user,
-		Timestamp:            hexTimestamp,
-		Composite_signatures: compositeSignatures,
-		Signing_addr:         signingAddr,
+		CommunityUser: *user,
+		TimestampSignaturePayload: shared.TimestampSignaturePayload{
+			Timestamp:            hexTimestamp,
+			Composite_signatures: compositeSignatures,
+			Signing_addr:         signingAddr,
+		},
 	}
 
 	return &signedPayload
@@ -33,11 +33,22 @@ func (otu *OverflowTestUtils) AddDummyVotesAndBalances(votes []*models.VoteWithB
 			return err
 		}
 
-		//Insert Balance
+		// Insert Balance, of the token the proposal's strategy weighs
+		p := models.Proposal{ID: vote.Vote.Proposal_id}
+		if err := p.GetProposalById(otu.A.DB); err != nil {
+			log.Error().Err(err).Msg("AddDummyVotesAndBalances database error - proposal.")
+			return err
+		}
+		tokenId, err := models.BalanceTokenId(otu.A.DB, p.ID, *p.Strategy)
+		if err != nil {
+			log.Error().Err(err).Msg("AddDummyVotesAndBalances error - balance token.")
+			return err
+		}
+
 		_, err = otu.A.DB.Conn.Exec(otu.A.DB.Context, `
-			INSERT INTO balances(id, addr, primary_account_balance, secondary_address, secondary_account_balance, staking_balance, script_result, stakes, block_height)
-			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		`, uuid.New(), vote.Addr, vote.PrimaryAccountBalance, "0x0", 0, vote.StakingBalance, "SUCCESS", []string{}, 1)
+			INSERT INTO balances(id, addr, primary_account_balance, secondary_address, secondary_account_balance, staking_balance, script_result, stakes, block_height, fungible_token_id)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, uuid.New(), vote.Addr, vote.PrimaryAccountBalance, "0x0", 0, vote.StakingBalance, "SUCCESS", []string{}, 1, tokenId)
 		if err != nil {
 			log.Error().Err(err).Msg("AddDummyVotesAndBalances database error - balances.")
 			return err
@@ -47,6 +58,17 @@ func (otu *OverflowTestUtils) AddDummyVotesAndBalances(votes []*models.VoteWithB
 	return nil
 }
 
+// Inserts a balance of another token at the dummy block height.
+func (otu *OverflowTestUtils) AddBalanceOfToken(addr string, balance uint64, tokenId string) {
+	_, err := otu.A.DB.Conn.Exec(otu.A.DB.Context, `
+		INSERT INTO balances(id, addr, primary_account_balance, secondary_address, secondary_account_balance, staking_balance, script_result, stakes, block_height, fungible_token_id)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, uuid.New(), addr, balance, "0x0", 0, 0, "SUCCESS", []string{}, 1, tokenId)
+	if err != nil {
+		otu.T.Errorf("Error adding %s balance for %s: %v", tokenId, addr, err)
+	}
+}
+
 func (otu *OverflowTestUtils) AddDummyVotesAndNFTs(votes []*models.VoteWithBalance) {
 	for _, vote := range votes {
 
@@ -272,6 +294,46 @@ func (otu *OverflowTestUtils) UpdateProposalEndTime(pId int, endTime time.Time)
 	}
 }
 
+func (otu *OverflowTestUtils) UpdateCommunityStrategies(communityId int, strategies []models.Strategy) {
+	_, err := otu.A.DB.Conn.Exec(otu.A.DB.Context,
+		`
+		UPDATE communities SET strategies = $2 WHERE id = $1
+		`, communityId, strategies)
+	if err != nil {
+		log.Error().Err(err).Msg("Update community strategies database err.")
+	}
+}
+
+func (otu *OverflowTestUtils) ScheduleDraft(pId int, publishAt time.Time, endTime time.Time) {
+	_, err := otu.A.DB.Conn.Exec(otu.A.DB.Context,
+		`
+		UPDATE proposals SET publish_at = $2, end_time = $3 WHERE id = $1
+		`, pId, publishAt, endTime)
+	if err != nil {
+		log.Error().Err(err).Msg("Update proposal publish_at database err.")
+	}
+}
+
+// Creates a community linked to a community of the VotingCommunity
+// contract on the emulator, returning both ids.
+func (otu *OverflowTestUtils) AddOnchainCommunity() (int, uint64) {
+	onchainId := otu.O.TransactionFromFile("setup_voting_community").
+		SignProposeAndPayAsService().
+		Args(otu.O.Arguments().String("test community")).
+		RunGetIdFromEvent("CommunityCreated", "id")
+
+	communityId := otu.AddCommunities(1)[0]
+	_, err := otu.A.DB.Conn.Exec(otu.A.DB.Context,
+		`
+		UPDATE communities SET onchain_id = $2 WHERE id = $1
+		`, communityId, onchainId)
+	if err != nil {
+		log.Error().Err(err).Msg("Update community onchain_id database err.")
+	}
+
+	return communityId, onchainId
+}
+
 func (otu *OverflowTestUtils) AddLists(cId int, count int) []int {
 	if count < 1 {
 		count = 1
@@ -0,0 +1,49 @@
+package test_utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+)
+
+////////////
+// Pin Jobs
+////////////
+
+func (otu *OverflowTestUtils) GetStuckPinsAPI(communityId int, status string, signer string) *httptest.ResponseRecorder {
+	url := "/communities/" + strconv.Itoa(communityId) + "/pin-jobs"
+	if status != "" {
+		url += "?status=" + status
+	}
+	req, _ := http.NewRequest("GET", url, nil)
+	if signer != "" {
+		otu.SignRequest(req, signer)
+	}
+	return otu.ExecuteRequest(req)
+}
+
+// Queues a pin for the vote as if pinning it to IPFS had failed.
+func (otu *OverflowTestUtils) AddPendingVotePin(communityId, voteId int) *models.PinJob {
+	_, err := otu.A.DB.Conn.Exec(otu.A.DB.Context,
+		`UPDATE votes SET cid = NULL, pin_status = 'pending' WHERE id = $1`, voteId)
+	if err != nil {
+		otu.T.Errorf("Error setting vote %d pending: %v", voteId, err)
+	}
+
+	now := time.Now().UTC()
+	job := models.PinJob{
+		Community_id:    communityId,
+		Record_type:     models.PinRecordVote,
+		Record_id:       voteId,
+		Payload:         []byte(`{"vote":{"choice":"a"}}`),
+		Status:          models.PinPending,
+		Next_attempt_at: &now,
+	}
+	if err := job.CreatePinJob(otu.A.DB); err != nil {
+		otu.T.Errorf("Error queueing pin for vote %d: %v", voteId, err)
+	}
+	return &job
+}
@@ -50,6 +50,15 @@ func (otu *OverflowTestUtils) GetProposalByIdAPI(communityId int, proposalId int
 	return response
 }
 
+func (otu *OverflowTestUtils) GetDraftByIdAPI(communityId int, proposalId int, signer string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/communities/"+strconv.Itoa(communityId)+"/proposals/"+strconv.Itoa(proposalId), nil)
+	if signer != "" {
+		otu.SignRequest(req, signer)
+	}
+	response := otu.ExecuteRequest(req)
+	return response
+}
+
 func (otu *OverflowTestUtils) CreateProposalAPI(proposal *models.Proposal) *httptest.ResponseRecorder {
 	json, _ := json.Marshal(proposal)
 	req, _ := http.NewRequest(
@@ -71,11 +80,47 @@ func (otu *OverflowTestUtils) UpdateProposalAPI(
 	return otu.ExecuteRequest(req)
 }
 
+func (otu *OverflowTestUtils) UpdateDraftAPI(
+	proposalId int,
+	payload *models.DraftProposalPayload,
+) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("PUT", "/proposals/"+strconv.Itoa(proposalId)+"/draft", bytes.NewBuffer(json))
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) EditProposalAPI(
+	proposalId int,
+	payload *models.EditProposalPayload,
+) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("PATCH", "/proposals/"+strconv.Itoa(proposalId), bytes.NewBuffer(json))
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) GetProposalRevisionsAPI(proposalId int) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/proposals/"+strconv.Itoa(proposalId)+"/revisions", nil)
+	return otu.ExecuteRequest(req)
+}
+
 func (otu *OverflowTestUtils) GetProposalResultsAPI(proposalId int) *httptest.ResponseRecorder {
 	req, _ := http.NewRequest("GET", "/proposals/"+strconv.Itoa(proposalId)+"/results", nil)
 	return otu.ExecuteRequest(req)
 }
 
+func (otu *OverflowTestUtils) GetProposalAuditAPI(proposalId int) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/proposals/"+strconv.Itoa(proposalId)+"/audit", nil)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) ExportProposalsAPI(communityId int, format string) *httptest.ResponseRecorder {
+	url := fmt.Sprintf("/communities/%d/proposals/export?format=%s", communityId, format)
+	req, _ := http.NewRequest("GET", url, nil)
+	return otu.ExecuteRequest(req)
+}
+
 func (otu *OverflowTestUtils) GenerateProposalStruct(signer string, communityId int) *models.Proposal {
 	// deep copy
 	proposal := DefaultProposalStruct
@@ -103,6 +148,45 @@ func (otu *OverflowTestUtils) GenerateCancelProposalStruct(
 	return &payload
 }
 
+func (otu *OverflowTestUtils) GeneratePublishProposalStruct(
+	signer string,
+	proposalId int,
+) *models.UpdateProposalRequestPayload {
+	payload := models.UpdateProposalRequestPayload{Status: models.ProposalPublished}
+	timestamp := fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond))
+	compositeSignatures := otu.GenerateCompositeSignatures(signer, timestamp)
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+	payload.Signing_addr = fmt.Sprintf("0x%s", account.Address().String())
+	payload.Timestamp = timestamp
+	payload.Composite_signatures = compositeSignatures
+
+	return &payload
+}
+
+func (otu *OverflowTestUtils) GenerateDraftPayload(signer string, name string) *models.DraftProposalPayload {
+	payload := models.DraftProposalPayload{Name: &name}
+	timestamp := fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond))
+	compositeSignatures := otu.GenerateCompositeSignatures(signer, timestamp)
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+	payload.Signing_addr = fmt.Sprintf("0x%s", account.Address().String())
+	payload.Timestamp = timestamp
+	payload.Composite_signatures = compositeSignatures
+
+	return &payload
+}
+
+func (otu *OverflowTestUtils) GenerateEditProposalPayload(signer string, body string) *models.EditProposalPayload {
+	payload := models.EditProposalPayload{Body: &body}
+	timestamp := fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond))
+	compositeSignatures := otu.GenerateCompositeSignatures(signer, timestamp)
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+	payload.Signing_addr = fmt.Sprintf("0x%s", account.Address().String())
+	payload.Timestamp = timestamp
+	payload.Composite_signatures = compositeSignatures
+
+	return &payload
+}
+
 func (otu *OverflowTestUtils) GenerateClosedProposalStruct(
 	signer string,
 	proposalId int,
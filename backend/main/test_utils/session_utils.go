@@ -0,0 +1,106 @@
+package test_utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/DapperCollectives/CAST/backend/main/server"
+	"github.com/DapperCollectives/CAST/backend/main/shared"
+)
+
+////////////
+// Sessions
+////////////
+
+func (otu *OverflowTestUtils) CreateAuthNonceAPI() *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/auth/nonce", nil)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) LoginAPI(payload *models.LoginPayload) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(json))
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) LogoutAPI(token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) RevokeSessionsAPI(token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("DELETE", "/auth/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) CreateListWithSessionAPI(payload *models.ListPayload, token string) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", "/communities/"+strconv.Itoa(payload.Community_id)+"/lists", bytes.NewBuffer(json))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) CreateAuthNonce() string {
+	response := otu.CreateAuthNonceAPI()
+	var nonce server.AuthNonceResponse
+	json.Unmarshal(response.Body.Bytes(), &nonce)
+	return nonce.Nonce
+}
+
+// Signs an account proof of the nonce for the address, as the signer.
+func (otu *OverflowTestUtils) GenerateLoginPayload(signer, address, nonce string) *models.LoginPayload {
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+
+	message, _ := shared.EncodeAccountProof(address, nonce, otu.A.Config.AppIdentifier)
+	messageBytes, _ := hex.DecodeString(message)
+
+	tag := make([]byte, 32)
+	copy(tag, shared.AccountProofDomainTag)
+
+	s, _ := account.Key().Signer(context.Background())
+	signature, _ := s.Sign(append(tag, messageBytes...))
+
+	return &models.LoginPayload{
+		Addr:  address,
+		Nonce: nonce,
+		Signatures: &[]shared.CompositeSignature{{
+			Addr:      address,
+			Key_id:    0,
+			Signature: hex.EncodeToString(signature),
+		}},
+	}
+}
+
+// Signs the account in and returns its session token.
+func (otu *OverflowTestUtils) Login(signer string) string {
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+	address := fmt.Sprintf("0x%s", account.Address().String())
+
+	response := otu.LoginAPI(otu.GenerateLoginPayload(signer, address, otu.CreateAuthNonce()))
+	var session models.Session
+	json.Unmarshal(response.Body.Bytes(), &session)
+	return session.Token
+}
+
+// Signs a request without a body as the signer, in its headers.
+func (otu *OverflowTestUtils) SignRequest(req *http.Request, signer string) {
+	timestamp := fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond))
+	signatures, _ := json.Marshal(otu.GenerateCompositeSignatures(signer, timestamp))
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+
+	req.Header.Set("X-Signing-Addr", fmt.Sprintf("0x%s", account.Address().String()))
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Composite-Signatures", string(signatures))
+}
@@ -63,6 +63,31 @@ func (otu *OverflowTestUtils) CreateVoteAPI(proposalId int, payload *models.Vote
 	return otu.ExecuteRequest(req)
 }
 
+func (otu *OverflowTestUtils) UpdateVoteAPI(proposalId int, payload *models.Vote) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	url := fmt.Sprintf("/proposals/%d/votes/%s", proposalId, payload.Addr)
+	req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(json))
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) GetVoteHistoryAPI(proposalId int, address string) *httptest.ResponseRecorder {
+	url := fmt.Sprintf("/proposals/%d/votes/%s/history", proposalId, address)
+	req, _ := http.NewRequest("GET", url, nil)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) GetDelegationsToAddressAPI(address string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/users/"+address+"/delegations/in", nil)
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) ExportVotesAPI(proposalId int, format string) *httptest.ResponseRecorder {
+	url := fmt.Sprintf("/proposals/%d/votes/export?format=%s", proposalId, format)
+	req, _ := http.NewRequest("GET", url, nil)
+	return otu.ExecuteRequest(req)
+}
+
 func (otu *OverflowTestUtils) GenerateValidVotePayload(accountName string, proposalId int, choice string) *models.Vote {
 	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
 	hexChoice := hex.EncodeToString([]byte(choice))
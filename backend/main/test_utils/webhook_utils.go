@@ -0,0 +1,66 @@
+package test_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+)
+
+////////////
+// Webhooks
+////////////
+
+var DefaultWebhookUrl = "https://example.com/cast-webhook"
+var DefaultWebhookEvents = []string{models.ProposalCreatedEvent, models.VoteCastEvent}
+
+func (otu *OverflowTestUtils) GetWebhooksForCommunityAPI(communityId int, signer string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/communities/"+strconv.Itoa(communityId)+"/webhooks", nil)
+	if signer != "" {
+		otu.SignRequest(req, signer)
+	}
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) CreateWebhookAPI(payload *models.WebhookPayload) *httptest.ResponseRecorder {
+	json, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", "/communities/"+strconv.Itoa(payload.Community_id)+"/webhooks", bytes.NewBuffer(json))
+	req.Header.Set("Content-Type", "application/json")
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) GetWebhookDeliveriesAPI(communityId, webhookId int, signer string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(
+		"GET",
+		"/communities/"+strconv.Itoa(communityId)+"/webhooks/"+strconv.Itoa(webhookId)+"/deliveries",
+		nil,
+	)
+	if signer != "" {
+		otu.SignRequest(req, signer)
+	}
+	return otu.ExecuteRequest(req)
+}
+
+func (otu *OverflowTestUtils) GenerateWebhookPayload(signer string, communityId int) *models.WebhookPayload {
+	var timestamp = fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond))
+	compositeSigs := otu.GenerateCompositeSignatures(signer, timestamp)
+
+	payload := models.WebhookPayload{
+		Webhook: models.Webhook{
+			Community_id: communityId,
+			Url:          DefaultWebhookUrl,
+			Events:       DefaultWebhookEvents,
+		},
+	}
+	payload.Composite_signatures = compositeSigs
+	payload.Timestamp = timestamp
+	account, _ := otu.O.State.Accounts().ByName(fmt.Sprintf("emulator-%s", signer))
+	payload.Signing_addr = fmt.Sprintf("0x%s", account.Address().String())
+
+	return &payload
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DapperCollectives/CAST/backend/main/models"
 	"github.com/DapperCollectives/CAST/backend/main/shared"
@@ -129,3 +132,154 @@ func TestCreateVote(t *testing.T) {
 		assert.Equal(t, 1, createdVote.ID)
 	})
 }
+
+func TestUpdateVote(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("votes")
+	communityId := otu.AddCommunities(1)[0]
+	proposalId := otu.AddActiveProposals(communityId, 1)[0]
+
+	t.Run("should not update a vote that does not exist", func(t *testing.T) {
+		votePayload := otu.GenerateValidVotePayload("user2", proposalId, "a")
+
+		response := otu.UpdateVoteAPI(proposalId, votePayload)
+		CheckResponseCode(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should replace the vote and keep the previous vote in its history", func(t *testing.T) {
+		votePayload := otu.GenerateValidVotePayload("user1", proposalId, "a")
+		response := otu.CreateVoteAPI(proposalId, votePayload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+
+		var originalVote models.Vote
+		json.Unmarshal(response.Body.Bytes(), &originalVote)
+
+		votePayload = otu.GenerateValidVotePayload("user1", proposalId, "b")
+		response = otu.UpdateVoteAPI(proposalId, votePayload)
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		response = otu.GetVoteForProposalByAccountNameAPI(proposalId, "user1")
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		var updatedVote models.Vote
+		json.Unmarshal(response.Body.Bytes(), &updatedVote)
+		assert.Equal(t, "b", updatedVote.Choice)
+		assert.Equal(t, originalVote.ID, updatedVote.ID)
+
+		response = otu.GetVoteHistoryAPI(proposalId, votePayload.Addr)
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		var history []models.VoteHistory
+		json.Unmarshal(response.Body.Bytes(), &history)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, "a", history[0].Choice)
+		assert.Equal(t, originalVote.Cid, history[0].Cid)
+	})
+}
+
+func TestSealedBallot(t *testing.T) {
+	key := strings.Repeat("ab", 32)
+	choices := []string{"a", "b"}
+	signatures := []shared.CompositeSignature{{Addr: "0x0000000000000001", Key_id: 0, Signature: "signature"}}
+	vote := models.Vote{
+		Proposal_id:          1,
+		Addr:                 "0x0000000000000001",
+		Choice:               "a",
+		Choices:              &choices,
+		Message:              "message",
+		Composite_signatures: &signatures,
+	}
+
+	t.Run("should hide the ballot until it is opened", func(t *testing.T) {
+		sealed := vote
+		err := sealed.SealBallot(key)
+		assert.Nil(t, err)
+		assert.NotNil(t, sealed.Sealed_ballot)
+		assert.Equal(t, "", sealed.Choice)
+		assert.Nil(t, sealed.Choices)
+		assert.Equal(t, "", sealed.Message)
+		assert.Nil(t, sealed.Composite_signatures)
+
+		err = sealed.OpenBallot(key)
+		assert.Nil(t, err)
+		assert.Equal(t, "a", sealed.Choice)
+		assert.Equal(t, choices, *sealed.Choices)
+		assert.Equal(t, "message", sealed.Message)
+		assert.Equal(t, signatures, *sealed.Composite_signatures)
+	})
+
+	t.Run("should hide when a sealed ballot was cast", func(t *testing.T) {
+		sealed := vote
+		sealed.Created_at = time.Now().UTC()
+		err := sealed.SealBallot(key)
+		assert.Nil(t, err)
+
+		sealed.HideCastTime()
+		assert.True(t, sealed.Created_at.IsZero())
+
+		history := models.VoteHistory{
+			Proposal_id:   sealed.Proposal_id,
+			Addr:          sealed.Addr,
+			Sealed_ballot: sealed.Sealed_ballot,
+			Cast_at:       time.Now().UTC(),
+		}
+		history.HideCastTime()
+		assert.True(t, history.Cast_at.IsZero())
+
+		err = history.OpenBallot(key)
+		assert.Nil(t, err)
+		assert.Equal(t, "a", history.Choice)
+		assert.Equal(t, signatures, *history.Composite_signatures)
+	})
+
+	t.Run("should not open a ballot moved to another voter", func(t *testing.T) {
+		sealed := vote
+		err := sealed.SealBallot(key)
+		assert.Nil(t, err)
+
+		sealed.Addr = "0x0000000000000002"
+		err = sealed.OpenBallot(key)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestExportVotes(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("proposals")
+	clearTable("votes")
+	communityId := otu.AddCommunities(1)[0]
+	proposalId := otu.AddActiveProposals(communityId, 1)[0]
+
+	for _, account := range []string{"user1", "user2"} {
+		votePayload := otu.GenerateValidVotePayload(account, proposalId, "a")
+		response := otu.CreateVoteAPI(proposalId, votePayload)
+		CheckResponseCode(t, http.StatusCreated, response.Code)
+	}
+
+	t.Run("should export every vote as csv", func(t *testing.T) {
+		response := otu.ExportVotesAPI(proposalId, "csv")
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		rows, err := csv.NewReader(response.Body).ReadAll()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(rows))
+		assert.Equal(t, "addr", rows[0][0])
+		assert.Equal(t, "a", rows[1][1])
+	})
+
+	t.Run("should export every vote as ndjson", func(t *testing.T) {
+		response := otu.ExportVotesAPI(proposalId, "ndjson")
+		CheckResponseCode(t, http.StatusOK, response.Code)
+
+		lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+		assert.Equal(t, 2, len(lines))
+	})
+
+	t.Run("should reject an unknown format", func(t *testing.T) {
+		response := otu.ExportVotesAPI(proposalId, "xlsx")
+		CheckResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+}
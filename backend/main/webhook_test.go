@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DapperCollectives/CAST/backend/main/models"
+	"github.com/stretchr/testify/assert"
+)
+
+/*****************/
+/*   Webhooks    */
+/*****************/
+
+func TestCreateWebhook(t *testing.T) {
+	clearTable("communities")
+	clearTable("community_users")
+	clearTable("webhooks")
+
+	t.Run("Community admin should be able to create a webhook", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateWebhookPayload("user1", communityId)
+		response := otu.CreateWebhookAPI(payload)
+		checkResponseCode(t, http.StatusCreated, response.Code)
+
+		var webhook models.Webhook
+		json.Unmarshal(response.Body.Bytes(), &webhook)
+
+		assert.NotEmpty(t, webhook.Secret)
+		assert.True(t, webhook.Is_active)
+		assert.Equal(t, payload.Events, webhook.Events)
+
+		response = otu.GetWebhooksForCommunityAPI(communityId, "user1")
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var webhooks []models.Webhook
+		json.Unmarshal(response.Body.Bytes(), &webhooks)
+		assert.Equal(t, 1, len(webhooks))
+		assert.Empty(t, webhooks[0].Secret)
+
+		response = otu.GetWebhookDeliveriesAPI(communityId, webhook.ID, "user1")
+		checkResponseCode(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Non admins should not be able to create a webhook", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateWebhookPayload("user2", communityId)
+		response := otu.CreateWebhookAPI(payload)
+		checkResponseCode(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Only community admins should be able to read webhooks", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		response := otu.CreateWebhookAPI(otu.GenerateWebhookPayload("user1", communityId))
+		checkResponseCode(t, http.StatusCreated, response.Code)
+
+		var webhook models.Webhook
+		json.Unmarshal(response.Body.Bytes(), &webhook)
+
+		response = otu.GetWebhooksForCommunityAPI(communityId, "")
+		checkResponseCode(t, http.StatusUnauthorized, response.Code)
+		response = otu.GetWebhooksForCommunityAPI(communityId, "user2")
+		checkResponseCode(t, http.StatusForbidden, response.Code)
+
+		response = otu.GetWebhookDeliveriesAPI(communityId, webhook.ID, "")
+		checkResponseCode(t, http.StatusUnauthorized, response.Code)
+		response = otu.GetWebhookDeliveriesAPI(communityId, webhook.ID, "user2")
+		checkResponseCode(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Should reject webhook urls that are not public https urls", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		for _, url := range []string{
+			"http://example.com/cast-webhook",
+			"https://localhost/cast-webhook",
+			"https://127.0.0.1/cast-webhook",
+			"https://10.0.0.1/cast-webhook",
+			"https://192.168.1.1:8443/cast-webhook",
+			"https://169.254.169.254/latest/meta-data",
+			"https://[::1]/cast-webhook",
+		} {
+			payload := otu.GenerateWebhookPayload("user1", communityId)
+			payload.Url = url
+			response := otu.CreateWebhookAPI(payload)
+			checkResponseCode(t, http.StatusBadRequest, response.Code)
+		}
+	})
+
+	t.Run("Should reject unknown events", func(t *testing.T) {
+		communityId := otu.AddCommunitiesWithUsers(1, "user1")[0]
+		payload := otu.GenerateWebhookPayload("user1", communityId)
+		payload.Events = []string{"proposal.deleted"}
+		response := otu.CreateWebhookAPI(payload)
+		checkResponseCode(t, http.StatusBadRequest, response.Code)
+	})
+}
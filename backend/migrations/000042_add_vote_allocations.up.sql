@@ -0,0 +1 @@
+ALTER TABLE votes ADD COLUMN allocations jsonb;
@@ -0,0 +1,7 @@
+BEGIN;
+ALTER TYPE strategies ADD VALUE IF NOT EXISTS 'quadratic-token-weighted';
+END TRANSACTION;
+COMMIT;
+
+INSERT INTO voting_strategies (key, name, description)
+VALUES ('quadratic-token-weighted', 'Quadratic Token Weighted', 'Vote weight is the square root of the voter''s token balance at the proposal snapshot.');
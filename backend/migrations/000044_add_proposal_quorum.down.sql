@@ -0,0 +1,4 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS quorum;
+ALTER TABLE proposals DROP COLUMN IF EXISTS quorum_type;
+ALTER TABLE proposals DROP COLUMN IF EXISTS pass_threshold;
+ALTER TABLE proposals DROP COLUMN IF EXISTS total_supply;
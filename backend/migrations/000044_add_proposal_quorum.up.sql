@@ -0,0 +1,4 @@
+ALTER TABLE proposals ADD COLUMN quorum DOUBLE PRECISION;
+ALTER TABLE proposals ADD COLUMN quorum_type VARCHAR(16);
+ALTER TABLE proposals ADD COLUMN pass_threshold DOUBLE PRECISION;
+ALTER TABLE proposals ADD COLUMN total_supply DOUBLE PRECISION;
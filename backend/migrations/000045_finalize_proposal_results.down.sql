@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS proposal_results_immutable ON proposal_results;
+DROP FUNCTION IF EXISTS prevent_proposal_results_update();
+ALTER TABLE proposal_results DROP CONSTRAINT IF EXISTS proposal_results_proposal_id_key;
+ALTER TABLE proposal_results DROP COLUMN IF EXISTS tally;
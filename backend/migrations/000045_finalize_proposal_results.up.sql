@@ -0,0 +1,42 @@
+-- Keep the latest results of each closed proposal that were tallied after
+-- it ended as its final results. Results of open proposals, or tallied
+-- before the proposal ended, are tallied again when they are next read.
+DELETE FROM proposal_results r
+USING proposals p
+WHERE p.id = r.proposal_id
+  AND (p.end_time > (now() at time zone 'utc') OR r.updated_at < p.end_time);
+
+DELETE FROM proposal_results
+WHERE ctid IN (
+  SELECT ctid FROM (
+    SELECT ctid, row_number() OVER (PARTITION BY proposal_id ORDER BY updated_at DESC NULLS LAST) AS n
+    FROM proposal_results
+  ) ranked
+  WHERE n > 1
+);
+
+ALTER TABLE proposal_results ADD COLUMN tally jsonb;
+
+UPDATE proposal_results r
+SET tally = jsonb_build_object(
+  'proposalId', r.proposal_id,
+  'results', r.results,
+  'updatedAt', to_char(r.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+  'cid', r.cid,
+  'achievementsDone', p.achievements_done
+)
+FROM proposals p
+WHERE p.id = r.proposal_id;
+
+ALTER TABLE proposal_results ADD CONSTRAINT proposal_results_proposal_id_key UNIQUE (proposal_id);
+
+-- Final results are immutable once written
+CREATE OR REPLACE FUNCTION prevent_proposal_results_update() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'proposal_results are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER proposal_results_immutable
+BEFORE UPDATE ON proposal_results
+FOR EACH ROW EXECUTE PROCEDURE prevent_proposal_results_update();
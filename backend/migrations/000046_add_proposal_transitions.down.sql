@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS proposal_transitions;
+ALTER TABLE proposals DROP COLUMN IF EXISTS processed_status;
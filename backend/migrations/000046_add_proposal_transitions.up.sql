@@ -0,0 +1,20 @@
+ALTER TABLE proposals ADD COLUMN processed_status VARCHAR(16);
+
+-- Existing proposals are treated as already processed
+UPDATE proposals SET processed_status = CASE
+  WHEN status = 'published' AND start_time > (now() at time zone 'utc') THEN 'pending'
+  WHEN status = 'published' AND start_time < (now() at time zone 'utc') AND end_time > (now() at time zone 'utc') THEN 'active'
+  WHEN status = 'published' AND end_time < (now() at time zone 'utc') THEN 'closed'
+  WHEN status = 'cancelled' THEN 'cancelled'
+  WHEN status = 'closed' THEN 'closed'
+END;
+
+CREATE TABLE proposal_transitions (
+  id BIGSERIAL primary key,
+  proposal_id INT not null references proposals(id),
+  from_status VARCHAR(16),
+  to_status VARCHAR(16) not null,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
+
+CREATE INDEX proposal_transitions_proposal_id_idx ON proposal_transitions(proposal_id);
@@ -0,0 +1,28 @@
+CREATE TABLE webhooks (
+  id BIGSERIAL primary key,
+  community_id INT not null references communities(id),
+  url VARCHAR(512) not null,
+  secret VARCHAR(128) not null,
+  events VARCHAR(32)[] not null,
+  is_active BOOLEAN not null default 'true',
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
+
+CREATE INDEX webhooks_community_id_idx ON webhooks(community_id);
+
+CREATE TABLE webhook_deliveries (
+  id BIGSERIAL primary key,
+  webhook_id INT not null references webhooks(id) ON DELETE CASCADE,
+  event VARCHAR(32) not null,
+  payload jsonb not null,
+  status VARCHAR(16) not null default 'pending',
+  attempts INT not null default 0,
+  response_status INT,
+  error TEXT,
+  next_attempt_at TIMESTAMP without time zone,
+  delivered_at TIMESTAMP without time zone,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries(webhook_id);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
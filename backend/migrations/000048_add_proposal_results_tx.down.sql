@@ -0,0 +1,7 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS results_tx_id;
+ALTER TABLE proposals DROP COLUMN IF EXISTS results_tx_status;
+ALTER TABLE proposals DROP COLUMN IF EXISTS results_tx_attempts;
+ALTER TABLE proposals DROP COLUMN IF EXISTS results_tx_error;
+ALTER TABLE proposals DROP COLUMN IF EXISTS results_tx_retry_at;
+
+ALTER TABLE communities DROP COLUMN IF EXISTS onchain_id;
@@ -0,0 +1,7 @@
+ALTER TABLE communities ADD COLUMN onchain_id BIGINT;
+
+ALTER TABLE proposals ADD COLUMN results_tx_id VARCHAR(64);
+ALTER TABLE proposals ADD COLUMN results_tx_status VARCHAR(16);
+ALTER TABLE proposals ADD COLUMN results_tx_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE proposals ADD COLUMN results_tx_error TEXT;
+ALTER TABLE proposals ADD COLUMN results_tx_retry_at TIMESTAMP without time zone;
@@ -0,0 +1,18 @@
+CREATE TABLE delegations (
+  id BIGSERIAL primary key,
+  community_id INT not null references communities(id),
+  delegator VARCHAR(18) not null,
+  delegate VARCHAR(18) not null,
+  strategy VARCHAR(64),
+  message TEXT not null,
+  composite_signatures jsonb not null,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc'),
+  revoked_at TIMESTAMP without time zone,
+  CHECK (delegator <> delegate)
+);
+
+-- an address has at most one active delegation per community and strategy
+CREATE UNIQUE INDEX delegations_active_idx ON delegations(community_id, delegator, COALESCE(strategy, ''))
+  WHERE revoked_at IS NULL;
+CREATE INDEX delegations_delegate_idx ON delegations(delegate);
+CREATE INDEX delegations_delegator_idx ON delegations(delegator);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vote_history;
+ALTER TABLE votes DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,18 @@
+ALTER TABLE votes ADD COLUMN updated_at TIMESTAMP without time zone;
+
+CREATE TABLE vote_history (
+  id BIGSERIAL primary key,
+  vote_id INT not null references votes(id) ON DELETE CASCADE,
+  proposal_id INT not null references proposals(id),
+  addr VARCHAR(18) not null,
+  choice VARCHAR(256) not null,
+  choices jsonb,
+  allocations jsonb,
+  composite_signatures jsonb,
+  cid VARCHAR(64),
+  message TEXT,
+  cast_at TIMESTAMP without time zone not null,
+  replaced_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
+
+CREATE INDEX vote_history_proposal_addr_idx ON vote_history(proposal_id, addr);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS proposal_keys;
+ALTER TABLE vote_history DROP COLUMN IF EXISTS sealed_ballot;
+ALTER TABLE votes DROP COLUMN IF EXISTS sealed_ballot;
+ALTER TABLE proposals DROP COLUMN IF EXISTS is_private;
@@ -0,0 +1,11 @@
+ALTER TABLE proposals ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT 'false';
+ALTER TABLE votes ADD COLUMN sealed_ballot TEXT;
+ALTER TABLE vote_history ADD COLUMN sealed_ballot TEXT;
+
+CREATE TABLE proposal_keys (
+  proposal_id INT primary key references proposals(id),
+  key VARCHAR(64) not null,
+  cid VARCHAR(64),
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc'),
+  revealed_at TIMESTAMP without time zone
+);
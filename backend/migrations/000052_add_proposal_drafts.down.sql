@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS proposals_scheduled_drafts_idx;
+ALTER TABLE proposals DROP COLUMN IF EXISTS publish_at;
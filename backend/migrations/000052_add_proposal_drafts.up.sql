@@ -0,0 +1,3 @@
+ALTER TABLE proposals ADD COLUMN publish_at TIMESTAMP without time zone;
+
+CREATE INDEX proposals_scheduled_drafts_idx ON proposals(publish_at) WHERE status = 'draft';
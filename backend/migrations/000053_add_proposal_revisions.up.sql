@@ -0,0 +1,13 @@
+CREATE TABLE proposal_revisions (
+  id BIGSERIAL primary key,
+  proposal_id INT not null references proposals(id) ON DELETE CASCADE,
+  revision INT not null,
+  name VARCHAR(256) not null,
+  body TEXT,
+  choices jsonb not null,
+  cid VARCHAR(64),
+  editor_addr VARCHAR(18) not null,
+  composite_signatures jsonb,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc'),
+  UNIQUE (proposal_id, revision)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS strategy_combination;
+ALTER TABLE proposals DROP COLUMN IF EXISTS strategies;
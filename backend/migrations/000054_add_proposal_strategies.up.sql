@@ -0,0 +1,2 @@
+ALTER TABLE proposals ADD COLUMN strategies jsonb;
+ALTER TABLE proposals ADD COLUMN strategy_combination VARCHAR(32);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS voting_strategies (
+  key strategies primary key,
+  name VARCHAR(128) not null,
+  description TEXT
+);
+
+INSERT INTO voting_strategies (key, name, description)
+VALUES ('token-weighted-default', 'Token-Weighted', 'A weight of 1 is added for each fungible token at a user’s wallet address that matches the contract of the proposal.');
+INSERT INTO voting_strategies (key, name, description)
+VALUES ('staked-token-weighted-default', 'Staked FLOW-Weighted', 'A weight of 1 is added for each FLOW token that a wallet address has staked or delegated to the Flow network.');
+INSERT INTO voting_strategies (key, name, description)
+VALUES ('balance-of-nfts', 'NFT-Weighted', 'A weight of 1 is added for each NFT at a user’s wallet address that matches the contract of the proposal.');
+INSERT INTO voting_strategies (key, name, description)
+VALUES ('float-nfts', 'Float NFTs', 'Vote weight is calculated via proof attendance using the Float Event ID');
+INSERT INTO voting_strategies (key, name, description)
+VALUES ('quadratic-token-weighted', 'Quadratic Token Weighted', 'Vote weight is the square root of the voter''s token balance at the proposal snapshot.');
+INSERT INTO voting_strategies (key, name, description)
+VALUES ('custom-script', 'Custom Script', 'Vote weight is calculated via a custom script.');
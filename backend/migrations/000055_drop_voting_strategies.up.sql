@@ -0,0 +1 @@
+DROP TABLE IF EXISTS voting_strategies;
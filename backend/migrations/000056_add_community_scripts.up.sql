@@ -0,0 +1,12 @@
+CREATE TABLE community_scripts (
+  id BIGSERIAL primary key,
+  community_id INT not null references communities(id),
+  key VARCHAR(64) not null,
+  name VARCHAR(128) not null,
+  description TEXT,
+  src TEXT not null,
+  creator_addr VARCHAR(18) not null,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc'),
+  updated_at TIMESTAMP without time zone default (now() at time zone 'utc'),
+  UNIQUE (community_id, key)
+);
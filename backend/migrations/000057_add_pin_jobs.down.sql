@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pin_jobs;
+ALTER TABLE proposals DROP COLUMN IF EXISTS pin_status;
+ALTER TABLE votes DROP COLUMN IF EXISTS pin_status;
@@ -0,0 +1,19 @@
+ALTER TABLE votes ADD COLUMN pin_status VARCHAR(16) not null default 'pinned';
+ALTER TABLE proposals ADD COLUMN pin_status VARCHAR(16) not null default 'pinned';
+
+CREATE TABLE pin_jobs (
+  id BIGSERIAL primary key,
+  community_id INT not null references communities(id) ON DELETE CASCADE,
+  record_type VARCHAR(16) not null,
+  record_id INT not null,
+  payload jsonb not null,
+  status VARCHAR(16) not null default 'pending',
+  attempts INT not null default 0,
+  error TEXT,
+  next_attempt_at TIMESTAMP without time zone,
+  pinned_at TIMESTAMP without time zone,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
+
+CREATE INDEX pin_jobs_record_idx ON pin_jobs(record_type, record_id);
+CREATE INDEX pin_jobs_pending_idx ON pin_jobs(next_attempt_at) WHERE status = 'pending';
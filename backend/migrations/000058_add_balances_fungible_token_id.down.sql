@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS balances_token_snapshot_idx;
+ALTER TABLE balances DROP COLUMN IF EXISTS fungible_token_id;
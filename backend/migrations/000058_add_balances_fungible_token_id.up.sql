@@ -0,0 +1,4 @@
+ALTER TABLE balances ADD COLUMN fungible_token_id VARCHAR(128) not null default '';
+
+CREATE UNIQUE INDEX balances_token_snapshot_idx ON balances(addr, block_height, fungible_token_id)
+  WHERE fungible_token_id <> '';
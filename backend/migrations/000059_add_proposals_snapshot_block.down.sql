@@ -0,0 +1,2 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS snapshot_block_height;
+ALTER TABLE proposals DROP COLUMN IF EXISTS snapshot_time;
@@ -0,0 +1,2 @@
+ALTER TABLE proposals ADD COLUMN snapshot_time TIMESTAMP;
+ALTER TABLE proposals ADD COLUMN snapshot_block_height BIGINT;
@@ -0,0 +1,16 @@
+CREATE TABLE auth_nonces (
+  nonce VARCHAR(64) primary key,
+  expires_at TIMESTAMP without time zone not null,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
+
+CREATE TABLE sessions (
+  id BIGSERIAL primary key,
+  addr VARCHAR(18) not null,
+  token_hash VARCHAR(64) not null unique,
+  expires_at TIMESTAMP without time zone not null,
+  revoked_at TIMESTAMP without time zone,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
+
+CREATE INDEX sessions_addr_idx ON sessions(addr);
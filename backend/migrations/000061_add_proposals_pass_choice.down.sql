@@ -0,0 +1 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS pass_choice;
@@ -0,0 +1 @@
+ALTER TABLE proposals ADD COLUMN pass_choice TEXT;
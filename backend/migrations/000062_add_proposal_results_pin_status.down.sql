@@ -0,0 +1,7 @@
+CREATE OR REPLACE FUNCTION prevent_proposal_results_update() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'proposal_results are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE proposal_results DROP COLUMN IF EXISTS pin_status;
@@ -0,0 +1,16 @@
+ALTER TABLE proposal_results ADD COLUMN pin_status VARCHAR(16) NOT NULL DEFAULT 'pinned';
+
+-- Final results are immutable once written, except for setting the cid
+-- of results whose pin was queued
+CREATE OR REPLACE FUNCTION prevent_proposal_results_update() RETURNS trigger AS $$
+BEGIN
+  IF NEW.proposal_id IS DISTINCT FROM OLD.proposal_id
+    OR NEW.results::text IS DISTINCT FROM OLD.results::text
+    OR NEW.tally IS DISTINCT FROM OLD.tally
+    OR NEW.updated_at IS DISTINCT FROM OLD.updated_at
+    OR (OLD.cid IS NOT NULL AND NEW.cid IS DISTINCT FROM OLD.cid) THEN
+    RAISE EXCEPTION 'proposal_results are immutable';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS transition_attempts;
+ALTER TABLE proposals DROP COLUMN IF EXISTS transition_retry_at;
@@ -0,0 +1,2 @@
+ALTER TABLE proposals ADD COLUMN transition_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE proposals ADD COLUMN transition_retry_at TIMESTAMP without time zone;
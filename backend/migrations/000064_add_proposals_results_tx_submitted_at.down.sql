@@ -0,0 +1 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS results_tx_submitted_at;
@@ -0,0 +1 @@
+ALTER TABLE proposals ADD COLUMN results_tx_submitted_at TIMESTAMP without time zone;
@@ -0,0 +1,5 @@
+CREATE TABLE proposal_audit_reports (
+  proposal_id INT primary key references proposals(id) ON DELETE CASCADE,
+  report jsonb not null,
+  created_at TIMESTAMP without time zone default (now() at time zone 'utc')
+);
@@ -0,0 +1,3 @@
+ALTER TABLE proposals DROP COLUMN IF EXISTS publish_error;
+ALTER TABLE proposals DROP COLUMN IF EXISTS publish_retry_at;
+ALTER TABLE proposals DROP COLUMN IF EXISTS publish_attempts;
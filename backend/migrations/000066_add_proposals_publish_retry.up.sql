@@ -0,0 +1,3 @@
+ALTER TABLE proposals ADD COLUMN publish_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE proposals ADD COLUMN publish_retry_at TIMESTAMP without time zone;
+ALTER TABLE proposals ADD COLUMN publish_error TEXT;
@@ -0,0 +1,6 @@
+ALTER TABLE votes ALTER COLUMN addr TYPE VARCHAR(18);
+ALTER TABLE balances ALTER COLUMN addr TYPE VARCHAR(18);
+ALTER TABLE nfts ALTER COLUMN owner_addr TYPE VARCHAR(18);
+ALTER TABLE vote_history ALTER COLUMN addr TYPE VARCHAR(18);
+ALTER TABLE delegations ALTER COLUMN delegator TYPE VARCHAR(18);
+ALTER TABLE delegations ALTER COLUMN delegate TYPE VARCHAR(18);
@@ -0,0 +1,7 @@
+-- EVM addresses are 42 characters long
+ALTER TABLE votes ALTER COLUMN addr TYPE VARCHAR(42);
+ALTER TABLE balances ALTER COLUMN addr TYPE VARCHAR(42);
+ALTER TABLE nfts ALTER COLUMN owner_addr TYPE VARCHAR(42);
+ALTER TABLE vote_history ALTER COLUMN addr TYPE VARCHAR(42);
+ALTER TABLE delegations ALTER COLUMN delegator TYPE VARCHAR(42);
+ALTER TABLE delegations ALTER COLUMN delegate TYPE VARCHAR(42);
@@ -0,0 +1 @@
+ALTER TABLE pin_jobs ALTER COLUMN payload TYPE jsonb USING payload::jsonb;
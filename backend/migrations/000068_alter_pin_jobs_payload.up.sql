@@ -0,0 +1,3 @@
+-- json keeps the payload as it was marshalled, so a retried pin gets the
+-- same CID as the first attempt would have
+ALTER TABLE pin_jobs ALTER COLUMN payload TYPE json USING payload::json;
@@ -0,0 +1,2 @@
+-- the token ids backfilled are kept, as they can't be told apart from
+-- those stored with their balances
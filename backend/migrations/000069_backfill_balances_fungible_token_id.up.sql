@@ -0,0 +1,40 @@
+-- balances stored before they were keyed by token are matched to the
+-- token of the strategy of the proposals they were fetched for
+WITH matches AS (
+  SELECT DISTINCT b.id, b.addr, b.block_height,
+    'A.' || regexp_replace(st->'contract'->>'addr', '^0x', '') || '.' || (st->'contract'->>'name') AS token_id
+  FROM balances b
+  JOIN votes v ON v.addr = b.addr
+  JOIN proposals p ON p.id = v.proposal_id AND p.block_height = b.block_height
+  JOIN communities c ON c.id = p.community_id
+  CROSS JOIN LATERAL jsonb_array_elements(c.strategies) st
+  WHERE b.fungible_token_id = ''
+  AND p.strategy IN ('token-weighted-default', 'staked-token-weighted-default', 'quadratic-token-weighted')
+  AND st->>'name' = p.strategy
+  AND st->'contract'->>'name' IS NOT NULL
+  AND st->'contract'->>'addr' IS NOT NULL
+),
+-- a balance fetched for proposals weighing different tokens cannot be
+-- told apart, so it is left unmatched
+single AS (
+  SELECT id, addr, block_height, min(token_id) AS token_id
+  FROM matches
+  GROUP BY id, addr, block_height
+  HAVING count(*) = 1
+),
+-- only one balance of a token is kept per address and block
+picked AS (
+  SELECT DISTINCT ON (s.addr, s.block_height, s.token_id) s.id, s.token_id
+  FROM single s
+  WHERE NOT EXISTS (
+    SELECT 1 FROM balances o
+    WHERE o.addr = s.addr
+    AND o.block_height = s.block_height
+    AND o.fungible_token_id = s.token_id
+  )
+  ORDER BY s.addr, s.block_height, s.token_id, s.id
+)
+UPDATE balances b
+SET fungible_token_id = picked.token_id
+FROM picked
+WHERE b.id = picked.id;
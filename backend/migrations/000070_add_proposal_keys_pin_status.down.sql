@@ -0,0 +1 @@
+ALTER TABLE proposal_keys DROP COLUMN IF EXISTS pin_status;
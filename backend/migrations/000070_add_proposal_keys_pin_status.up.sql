@@ -0,0 +1 @@
+ALTER TABLE proposal_keys ADD COLUMN pin_status VARCHAR(16) NOT NULL DEFAULT 'pinned';
@@ -54,7 +54,8 @@ export default function StrategySelectorForm({
     const { contract } = newStrategyInfo;
     if (
       newStrategyInfo.name === 'staked-token-weighted-default' ||
-      newStrategyInfo.name === 'token-weighted-default'
+      newStrategyInfo.name === 'token-weighted-default' ||
+      newStrategyInfo.name === 'quadratic-token-weighted'
     ) {
       addFungibleToken(contract.addr, contract.name, contract.publicPath);
     }